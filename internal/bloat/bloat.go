@@ -0,0 +1,336 @@
+package bloat
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// 无法归属到 Go 包的符号分组
+const (
+	GroupLinker = "(linker)" // 链接器生成的数据，如 go:string.*、go:buildid
+	GroupCgo    = "(cgo)"    // C 符号及 cgo 桩代码
+	GroupStd    = "std"      // 标准库模块
+)
+
+// Symbol 二进制中的单个符号
+type Symbol struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Size    uint64 `json:"size"`
+}
+
+// Entry 按包、模块或符号聚合后的体积
+type Entry struct {
+	Name    string `json:"name"`
+	Size    uint64 `json:"size"`
+	Symbols int    `json:"symbols"`
+}
+
+// Report 二进制体积报告
+type Report struct {
+	Binary   string   `json:"binary"`
+	FileSize uint64   `json:"file_size"`
+	Total    uint64   `json:"symbol_size"` // 归属到符号的字节数
+	Packages []Entry  `json:"packages"`
+	Modules  []Entry  `json:"modules"`
+	Symbols  []Symbol `json:"-"`
+}
+
+// Analyze 读取二进制的符号表，按包与模块聚合符号体积
+func Analyze(path string) (*Report, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := ReadSymbols(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("%s has no symbol table (was it built with -ldflags=-s?)", path)
+	}
+
+	modules := []string{}
+	if bi, err := buildinfo.ReadFile(path); err == nil {
+		modules = append(modules, bi.Main.Path)
+		for _, dep := range bi.Deps {
+			modules = append(modules, dep.Path)
+		}
+	}
+
+	report := &Report{Binary: path, FileSize: uint64(info.Size()), Symbols: symbols}
+	packages := map[string]*Entry{}
+	mods := map[string]*Entry{}
+	for _, sym := range symbols {
+		report.Total += sym.Size
+		add(packages, sym.Package, sym.Size)
+		add(mods, ModuleOf(sym.Package, modules), sym.Size)
+	}
+	report.Packages = sortEntries(packages)
+	report.Modules = sortEntries(mods)
+	return report, nil
+}
+
+func add(entries map[string]*Entry, name string, size uint64) {
+	entry, ok := entries[name]
+	if !ok {
+		entry = &Entry{Name: name}
+		entries[name] = entry
+	}
+	entry.Size += size
+	entry.Symbols++
+}
+
+// sortEntries 按体积从大到小排序，体积相同时按名称排序
+func sortEntries(entries map[string]*Entry) []Entry {
+	sorted := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		sorted = append(sorted, *entry)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// TopSymbols 返回体积最大的 n 个符号，n <= 0 时返回全部
+func (r *Report) TopSymbols(n int) []Entry {
+	entries := make([]Entry, 0, len(r.Symbols))
+	for _, sym := range r.Symbols {
+		entries = append(entries, Entry{Name: sym.Name, Size: sym.Size, Symbols: 1})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Size != entries[j].Size {
+			return entries[i].Size > entries[j].Size
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// PackageOf 根据符号名推断其所属的 Go 包路径
+func PackageOf(name string) string {
+	fallback := GroupCgo
+	switch {
+	case strings.HasPrefix(name, "type:"):
+		// 未命名类型 (如 type:struct { ... }) 的描述符由链接器生成
+		name = strings.TrimPrefix(name, "type:")
+		fallback = GroupLinker
+	case strings.HasPrefix(name, "go:itab."):
+		// go:itab.*pkg.T,iface 归属到实现类型所在的包
+		name = strings.TrimPrefix(name, "go:itab.")
+		if i := strings.Index(name, ","); i >= 0 {
+			name = name[:i]
+		}
+	case strings.HasPrefix(name, "go:"), strings.HasPrefix(name, "go."), strings.HasPrefix(name, "type."):
+		return GroupLinker
+	}
+	name = strings.TrimLeft(name, "*[]")
+	if strings.HasPrefix(name, ".") {
+		return GroupLinker
+	}
+
+	// 泛型实例化参数中可能包含 / 和 .，先截掉
+	if i := strings.IndexAny(name, "[("); i > 0 {
+		name = name[:i]
+	}
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot <= 0 {
+		return fallback
+	}
+	pkg := name[:slash+1+dot]
+	if strings.HasPrefix(pkg, "_cgo_") {
+		return GroupCgo
+	}
+	// 链接器将包路径最后一段中的 . 转义为 %2e (如 gopkg.in/yaml%2ev3)
+	return strings.ReplaceAll(pkg, "%2e", ".")
+}
+
+// ModuleOf 返回包所属的模块：最长匹配的模块路径，标准库为 std
+func ModuleOf(pkg string, modules []string) string {
+	if pkg == GroupLinker || pkg == GroupCgo {
+		return pkg
+	}
+	best := ""
+	for _, mod := range modules {
+		if (pkg == mod || strings.HasPrefix(pkg, mod+"/")) && len(mod) > len(best) {
+			best = mod
+		}
+	}
+	if best != "" {
+		return best
+	}
+	if pkg == "main" && len(modules) > 0 && modules[0] != "" {
+		return modules[0]
+	}
+	// 标准库路径的第一段不含 .
+	first, _, _ := strings.Cut(pkg, "/")
+	if !strings.Contains(first, ".") {
+		return GroupStd
+	}
+	return pkg
+}
+
+// rawSymbol 读取到的原始符号，size 为 0 时根据相邻符号地址推算
+type rawSymbol struct {
+	name    string
+	section int
+	addr    uint64
+	size    uint64
+}
+
+// ReadSymbols 读取 ELF、Mach-O 或 PE 二进制中占用文件空间的符号
+func ReadSymbols(path string) ([]Symbol, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		return readELF(f)
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return readMachO(f), nil
+	}
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		return readPE(f), nil
+	}
+	return nil, fmt.Errorf("%s is not an ELF, Mach-O or PE binary", path)
+}
+
+func readELF(f *elf.File) ([]Symbol, error) {
+	syms, err := f.Symbols()
+	if err != nil {
+		if err == elf.ErrNoSymbols {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sectionEnds := make([]uint64, len(f.Sections))
+	fileBacked := make([]bool, len(f.Sections))
+	for i, s := range f.Sections {
+		sectionEnds[i] = s.Addr + s.Size
+		fileBacked[i] = s.Type != elf.SHT_NOBITS && s.Flags&elf.SHF_ALLOC != 0
+	}
+	raw := []rawSymbol{}
+	for _, sym := range syms {
+		idx := int(sym.Section)
+		if idx <= 0 || idx >= len(sectionEnds) || !fileBacked[idx] {
+			continue
+		}
+		typ := elf.ST_TYPE(sym.Info)
+		if typ == elf.STT_SECTION || typ == elf.STT_FILE {
+			continue
+		}
+		raw = append(raw, rawSymbol{sym.Name, idx, sym.Value, sym.Size})
+	}
+	return resolveSizes(raw, sectionEnds), nil
+}
+
+func readMachO(f *macho.File) []Symbol {
+	if f.Symtab == nil {
+		return nil
+	}
+	// Mach-O 的节号从 1 开始
+	sectionEnds := make([]uint64, len(f.Sections)+1)
+	fileBacked := make([]bool, len(f.Sections)+1)
+	for i, s := range f.Sections {
+		sectionEnds[i+1] = s.Addr + s.Size
+		fileBacked[i+1] = s.Flags&0xff != 0x1 // S_ZEROFILL
+	}
+	raw := []rawSymbol{}
+	for _, sym := range f.Symtab.Syms {
+		idx := int(sym.Sect)
+		if sym.Type&0xe0 != 0 || idx <= 0 || idx >= len(sectionEnds) || !fileBacked[idx] { // N_STAB
+			continue
+		}
+		raw = append(raw, rawSymbol{strings.TrimPrefix(sym.Name, "_"), idx, sym.Value, 0})
+	}
+	return resolveSizes(raw, sectionEnds)
+}
+
+func readPE(f *pe.File) []Symbol {
+	// PE 的节号从 1 开始，符号值为节内偏移
+	sectionEnds := make([]uint64, len(f.Sections)+1)
+	fileBacked := make([]bool, len(f.Sections)+1)
+	for i, s := range f.Sections {
+		size := min(s.VirtualSize, s.Size)
+		if size == 0 {
+			size = max(s.VirtualSize, s.Size)
+		}
+		sectionEnds[i+1] = uint64(size)
+		fileBacked[i+1] = s.Characteristics&pe.IMAGE_SCN_CNT_UNINITIALIZED_DATA == 0
+	}
+	raw := []rawSymbol{}
+	for _, sym := range f.Symbols {
+		idx := int(sym.SectionNumber)
+		if idx <= 0 || idx >= len(sectionEnds) || !fileBacked[idx] {
+			continue
+		}
+		raw = append(raw, rawSymbol{sym.Name, idx, uint64(sym.Value), 0})
+	}
+	return resolveSizes(raw, sectionEnds)
+}
+
+// resolveSizes 为没有大小信息的符号按同一节内下一个符号的地址推算大小
+func resolveSizes(raw []rawSymbol, sectionEnds []uint64) []Symbol {
+	sort.SliceStable(raw, func(i, j int) bool {
+		if raw[i].section != raw[j].section {
+			return raw[i].section < raw[j].section
+		}
+		return raw[i].addr < raw[j].addr
+	})
+	symbols := []Symbol{}
+	for i, sym := range raw {
+		size := sym.size
+		if size == 0 {
+			end := sectionEnds[sym.section]
+			if i+1 < len(raw) && raw[i+1].section == sym.section {
+				end = raw[i+1].addr
+			}
+			if end > sym.addr {
+				size = end - sym.addr
+			}
+		}
+		if size == 0 || sym.name == "" {
+			continue
+		}
+		symbols = append(symbols, Symbol{Name: sym.name, Package: PackageOf(sym.name), Size: size})
+	}
+	return symbols
+}
+
+// FormatSize 将字节数格式化为便于阅读的形式
+func FormatSize(size int64) string {
+	sign := ""
+	if size < 0 {
+		sign = "-"
+		size = -size
+	}
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%s%d B", sign, size)
+	}
+	value := float64(size)
+	suffixes := []string{"KiB", "MiB", "GiB"}
+	suffix := ""
+	for _, s := range suffixes {
+		value /= unit
+		suffix = s
+		if value < unit {
+			break
+		}
+	}
+	return fmt.Sprintf("%s%.1f %s", sign, value, suffix)
+}
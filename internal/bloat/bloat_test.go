@@ -0,0 +1,138 @@
+package bloat
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPackageOf(t *testing.T) {
+	tests := map[string]string{
+		"main.main":                            "main",
+		"runtime.mallocgc":                     "runtime",
+		"github.com/foo/bar.(*Client).Do":      "github.com/foo/bar",
+		"github.com/foo/bar.Map[go.shape.int]": "github.com/foo/bar",
+		"gopkg.in/yaml%2ev3.Unmarshal":         "gopkg.in/yaml.v3",
+		"type:*encoding/json.Decoder":          "encoding/json",
+		"type:struct { a int }":                GroupLinker,
+		"type:.eq.[2]interface {}":             GroupLinker,
+		"go:itab.*os.File,io.Writer":           "os",
+		"go:string.*":                          GroupLinker,
+		"go:buildid":                           GroupLinker,
+		"x_cgo_init":                           GroupCgo,
+		"_cgo_topofstack":                      GroupCgo,
+	}
+	for name, want := range tests {
+		if got := PackageOf(name); got != want {
+			t.Errorf("PackageOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestModuleOf(t *testing.T) {
+	modules := []string{"example.com/app", "github.com/foo/bar", "github.com/foo/bar/v2"}
+	tests := map[string]string{
+		"main":                         "example.com/app",
+		"example.com/app/internal/x":   "example.com/app",
+		"github.com/foo/bar/sub":       "github.com/foo/bar",
+		"github.com/foo/bar/v2/sub":    "github.com/foo/bar/v2",
+		"encoding/json":                GroupStd,
+		"vendor/golang.org/x/net/idna": GroupStd,
+		"github.com/unknown/pkg":       "github.com/unknown/pkg",
+		GroupLinker:                    GroupLinker,
+	}
+	for pkg, want := range tests {
+		if got := ModuleOf(pkg, modules); got != want {
+			t.Errorf("ModuleOf(%q) = %q, want %q", pkg, got, want)
+		}
+	}
+}
+
+func TestAnalyzeBinary(t *testing.T) {
+	// go test 链接测试二进制时会去掉符号表，这里单独构建一个小程序
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.21\n",
+		"main.go": "package main\n\nimport \"encoding/json\"\n\nfunc main() { json.Marshal(1) }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "app")
+	cmd := exec.Command("go", "build", "-o", path, ".")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, output)
+	}
+
+	report, err := Analyze(path)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if report.Total == 0 || report.Total > report.FileSize {
+		t.Fatalf("symbol size = %d, file size = %d", report.Total, report.FileSize)
+	}
+
+	found := map[string]bool{}
+	for _, entry := range report.Packages {
+		found[entry.Name] = true
+	}
+	for _, pkg := range []string{"runtime", "encoding/json", "main"} {
+		if !found[pkg] {
+			t.Errorf("package %s missing from report", pkg)
+		}
+	}
+	modules := map[string]bool{}
+	for _, entry := range report.Modules {
+		modules[entry.Name] = true
+	}
+	if !modules[GroupStd] || !modules["example.com/app"] {
+		t.Errorf("modules = %v, want std and example.com/app", report.Modules)
+	}
+
+	stripped := filepath.Join(dir, "stripped")
+	cmd = exec.Command("go", "build", "-ldflags=-s -w", "-o", stripped, ".")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, output)
+	}
+	if _, err := Analyze(stripped); err == nil || !strings.Contains(err.Error(), "no symbol table") {
+		t.Errorf("Analyze(stripped) error = %v, want no symbol table", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := []Entry{{Name: "a", Size: 100}, {Name: "b", Size: 50}, {Name: "c", Size: 10}}
+	new := []Entry{{Name: "a", Size: 120}, {Name: "c", Size: 10}, {Name: "d", Size: 300}}
+	deltas := Diff(old, new)
+	want := []Delta{
+		{Name: "d", Old: 0, New: 300, Delta: 300},
+		{Name: "b", Old: 50, New: 0, Delta: -50},
+		{Name: "a", Old: 100, New: 120, Delta: 20},
+	}
+	if len(deltas) != len(want) {
+		t.Fatalf("Diff() = %+v, want %+v", deltas, want)
+	}
+	for i := range want {
+		if deltas[i] != want[i] {
+			t.Errorf("Diff()[%d] = %+v, want %+v", i, deltas[i], want[i])
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:              "512 B",
+		2048:             "2.0 KiB",
+		-3 * 1024 * 1024: "-3.0 MiB",
+	}
+	for size, want := range tests {
+		if got := FormatSize(size); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package bloat
+
+import "sort"
+
+// Delta 两次构建之间某个包或模块的体积变化
+type Delta struct {
+	Name  string `json:"name"`
+	Old   uint64 `json:"old"`
+	New   uint64 `json:"new"`
+	Delta int64  `json:"delta"`
+}
+
+// Diff 比较两组聚合结果，返回体积有变化的条目，按变化量绝对值从大到小排序
+func Diff(old, new []Entry) []Delta {
+	sizes := map[string]*Delta{}
+	get := func(name string) *Delta {
+		d, ok := sizes[name]
+		if !ok {
+			d = &Delta{Name: name}
+			sizes[name] = d
+		}
+		return d
+	}
+	for _, entry := range old {
+		get(entry.Name).Old = entry.Size
+	}
+	for _, entry := range new {
+		get(entry.Name).New = entry.Size
+	}
+
+	deltas := []Delta{}
+	for _, d := range sizes {
+		d.Delta = int64(d.New) - int64(d.Old)
+		if d.Delta != 0 {
+			deltas = append(deltas, *d)
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		a, b := abs(deltas[i].Delta), abs(deltas[j].Delta)
+		if a != b {
+			return a > b
+		}
+		return deltas[i].Name < deltas[j].Name
+	})
+	return deltas
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
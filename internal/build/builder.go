@@ -5,8 +5,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"gocar/internal/config"
+	"gocar/internal/diag"
 )
 
 // Builder 构建器
@@ -16,6 +21,10 @@ type Builder struct {
 	appName     string
 	projectMode string
 	gocarConfig *config.GocarConfig
+	bin         *config.BinConfig
+
+	cacheMu sync.Mutex
+	cache   map[string]string // 版本号与 git 信息，见 cached
 }
 
 // NewBuilder 创建构建器
@@ -29,30 +38,206 @@ func NewBuilder(projectRoot, appName, projectMode string, config *Config, gocarC
 	}
 }
 
-// Build 执行构建
-func (b *Builder) Build() error {
+// SetBin 指定构建的二进制 ([[bin]] 或自动发现的 cmd/<name>)
+func (b *Builder) SetBin(bin config.BinConfig) {
+	b.appName = bin.Name
+	b.bin = &bin
+}
+
+// ProjectRoot 返回项目根目录
+func (b *Builder) ProjectRoot() string {
+	return b.projectRoot
+}
+
+// Name 返回构建的应用名称
+func (b *Builder) Name() string {
+	return b.appName
+}
+
+// Build 执行构建并输出结果
+func (b *Builder) Build() (*Result, error) {
+	result := b.Compile()
+	diag.RenderOutput(os.Stdout, b.projectRoot, diag.ToolBuild, result.Output)
+	PrintHookOutput(os.Stdout, result.HookOutput, "")
+
+	if result.Err != nil {
+		return result, result.Err
+	}
+
+	if result.Fresh {
+		fmt.Printf("Fresh %s: %s (use --force to rebuild)\n", result.Name, result.Artifact)
+		return result, nil
+	}
+
+	fmt.Printf("Build successful: %s\n", result.Artifact)
+	if result.Header != "" {
+		fmt.Printf("C header: %s\n", result.Header)
+	}
+	if result.Timings != "" {
+		fmt.Printf("Timings: %s\n", result.Timings)
+	}
+	return result, nil
+}
+
+// Compile 执行 go build 并返回构建结果，编译输出由调用方决定如何展示
+func (b *Builder) Compile() *Result {
+	start := time.Now()
+	result := &Result{
+		Name:     b.appName,
+		Target:   b.Target(),
+		Profile:  b.config.BuildMode(),
+		Artifact: b.GetRelativeOutputPath(),
+	}
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	flags, err := b.resolveFlags()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Ldflags = flags.Ldflags
+	result.Gcflags = flags.Gcflags
+	result.Tags = flags.Tags
+
+	if _, err := b.relativeOutputPath(); err != nil {
+		result.Err = err
+		return result
+	}
 	outputPath := b.GetOutputPath()
 
 	// 确保输出目录存在
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		result.Err = fmt.Errorf("failed to create output directory: %w", err)
+		return result
 	}
 
 	// 构建命令
-	cmd := b.buildCommand(outputPath)
+	cmd := b.buildCommand(outputPath, flags)
 
-	// 执行构建
-	output, err := cmd.CombinedOutput()
-	if len(output) > 0 {
-		fmt.Print(string(output))
+	// windows 目标在入口包目录生成资源文件 (.syso)，构建结束后删除
+	cleanup, err := b.writeWindowsResources()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer cleanup()
+
+	// 指纹未变化时跳过构建；指纹计算失败时照常构建
+	fingerprint := ""
+	if goVersion, err := goEnv(cmd.Dir, cmd.Env, "GOVERSION"); err == nil {
+		result.GoVersion = goVersion
+		if fp, err := computeFingerprint(cmd, goVersion, b.PGOProfile()); err == nil && !b.config.NoFingerprint {
+			fingerprint = fp
+		}
+	}
+	// --timings 时始终执行 go build (由 go 的构建缓存决定哪些包需要重新编译)，并记录动作图
+	actionGraph := ""
+	if b.config.Timings {
+		file, err := os.CreateTemp("", actionGraphPattern)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		file.Close()
+		actionGraph = file.Name()
+		defer os.Remove(actionGraph)
+		addActionGraph(cmd, actionGraph)
 	}
 
+	header := headerPath(outputPath, flags.Buildmode)
+	result.Header = headerPath(result.Artifact, flags.Buildmode)
+	if !b.config.Force && !b.config.Timings && isFresh(outputPath, fingerprint) && (header == "" || fileExists(header)) {
+		result.Fresh = true
+	} else {
+		// 执行构建
+		output, err := cmd.CombinedOutput()
+		result.Output = output
+		result.Diagnostics = diag.Parse(diag.ToolBuild, output)
+		diag.Resolve(b.projectRoot, result.Diagnostics)
+		if err != nil {
+			removeFingerprint(outputPath)
+			result.Err = fmt.Errorf("build failed: %w", err)
+			return result
+		}
+
+		if err := writeFingerprint(outputPath, fingerprint); err != nil {
+			result.Output = append(result.Output, fmt.Sprintf("warning: failed to write build fingerprint: %v\n", err)...)
+		}
+
+		if actionGraph != "" {
+			timings, err := b.writeTimings(actionGraph, result)
+			if err != nil {
+				result.Output = append(result.Output, fmt.Sprintf("warning: failed to write build timings: %v\n", err)...)
+			}
+			result.Timings = timings
+		}
+	}
+
+	// post_build 钩子在每次成功构建后执行 (包括指纹未变化的产物)，用于签名、复制等步骤
+	if hook, ok := b.gocarConfig.HookCommand(b.projectRoot, config.HookPostBuild, b.HookEnv()); ok && !b.config.SkipHooks {
+		output, err := hook.CombinedOutput()
+		result.HookOutput = output
+		if err != nil {
+			result.Err = fmt.Errorf("%s hook failed: %w", config.HookPostBuild, err)
+			return result
+		}
+	}
+
+	sum, size, err := fileDigest(outputPath)
 	if err != nil {
-		return fmt.Errorf("build failed: %w", err)
+		result.Err = fmt.Errorf("failed to read artifact: %w", err)
+		return result
+	}
+	result.SHA256 = sum
+	result.Size = size
+
+	return result
+}
+
+// HookEnv 返回传给钩子的构建环境变量
+func (b *Builder) HookEnv() map[string]string {
+	return map[string]string{
+		"GOCAR_ARTIFACT": b.GetOutputPath(),
+		"GOCAR_BIN":      b.appName,
+		"GOCAR_TARGET":   b.Target(),
+		"GOCAR_PROFILE":  b.config.BuildMode(),
 	}
+}
 
-	fmt.Printf("Build successful: %s\n", b.GetRelativeOutputPath())
-	return nil
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// outputRoot 返回配置的输出根目录，Config.OutputDir 优先
+func (b *Builder) outputRoot() string {
+	if b.config.OutputDir != "" {
+		return b.config.OutputDir
+	}
+	if b.gocarConfig != nil {
+		return b.gocarConfig.GetBuildOutputRoot()
+	}
+	return "bin"
+}
+
+// OutputRoot 返回构建输出根目录的绝对路径
+func (b *Builder) OutputRoot() string {
+	outputRoot := b.outputRoot()
+	if filepath.IsAbs(outputRoot) {
+		return outputRoot
+	}
+	return filepath.Join(b.projectRoot, outputRoot)
+}
+
+// Target 返回目标平台字符串 <os>/<arch>[/<variant>]
+func (b *Builder) Target() string {
+	target := b.config.TargetOS + "/" + b.config.TargetArch
+	if b.config.TargetVariant != "" {
+		target += "/" + b.config.TargetVariant
+	}
+	return target
 }
 
 // GetOutputPath 获取完整输出路径
@@ -61,98 +246,347 @@ func (b *Builder) GetOutputPath() string {
 	return outputPath
 }
 
-// GetRelativeOutputPath 获取相对输出路径
+// GetRelativeOutputPath 获取相对输出路径，[build].artifact_name/artifact_dir 模板无效时使用默认布局
 func (b *Builder) GetRelativeOutputPath() string {
-	outputRoot := "bin"
-	if b.gocarConfig != nil {
-		outputRoot = b.gocarConfig.GetBuildOutputRoot()
+	path, err := b.relativeOutputPath()
+	if err != nil {
+		return filepath.Join(b.outputRoot(), b.defaultArtifactPath(b.config.TargetArch, b.config.TargetVariant))
+	}
+	return path
+}
+
+// relativeOutputPath 按 [build].artifact_name/artifact_dir 模板计算相对输出路径
+func (b *Builder) relativeOutputPath() (string, error) {
+	return b.artifactPath(b.config.TargetArch, b.config.TargetVariant)
+}
+
+// artifactPath 按模板计算指定架构的相对输出路径，通用二进制的架构为 universal
+func (b *Builder) artifactPath(arch, variant string) (string, error) {
+	defaultPath := b.defaultArtifactPath(arch, variant)
+	if b.gocarConfig == nil {
+		return filepath.Join(b.outputRoot(), defaultPath), nil
+	}
+	placeholder := func(name string) string {
+		switch name {
+		case "arch":
+			return arch
+		case "variant":
+			return variant
+		}
+		return b.artifactPlaceholder(name)
+	}
+	rel, err := b.gocarConfig.ResolveArtifactPath(placeholder, filepath.Dir(defaultPath), filepath.Base(defaultPath))
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(b.outputRoot(), rel), nil
+}
 
-	targetDir := fmt.Sprintf("%s-%s", b.config.TargetOS, b.config.TargetArch)
-	outputDir := filepath.Join(outputRoot, b.config.BuildMode(), targetDir)
-	outputPath := filepath.Join(outputDir, b.appName)
+// defaultArtifactPath 返回默认布局下产物相对输出根目录的路径: <profile>/<os>-<arch>[-<variant>]/<name>
+func (b *Builder) defaultArtifactPath(arch, variant string) string {
+	// 子版本构建使用独立目录 (如 linux-arm-v7)，避免相互覆盖
+	targetDir := b.config.TargetOS + "-" + arch
+	if variant != "" {
+		targetDir += "-" + variant
+	}
+	return filepath.Join(b.config.BuildMode(), targetDir, artifactFileName(b.appName, b.config.TargetOS, b.buildmode()))
+}
 
-	if b.config.TargetOS == "windows" {
-		outputPath += ".exe"
+// artifactPlaceholder 返回产物命名模板中占位符的值
+func (b *Builder) artifactPlaceholder(name string) string {
+	switch name {
+	case "name":
+		return b.appName
+	case "version":
+		return b.version()
+	case "os":
+		return b.config.TargetOS
+	case "arch":
+		return b.config.TargetArch
+	case "variant":
+		return b.config.TargetVariant
+	case "profile":
+		return b.config.BuildMode()
+	case "commit":
+		return shortCommit(b.commit())
+	case "ext":
+		return artifactExt(b.config.TargetOS, b.buildmode())
 	}
+	return ""
+}
 
-	return outputPath
+// buildFlags 解析后的构建参数
+type buildFlags struct {
+	Ldflags   string
+	Gcflags   string
+	Asmflags  string
+	Tags      []string
+	Trimpath  bool
+	Race      bool
+	Msan      bool
+	Asan      bool
+	Cover     bool
+	Coverpkg  []string
+	Buildmode string
+	Mod       string
+	Pgo       string
+
+	// 以下记录各参数的来源，供 --dry-run / --explain 使用
+	ldflagParts []Setting         // ldflags 按来源拆分的片段
+	origins     map[string]string // 参数名 (profile 中的 toml 键名) -> 来源
 }
 
-// buildCommand 构建 go build 命令
-func (b *Builder) buildCommand(outputPath string) *exec.Cmd {
-	args := []string{"build"}
+// profile 获取当前模式的 profile 配置，未找到时返回 nil
+func (b *Builder) profile() *config.ProfileConfig {
+	if b.gocarConfig == nil {
+		return nil
+	}
+	profile, _, ok := b.gocarConfig.GetProfileForBuild(b.config.Profile, b.config.Release)
+	if !ok {
+		return nil
+	}
+	return profile
+}
 
-	// 获取当前模式的 profile 配置
-	var profile *config.ProfileConfig
-	if b.gocarConfig != nil {
-		var ok bool
-		profile, _, ok = b.gocarConfig.GetProfileForBuild(b.config.Profile, b.config.Release)
-		if !ok {
-			profile = nil
+// resolveFlags 合并 profile、[build]、[build.vars]、[[bin]] 等来源，得到最终构建参数
+func (b *Builder) resolveFlags() (buildFlags, error) {
+	flags := buildFlags{origins: map[string]string{}}
+	profile := b.profile()
+
+	// 按来源收集 ldflags，保留符号表 (gocar bloat) 时去掉每段中的 -s/-w
+	addLdflags := func(value, origin string) {
+		if b.config.KeepSymbols {
+			value = stripSymbolFlags(value)
+		}
+		if value != "" {
+			flags.ldflagParts = append(flags.ldflagParts, Setting{Name: "-ldflags", Value: value, Source: origin})
 		}
 	}
 
 	// 构建 ldflags
-	ldflags := ""
 	if profile != nil && profile.Ldflags != "" {
-		ldflags = profile.Ldflags
+		addLdflags(profile.Ldflags, b.profileOrigin("ldflags"))
 	}
 
-	// 自动注入版本号到 main.version
-	if b.gocarConfig != nil && b.gocarConfig.Project.Version != "" {
-		versionFlag := fmt.Sprintf("-X main.version=%s", b.gocarConfig.Project.Version)
-		if ldflags != "" {
-			ldflags += " " + versionFlag
-		} else {
-			ldflags = versionFlag
+	if b.gocarConfig != nil {
+		vars := b.gocarConfig.Build.Vars
+
+		// 自动注入版本号到 main.version，[build.vars] 中显式声明时以其为准
+		if _, ok := vars["main.version"]; !ok {
+			if version := b.version(); version != "" {
+				versionFlag, err := quoteLdflag("main.version=" + version)
+				if err != nil {
+					return flags, fmt.Errorf("invalid project.version: %w", err)
+				}
+				addLdflags("-X "+versionFlag, "[project].version")
+			}
+		}
+
+		// [build.vars] 模板变量
+		if len(vars) > 0 {
+			varFlags, err := renderVars(vars, b.varsData())
+			if err != nil {
+				return flags, err
+			}
+			addLdflags(varFlags, "[build.vars]")
 		}
 	}
 
 	// 追加配置文件中的额外 ldflags
 	if b.gocarConfig != nil && b.gocarConfig.Build.Ldflags != "" {
-		if ldflags != "" {
-			ldflags += " " + b.gocarConfig.Build.Ldflags
-		} else {
-			ldflags = b.gocarConfig.Build.Ldflags
+		addLdflags(b.gocarConfig.Build.Ldflags, "[build].ldflags")
+	}
+
+	// 追加 [[bin]] 中的 ldflags
+	if b.bin != nil && b.bin.Ldflags != "" {
+		addLdflags(b.bin.Ldflags, b.binOrigin())
+	}
+
+	for _, part := range flags.ldflagParts {
+		flags.Ldflags = joinFlags(flags.Ldflags, part.Value)
+	}
+
+	if profile != nil {
+		flags.Gcflags = profile.Gcflags
+		flags.Asmflags = profile.Asmflags
+		flags.Trimpath = config.Enabled(profile.Trimpath)
+		flags.Race = config.Enabled(profile.Race)
+		flags.Msan = config.Enabled(profile.Msan)
+		flags.Asan = config.Enabled(profile.Asan)
+		flags.Cover = config.Enabled(profile.Cover) || len(profile.Coverpkg) > 0
+		flags.Coverpkg = profile.Coverpkg
+		flags.Mod = profile.Mod
+		flags.Pgo = profile.Pgo
+		for _, field := range []string{"gcflags", "asmflags", "trimpath", "race", "msan", "asan", "cover", "coverpkg", "mod", "pgo"} {
+			flags.origins[field] = b.profileOrigin(field)
+		}
+		if !config.Enabled(profile.Cover) {
+			flags.origins["cover"] = flags.origins["coverpkg"]
+		}
+	}
+
+	flags.Buildmode = b.buildmode()
+	if b.config.Buildmode != "" {
+		flags.origins["buildmode"] = CLIOrigin("--buildmode")
+	} else {
+		flags.origins["buildmode"] = b.profileOrigin("buildmode")
+	}
+
+	// 构建标签：[[bin]] 中的 tags 覆盖 [build].tags，再追加 profile 中的 tags
+	tagOrigins := []string{}
+	if b.gocarConfig != nil {
+		flags.Tags = b.gocarConfig.Build.Tags
+		if len(flags.Tags) > 0 {
+			tagOrigins = []string{"[build].tags"}
+		}
+	}
+	if b.bin != nil && len(b.bin.Tags) > 0 {
+		flags.Tags = b.bin.Tags
+		tagOrigins = []string{b.binOrigin()}
+	}
+	if profile != nil && len(profile.Tags) > 0 {
+		tags := slices.Clone(flags.Tags)
+		for _, tag := range profile.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		flags.Tags = tags
+		tagOrigins = append(tagOrigins, b.profileOrigin("tags"))
+	}
+	flags.origins["tags"] = strings.Join(tagOrigins, " + ")
+
+	return flags, nil
+}
+
+// joinFlags 以空格拼接非空参数
+func joinFlags(base, extra string) string {
+	if base == "" {
+		return extra
+	}
+	if extra == "" {
+		return base
+	}
+	return base + " " + extra
+}
+
+// stripSymbolFlags 去掉 ldflags 中裁剪符号表的 -s 与 -w，其余参数 (包括带引号的 -X 值) 原样保留
+func stripSymbolFlags(ldflags string) string {
+	kept := []string{}
+	for _, field := range splitFlagFields(ldflags) {
+		if field != "-s" && field != "-w" {
+			kept = append(kept, field)
 		}
 	}
-	if ldflags != "" {
-		args = append(args, "-ldflags="+ldflags)
+	return strings.Join(kept, " ")
+}
+
+// splitFlagFields 按空白拆分参数，引号内的空白不拆分
+func splitFlagFields(s string) []string {
+	fields := []string{}
+	start := -1
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			if start < 0 {
+				start = i
+			}
+		case r == ' ' || r == '\t' || r == '\n':
+			if start >= 0 {
+				fields = append(fields, s[start:i])
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, s[start:])
+	}
+	return fields
+}
+
+// buildCommand 按 resolveFlags 解析出的参数构建 go build 命令
+func (b *Builder) buildCommand(outputPath string, flags buildFlags) *exec.Cmd {
+	args := []string{"build"}
+	if flags.Ldflags != "" {
+		args = append(args, "-ldflags="+flags.Ldflags)
 	}
 
 	// gcflags
-	if profile != nil && profile.Gcflags != "" {
-		args = append(args, "-gcflags="+profile.Gcflags)
+	if flags.Gcflags != "" {
+		args = append(args, "-gcflags="+flags.Gcflags)
+	}
+
+	// asmflags
+	if flags.Asmflags != "" {
+		args = append(args, "-asmflags="+flags.Asmflags)
 	}
 
 	// trimpath
-	if profile != nil && profile.Trimpath != nil && *profile.Trimpath {
+	if flags.Trimpath {
 		args = append(args, "-trimpath")
 	}
 
-	// race 检测
-	if profile != nil && profile.Race {
+	// race / msan / asan 检测
+	if flags.Race {
 		args = append(args, "-race")
 	}
+	if flags.Msan {
+		args = append(args, "-msan")
+	}
+	if flags.Asan {
+		args = append(args, "-asan")
+	}
+
+	// 覆盖率插桩
+	if flags.Cover {
+		args = append(args, "-cover")
+	}
+	if len(flags.Coverpkg) > 0 {
+		args = append(args, "-coverpkg="+strings.Join(flags.Coverpkg, ","))
+	}
+
+	if flags.Buildmode != "" {
+		args = append(args, "-buildmode="+flags.Buildmode)
+	}
+	if flags.Mod != "" {
+		args = append(args, "-mod="+flags.Mod)
+	}
+	if flags.Pgo != "" {
+		args = append(args, "-pgo="+flags.Pgo)
+	}
 
 	// 添加构建标签
-	if b.gocarConfig != nil && len(b.gocarConfig.Build.Tags) > 0 {
-		tags := ""
-		for i, tag := range b.gocarConfig.Build.Tags {
-			if i > 0 {
-				tags += ","
-			}
-			tags += tag
-		}
-		args = append(args, "-tags="+tags)
+	if len(flags.Tags) > 0 {
+		args = append(args, "-tags="+strings.Join(flags.Tags, ","))
 	}
 
 	args = append(args, "-o", outputPath)
+	args = append(args, b.entry())
 
+	cmd := exec.Command("go", args...)
+	cmd.Dir = b.projectRoot
+	cmd.Env = b.buildEnv()
+
+	return cmd
+}
+
+// entry 返回构建入口，相对路径以 ./ 开头
+func (b *Builder) entry() string {
 	// 从配置获取构建入口
 	var entry string
-	if b.gocarConfig != nil {
+	if b.bin != nil && b.bin.Entry != "" {
+		entry = b.bin.Entry
+	} else if b.gocarConfig != nil {
 		entry = b.gocarConfig.GetBuildEntryForApp(b.appName)
 	} else if b.projectMode == "standard" {
 		entry = "./cmd/" + b.appName
@@ -164,53 +598,75 @@ func (b *Builder) buildCommand(outputPath string) *exec.Cmd {
 	if entry != "." && !filepath.IsAbs(entry) && entry[0] != '.' {
 		entry = "./" + entry
 	}
-	args = append(args, entry)
-
-	cmd := exec.Command("go", args...)
-	cmd.Dir = b.projectRoot
-	cmd.Env = b.buildEnv()
-
-	return cmd
+	return entry
 }
 
 // buildEnv 构建环境变量
 func (b *Builder) buildEnv() []string {
 	env := os.Environ()
+	for _, setting := range b.envSettings() {
+		env = append(env, setting.Name+"="+setting.Value)
+	}
+	return env
+}
 
-	env = append(env, fmt.Sprintf("GOOS=%s", b.config.TargetOS))
-	env = append(env, fmt.Sprintf("GOARCH=%s", b.config.TargetArch))
-
-	// 获取当前模式的 profile 配置
-	var profile *config.ProfileConfig
-	if b.gocarConfig != nil {
-		var ok bool
-		profile, _, ok = b.gocarConfig.GetProfileForBuild(b.config.Profile, b.config.Release)
-		if !ok {
-			profile = nil
+// envSettings 返回 gocar 添加或覆盖的环境变量及其来源，后出现的同名变量优先
+func (b *Builder) envSettings() []Setting {
+	targetOrigin := b.targetOrigin()
+	env := []Setting{
+		{Name: "GOOS", Value: b.config.TargetOS, Source: targetOrigin},
+		{Name: "GOARCH", Value: b.config.TargetArch, Source: targetOrigin},
+	}
+	if b.config.TargetVariant != "" {
+		if variantEnv, err := VariantEnv(b.config.TargetArch, b.config.TargetVariant); err == nil {
+			name, value, _ := strings.Cut(variantEnv, "=")
+			env = append(env, Setting{Name: name, Value: value, Source: targetOrigin})
 		}
 	}
 
-	// 命令行 --with-cgo 优先级最高
+	// 获取当前模式的 profile 配置
+	profile := b.profile()
+
+	// 命令行 --with-cgo 与需要 cgo 的构建模式优先级最高
 	if b.config.WithCGO {
-		env = append(env, "CGO_ENABLED=1")
+		env = append(env, Setting{Name: "CGO_ENABLED", Value: "1", Source: CLIOrigin("--with-cgo")})
+	} else if buildmode := b.buildmode(); RequiresCgo(buildmode) {
+		env = append(env, Setting{Name: "CGO_ENABLED", Value: "1", Source: "required by buildmode " + buildmode})
 	} else if profile != nil && profile.CgoEnabled != nil {
 		// 使用 profile 中的配置
+		value := "0"
 		if *profile.CgoEnabled {
-			env = append(env, "CGO_ENABLED=1")
-		} else {
-			env = append(env, "CGO_ENABLED=0")
+			value = "1"
 		}
+		env = append(env, Setting{Name: "CGO_ENABLED", Value: value, Source: b.profileOrigin("cgo_enabled")})
 	}
 	// 如果都没设置，则使用系统默认（不设置 CGO_ENABLED）
 
-	// 添加配置文件中的额外环境变量
-	if b.gocarConfig != nil && len(b.gocarConfig.Build.ExtraEnv) > 0 {
-		env = append(env, b.gocarConfig.Build.ExtraEnv...)
+	if profile != nil && profile.Goexperiment != "" {
+		env = append(env, Setting{Name: "GOEXPERIMENT", Value: profile.Goexperiment, Source: b.profileOrigin("goexperiment")})
+	}
+
+	// 添加配置文件中的额外环境变量，profile 中的 extra_env 在 [build].extra_env 之后，同名时优先
+	if b.gocarConfig != nil {
+		env = append(env, envSettings(b.gocarConfig.Build.ExtraEnv, "[build].extra_env")...)
+	}
+	if profile != nil {
+		env = append(env, envSettings(profile.ExtraEnv, b.profileOrigin("extra_env"))...)
 	}
 
 	return env
 }
 
+// envSettings 将 KEY=VALUE 形式的环境变量转换为设置项
+func envSettings(env []string, origin string) []Setting {
+	settings := make([]Setting, 0, len(env))
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		settings = append(settings, Setting{Name: name, Value: value, Source: origin})
+	}
+	return settings
+}
+
 // PrintBuildInfo 打印构建信息
 func (b *Builder) PrintBuildInfo() {
 	mode := "debug"
@@ -221,11 +677,14 @@ func (b *Builder) PrintBuildInfo() {
 	}
 
 	if !b.config.IsCurrentPlatform() {
-		fmt.Printf("Building in %s mode for %s/%s", mode, b.config.TargetOS, b.config.TargetArch)
+		fmt.Printf("Building in %s mode for %s", mode, b.Target())
 	} else {
 		fmt.Printf("Building in %s mode", mode)
 	}
 
+	if buildmode := b.buildmode(); buildmode != "" {
+		fmt.Printf(" (buildmode %s)", buildmode)
+	}
 	if b.config.WithCGO {
 		fmt.Print(" with CGO enabled")
 	}
@@ -1,13 +1,23 @@
 package build
 
 import (
+	"bytes"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	gocarconfig "gocar/internal/config"
+	"gocar/internal/winres"
 )
 
 func TestBuilderCommandUsesConfig(t *testing.T) {
@@ -29,7 +39,11 @@ func TestBuilderCommandUsesConfig(t *testing.T) {
 		t.Fatalf("GetRelativeOutputPath() = %q", got)
 	}
 
-	cmd := builder.buildCommand("/repo/dist/release/linux-amd64/api")
+	flags, err := builder.resolveFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := builder.buildCommand("/repo/dist/release/linux-amd64/api", flags)
 	args := cmd.Args
 
 	if !slices.Contains(args, "-trimpath") {
@@ -75,3 +89,935 @@ func TestBuildConfigCurrentPlatform(t *testing.T) {
 		t.Fatal("new config should target current platform")
 	}
 }
+
+func TestBuildMatrixKeepsResultsOfFailedTargets(t *testing.T) {
+	root := writeTestModule(t, "api")
+
+	var builders []*Builder
+	for _, target := range [][2]string{{"linux", "amd64"}, {"linux", "bogus"}, {"windows", "amd64"}} {
+		cfg := NewConfig()
+		cfg.SetTarget(target[0], target[1])
+		builders = append(builders, NewBuilder(root, "api", "standard", cfg, nil))
+	}
+
+	done := 0
+	results := BuildMatrix(builders, 2, func(*Result) { done++ })
+	if done != len(builders) {
+		t.Fatalf("onDone called %d times, want %d", done, len(builders))
+	}
+	if got := CountFailed(results); got != 1 {
+		t.Fatalf("CountFailed() = %d, want 1", got)
+	}
+	if results[1].OK() || results[1].Target != "linux/bogus" {
+		t.Fatalf("expected linux/bogus to fail: %+v", results[1])
+	}
+	for _, i := range []int{0, 2} {
+		if !results[i].OK() {
+			t.Fatalf("target %s failed: %v\n%s", results[i].Target, results[i].Err, results[i].Output)
+		}
+		if _, err := os.Stat(filepath.Join(root, results[i].Artifact)); err != nil {
+			t.Fatalf("artifact for %s missing: %v", results[i].Target, err)
+		}
+	}
+
+	var summary strings.Builder
+	PrintSummary(&summary, results)
+	for _, want := range []string{"linux/amd64", "FAILED", filepath.Join("bin", "debug", "windows-amd64", "api.exe")} {
+		if !strings.Contains(summary.String(), want) {
+			t.Fatalf("summary missing %q:\n%s", want, summary.String())
+		}
+	}
+}
+
+// writeTestModule 创建一个最小的 standard 布局模块
+func writeTestModule(t *testing.T, name string) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                              "module example.com/" + name + "\n\ngo 1.21\n",
+		filepath.Join("cmd", name, "main.go"): "package main\n\nfunc main() {}\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestBuilderUsesBinOverrides(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetTarget("linux", "amd64")
+
+	gcfg := gocarconfig.DefaultConfig()
+	gcfg.Build.Tags = []string{"jsoniter"}
+	gcfg.Build.Ldflags = "-X main.commit=abc"
+
+	builder := NewBuilder("/repo", "app", "standard", cfg, gcfg)
+	builder.SetBin(gocarconfig.BinConfig{Name: "worker", Entry: "cmd/worker", Ldflags: "-X main.component=worker", Tags: []string{"netgo"}})
+
+	if got := builder.GetRelativeOutputPath(); got != filepath.Join("bin", "debug", "linux-amd64", "worker") {
+		t.Fatalf("GetRelativeOutputPath() = %q", got)
+	}
+
+	flags, err := builder.resolveFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := builder.buildCommand("/repo/bin/debug/linux-amd64/worker", flags)
+	args := cmd.Args
+	if !slices.Contains(args, "./cmd/worker") {
+		t.Fatalf("expected bin entry in args: %#v", args)
+	}
+	if !slices.Contains(args, "-tags=netgo") {
+		t.Fatalf("expected bin tags to override build tags: %#v", args)
+	}
+	if !slices.Contains(args, "-ldflags=-X main.commit=abc -X main.component=worker") {
+		t.Fatalf("expected bin ldflags after build ldflags: %#v", args)
+	}
+}
+
+func TestCompileSkipsFreshBuilds(t *testing.T) {
+	root := writeTestModule(t, "api")
+	cfg := NewConfig()
+	builder := NewBuilder(root, "api", "standard", cfg, nil)
+
+	first := builder.Compile()
+	if !first.OK() || first.Fresh {
+		t.Fatalf("first build: fresh=%v err=%v\n%s", first.Fresh, first.Err, first.Output)
+	}
+	if second := builder.Compile(); !second.OK() || !second.Fresh {
+		t.Fatalf("unchanged rebuild should be fresh: fresh=%v err=%v", second.Fresh, second.Err)
+	}
+
+	cfg.Force = true
+	if forced := builder.Compile(); !forced.OK() || forced.Fresh {
+		t.Fatalf("--force should rebuild: fresh=%v err=%v", forced.Fresh, forced.Err)
+	}
+	cfg.Force = false
+
+	mainFile := filepath.Join(root, "cmd", "api", "main.go")
+	if err := os.WriteFile(mainFile, []byte("package main\n\nfunc main() { println(1) }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed := builder.Compile(); !changed.OK() || changed.Fresh {
+		t.Fatalf("source change should trigger a rebuild: fresh=%v err=%v", changed.Fresh, changed.Err)
+	}
+
+	gcfg := gocarconfig.DefaultConfig()
+	gcfg.Build.Tags = []string{"netgo"}
+	tagged := NewBuilder(root, "api", "standard", cfg, gcfg)
+	if result := tagged.Compile(); !result.OK() || result.Fresh {
+		t.Fatalf("flag change should trigger a rebuild: fresh=%v err=%v", result.Fresh, result.Err)
+	}
+}
+
+func TestFingerprintHashesLocalReplace(t *testing.T) {
+	root := writeTestModule(t, "api")
+	lib := t.TempDir()
+	files := map[string]string{
+		filepath.Join(lib, "go.mod"):                 "module example.com/lib\n\ngo 1.21\n",
+		filepath.Join(lib, "lib.go"):                 "package lib\n\nfunc Name() string { return \"a\" }\n",
+		filepath.Join(root, "go.mod"):                "module example.com/api\n\ngo 1.21\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => " + filepath.ToSlash(lib) + "\n",
+		filepath.Join(root, "cmd", "api", "main.go"): "package main\n\nimport \"example.com/lib\"\n\nfunc main() { println(lib.Name()) }\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	builder := NewBuilder(root, "api", "standard", NewConfig(), nil)
+
+	if first := builder.Compile(); !first.OK() || first.Fresh {
+		t.Fatalf("first build: fresh=%v err=%v\n%s", first.Fresh, first.Err, first.Output)
+	}
+	if second := builder.Compile(); !second.OK() || !second.Fresh {
+		t.Fatalf("unchanged rebuild should be fresh: fresh=%v err=%v", second.Fresh, second.Err)
+	}
+
+	// 修改 replace 指向的本地模块应触发重新构建
+	if err := os.WriteFile(filepath.Join(lib, "lib.go"), []byte("package lib\n\nfunc Name() string { return \"b\" }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed := builder.Compile(); !changed.OK() || changed.Fresh {
+		t.Fatalf("change in a locally replaced module should trigger a rebuild: fresh=%v err=%v\n%s", changed.Fresh, changed.Err, changed.Output)
+	}
+}
+
+func TestUpdateManifestMergesArtifacts(t *testing.T) {
+	root := t.TempDir()
+	linux := &Result{Name: "api", Target: "linux/amd64", Profile: "release", Artifact: "bin/release/linux-amd64/api", SHA256: "aa", Size: 10}
+	darwin := &Result{Name: "api", Target: "darwin/arm64", Profile: "release", Artifact: "bin/release/darwin-arm64/api", SHA256: "bb", Size: 20}
+	failed := &Result{Name: "api", Target: "linux/nope", Artifact: "bin/release/linux-nope/api", Err: os.ErrInvalid}
+
+	if err := UpdateManifest(root, []*Result{linux, failed}); err != nil {
+		t.Fatal(err)
+	}
+	linux.SHA256 = "cc"
+	if err := UpdateManifest(root, []*Result{linux, darwin}); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := ReadManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Artifacts) != 2 {
+		t.Fatalf("manifest artifacts = %#v", manifest.Artifacts)
+	}
+	got := map[string]ManifestArtifact{}
+	for _, artifact := range manifest.Artifacts {
+		got[artifact.Target] = artifact
+	}
+	if got["linux/amd64"].SHA256 != "cc" || got["linux/amd64"].OS != "linux" || got["darwin/arm64"].Size != 20 {
+		t.Fatalf("unexpected manifest: %#v", manifest.Artifacts)
+	}
+}
+
+func TestRenderVars(t *testing.T) {
+	data := VarsData{
+		Version:     "1.4.0",
+		Commit:      "0123456789abcdef",
+		ShortCommit: "0123456",
+		Date:        "2024-01-02T03:04:05Z",
+		Dirty:       true,
+		Target:      "linux/arm64",
+		Profile:     "release",
+	}
+	vars := map[string]string{
+		"myapp/internal/buildinfo.Version": "{{.Version}}",
+		"myapp/internal/buildinfo.Commit":  "{{.ShortCommit}}{{if .Dirty}}-dirty{{end}}",
+		"main.banner":                      "{{.Target}} {{.Profile}} {{.Date}}",
+	}
+
+	got, err := renderVars(vars, data)
+	if err != nil {
+		t.Fatalf("renderVars() unexpected error: %v", err)
+	}
+	want := "-X 'main.banner=linux/arm64 release 2024-01-02T03:04:05Z'" +
+		" -X myapp/internal/buildinfo.Commit=0123456-dirty" +
+		" -X myapp/internal/buildinfo.Version=1.4.0"
+	if got != want {
+		t.Fatalf("renderVars() = %q, want %q", got, want)
+	}
+
+	if _, err := renderVars(map[string]string{"main.x": "{{.Unknown}}"}, data); err == nil {
+		t.Fatal("expected unknown template field to fail")
+	}
+	if _, err := renderVars(map[string]string{"main.x": `it's "quoted"`}, data); err == nil {
+		t.Fatal("expected value with both quote kinds to fail")
+	}
+}
+
+func TestSourceDateOutsideGit(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	root := t.TempDir()
+	modTime := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	for _, name := range []string{"go.mod", "main.go", filepath.Join(".git", "x.go")} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 隐藏目录中的文件不参与
+	hidden := filepath.Join(root, ".git", "x.go")
+	if err := os.Chtimes(hidden, modTime.Add(time.Hour), modTime.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := SourceDate(root); !got.Equal(modTime) {
+		t.Fatalf("SourceDate() = %v, want the newest source mtime %v", got, modTime)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	if got := SourceDate(root); got.Unix() != 1700000000 {
+		t.Fatalf("SourceDate() = %v, want SOURCE_DATE_EPOCH", got)
+	}
+}
+
+func TestCompileRunsPostBuildHook(t *testing.T) {
+	root := writeTestModule(t, "api")
+	cfg := NewConfig()
+	gcfg := gocarconfig.DefaultConfig()
+	gcfg.Hooks.PostBuild = `echo "$GOCAR_HOOK $GOCAR_TARGET $GOCAR_PROFILE" >> hook.log; cp "$GOCAR_ARTIFACT" "$GOCAR_PROJECT_ROOT/copied"; echo "main.go:1:1: signed"`
+	builder := NewBuilder(root, "api", "standard", cfg, gcfg)
+
+	result := builder.Compile()
+	if !result.OK() {
+		t.Fatalf("build failed: %v\n%s", result.Err, result.Output)
+	}
+	log, err := os.ReadFile(filepath.Join(root, "hook.log"))
+	if err != nil {
+		t.Fatalf("post_build hook did not run: %v", err)
+	}
+	line := "post_build " + cfg.TargetOS + "/" + cfg.TargetArch + " debug\n"
+	if string(log) != line {
+		t.Fatalf("hook.log = %q, want %q", log, line)
+	}
+	if _, err := os.Stat(filepath.Join(root, "copied")); err != nil {
+		t.Fatalf("GOCAR_ARTIFACT was not usable: %v", err)
+	}
+
+	// 钩子输出单独保存，不当作编译输出解析
+	if string(result.HookOutput) != "main.go:1:1: signed\n" {
+		t.Fatalf("HookOutput = %q", result.HookOutput)
+	}
+	if strings.Contains(string(result.Output), "signed") || len(result.Diagnostics) != 0 {
+		t.Fatalf("hook output leaked into compiler output: %q %v", result.Output, result.Diagnostics)
+	}
+
+	// 指纹未变化时跳过构建，但仍然执行 post_build
+	if err := os.Remove(filepath.Join(root, "copied")); err != nil {
+		t.Fatal(err)
+	}
+	result = builder.Compile()
+	if !result.OK() || !result.Fresh {
+		t.Fatalf("expected a fresh build: fresh=%v err=%v", result.Fresh, result.Err)
+	}
+	if log, _ := os.ReadFile(filepath.Join(root, "hook.log")); string(log) != line+line {
+		t.Fatalf("post_build hook should run for fresh artifacts, hook.log = %q", log)
+	}
+	if _, err := os.Stat(filepath.Join(root, "copied")); err != nil {
+		t.Fatalf("fresh artifact was not copied again: %v", err)
+	}
+
+	gcfg.Hooks.PostBuild = "exit 3"
+	if result := builder.Compile(); result.OK() {
+		t.Fatalf("failing post_build hook should fail the build: fresh=%v err=%v", result.Fresh, result.Err)
+	}
+
+	// SkipHooks (gocar bloat) 不执行钩子，但不影响构建指纹
+	cfg.SkipHooks = true
+	if result := builder.Compile(); !result.OK() || !result.Fresh {
+		t.Fatalf("SkipHooks build should ignore the failing hook and stay fresh: fresh=%v err=%v", result.Fresh, result.Err)
+	}
+
+	// NoFingerprint 总是重新构建，也不写入指纹
+	removeFingerprint(builder.GetOutputPath())
+	cfg.NoFingerprint = true
+	if result := builder.Compile(); !result.OK() || result.Fresh {
+		t.Fatalf("NoFingerprint build should rebuild: fresh=%v err=%v", result.Fresh, result.Err)
+	}
+	if _, err := os.Stat(fingerprintPath(builder.GetOutputPath())); !os.IsNotExist(err) {
+		t.Fatalf("NoFingerprint build should not write a fingerprint: %v", err)
+	}
+}
+
+func TestValidateTargetSuggestions(t *testing.T) {
+	platforms := []Platform{
+		{GOOS: "linux", GOARCH: "amd64", CgoSupported: true, FirstClass: true},
+		{GOOS: "linux", GOARCH: "arm64", CgoSupported: true, FirstClass: true},
+		{GOOS: "darwin", GOARCH: "arm64", CgoSupported: true, FirstClass: true},
+		{GOOS: "js", GOARCH: "wasm"},
+	}
+
+	if err := ValidateTarget(platforms, "linux", "arm64"); err != nil {
+		t.Fatalf("ValidateTarget(linux/arm64) unexpected error: %v", err)
+	}
+	err := ValidateTarget(platforms, "linux", "amd46")
+	if err == nil || !strings.Contains(err.Error(), "did you mean linux/amd64?") {
+		t.Fatalf("ValidateTarget(linux/amd46) = %v, want linux/amd64 suggestion", err)
+	}
+	err = ValidateTarget(platforms, "plan9", "sparc")
+	if err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Fatalf("ValidateTarget(plan9/sparc) = %v, want error without suggestion", err)
+	}
+	if _, _, _, err := ParseTarget("linux/"); err == nil {
+		t.Fatal("expected ParseTarget to reject an empty architecture")
+	}
+}
+
+func TestTargetVariants(t *testing.T) {
+	goos, arch, variant, err := ParseTarget("linux/arm/7")
+	if err != nil || goos != "linux" || arch != "arm" || variant != "v7" {
+		t.Fatalf("ParseTarget(linux/arm/7) = %q %q %q %v", goos, arch, variant, err)
+	}
+	for _, target := range []string{"linux/amd64/v5", "linux/riscv64/rva99u64", "linux/s390x/z15", "linux/arm/v7/extra"} {
+		if _, _, _, err := ParseTarget(target); err == nil {
+			t.Fatalf("ParseTarget(%s) expected error", target)
+		}
+	}
+
+	cfg := NewConfig()
+	cfg.SetTarget("linux", "arm")
+	cfg.TargetVariant = variant
+	builder := NewBuilder("/repo", "api", "standard", cfg, nil)
+	if got := builder.Target(); got != "linux/arm/v7" {
+		t.Fatalf("Target() = %q", got)
+	}
+	if got := builder.GetRelativeOutputPath(); got != filepath.Join("bin", "debug", "linux-arm-v7", "api") {
+		t.Fatalf("GetRelativeOutputPath() = %q", got)
+	}
+	if env := builder.buildEnv(); !slices.Contains(env, "GOARM=7") {
+		t.Fatalf("buildEnv() missing GOARM=7")
+	}
+
+	for _, tc := range [][3]string{{"amd64", "v3", "GOAMD64=v3"}, {"arm64", "v8.2", "GOARM64=v8.2"}, {"386", "softfloat", "GO386=softfloat"}, {"mipsle", "softfloat", "GOMIPS=softfloat"}, {"mips64", "hardfloat", "GOMIPS64=hardfloat"}, {"riscv64", "rva22u64", "GORISCV64=rva22u64"}, {"ppc64le", "power9", "GOPPC64=power9"}} {
+		if got, err := VariantEnv(tc[0], tc[1]); err != nil || got != tc[2] {
+			t.Fatalf("VariantEnv(%s, %s) = %q, %v; want %q", tc[0], tc[1], got, err, tc[2])
+		}
+	}
+}
+
+func TestBuilderUsesProfileSettings(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Profile = "sqlite"
+	cfg.SetTarget("linux", "amd64")
+
+	gcfg := gocarconfig.DefaultConfig()
+	gcfg.Build.Tags = []string{"netgo"}
+	gcfg.Build.ExtraEnv = []string{"FOO=build"}
+	cover := true
+	gcfg.Profile.Profiles["sqlite"] = gocarconfig.ProfileConfig{
+		Inherits:     "release",
+		Tags:         []string{"sqlite", "netgo"},
+		ExtraEnv:     []string{"FOO=profile"},
+		Asmflags:     "all=-trimpath",
+		Buildmode:    "pie",
+		Mod:          "vendor",
+		Goexperiment: "loopvar",
+		Cover:        &cover,
+		Pgo:          "off",
+	}
+	builder := NewBuilder("/repo", "api", "standard", cfg, gcfg)
+
+	flags, err := builder.resolveFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := builder.buildCommand("/repo/bin/sqlite/linux-amd64/api", flags)
+	for _, want := range []string{"-ldflags=-s -w", "-trimpath", "-tags=netgo,sqlite", "-asmflags=all=-trimpath", "-buildmode=pie", "-mod=vendor", "-cover", "-pgo=off"} {
+		if !slices.Contains(cmd.Args, want) {
+			t.Fatalf("expected %q in args: %#v", want, cmd.Args)
+		}
+	}
+	if slices.ContainsFunc(cmd.Args, func(arg string) bool { return strings.HasPrefix(arg, "-coverpkg") }) {
+		t.Fatalf("cover = true alone should not add -coverpkg: %#v", cmd.Args)
+	}
+
+	env := cmd.Env
+	if !slices.Contains(env, "GOEXPERIMENT=loopvar") || !slices.Contains(env, "CGO_ENABLED=0") {
+		t.Fatalf("expected GOEXPERIMENT and inherited CGO_ENABLED in env")
+	}
+	if slices.Index(env, "FOO=profile") < slices.Index(env, "FOO=build") {
+		t.Fatalf("profile extra_env should come after [build].extra_env")
+	}
+
+	// coverpkg 隐含 cover
+	gcfg.Profile.Profiles["sqlite"] = gocarconfig.ProfileConfig{Inherits: "release", Coverpkg: []string{"./...", "example.com/lib"}}
+	builder = NewBuilder("/repo", "api", "standard", cfg, gcfg)
+	if flags, err = builder.resolveFlags(); err != nil {
+		t.Fatal(err)
+	}
+	cmd = builder.buildCommand("/repo/bin/sqlite/linux-amd64/api", flags)
+	for _, want := range []string{"-cover", "-coverpkg=./...,example.com/lib"} {
+		if !slices.Contains(cmd.Args, want) {
+			t.Fatalf("expected %q in args: %#v", want, cmd.Args)
+		}
+	}
+}
+
+func TestBuildmodeArtifactNames(t *testing.T) {
+	tests := []struct {
+		goos, buildmode, want string
+	}{
+		{"linux", "c-shared", "libapi.so"},
+		{"darwin", "c-shared", "libapi.dylib"},
+		{"windows", "c-shared", "api.dll"},
+		{"windows", "c-archive", "libapi.a"},
+		{"linux", "plugin", "api.so"},
+		{"windows", "pie", "api.exe"},
+		{"linux", "", "api"},
+	}
+	for _, tt := range tests {
+		if got := artifactFileName("api", tt.goos, tt.buildmode); got != tt.want {
+			t.Fatalf("artifactFileName(%s, %s) = %q, want %q", tt.goos, tt.buildmode, got, tt.want)
+		}
+	}
+	if got := headerPath(filepath.Join("bin", "libapi.so"), "c-shared"); got != filepath.Join("bin", "libapi.h") {
+		t.Fatalf("headerPath() = %q", got)
+	}
+}
+
+func TestBuilderValidateBuildmode(t *testing.T) {
+	root := writeTestModule(t, "api")
+	cfg := NewConfig()
+	cfg.SetTarget("linux", "amd64")
+	cfg.Buildmode = "c-shared"
+	builder := NewBuilder(root, "api", "standard", cfg, nil)
+
+	if got := builder.GetRelativeOutputPath(); got != filepath.Join("bin", "debug", "linux-amd64", "libapi.so") {
+		t.Fatalf("GetRelativeOutputPath() = %q", got)
+	}
+	if err := builder.Validate(); err == nil || !strings.Contains(err.Error(), "//export") {
+		t.Fatalf("Validate() = %v, want missing //export error", err)
+	}
+
+	exported := "package main\n\nimport \"C\"\n\n//export Add\nfunc Add(a, b C.int) C.int { return a + b }\n\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(root, "cmd", "api", "main.go"), []byte(exported), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if env := builder.buildEnv(); !slices.Contains(env, "CGO_ENABLED=1") {
+		t.Fatal("c-shared should enable CGO")
+	}
+
+	cfg.Buildmode = "plugin"
+	cfg.SetTarget("windows", "amd64")
+	if err := builder.Validate(); err == nil {
+		t.Fatal("expected plugin on windows to be rejected")
+	}
+}
+
+func TestKeepSymbolsStripsLinkerFlags(t *testing.T) {
+	root := writeTestModule(t, "api")
+	cfg := NewConfig()
+	cfg.Profile = "release"
+	cfg.Release = true
+	cfg.KeepSymbols = true
+	cfg.OutputDir = filepath.Join("bin", "gocar-bloat")
+	gocarConfig := gocarconfig.DefaultConfig()
+	gocarConfig.Build.Ldflags = "-s -X 'main.note=a -w b'"
+	builder := NewBuilder(root, "api", "standard", cfg, gocarConfig)
+
+	flags, err := builder.resolveFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range splitFlagFields(flags.Ldflags) {
+		if field == "-s" || field == "-w" {
+			t.Fatalf("ldflags = %q, want -s/-w removed", flags.Ldflags)
+		}
+	}
+	if !strings.Contains(flags.Ldflags, "-X 'main.note=a -w b'") {
+		t.Fatalf("ldflags = %q, want quoted -X value preserved", flags.Ldflags)
+	}
+	if got, want := builder.GetRelativeOutputPath(), filepath.Join("bin", "gocar-bloat", "release", cfg.TargetOS+"-"+cfg.TargetArch, "api"); got != want && got != want+".exe" {
+		t.Fatalf("GetRelativeOutputPath() = %q, want %q", got, want)
+	}
+}
+
+func TestParseActionGraph(t *testing.T) {
+	data := []byte(`[
+  {"ID": 0, "Mode": "link-install", "Package": "app", "Deps": [1], "TimeStart": "2024-01-01T00:00:01.000Z", "TimeDone": "2024-01-01T00:00:01.010Z"},
+  {"ID": 1, "Mode": "link", "Package": "app", "Deps": [2], "Cmd": ["link"], "TimeStart": "2024-01-01T00:00:00.600Z", "TimeDone": "2024-01-01T00:00:01.000Z"},
+  {"ID": 2, "Mode": "build", "Package": "app", "Deps": [3, 4], "Cmd": ["compile"], "TimeStart": "2024-01-01T00:00:00.300Z", "TimeDone": "2024-01-01T00:00:00.600Z"},
+  {"ID": 3, "Mode": "build", "Package": "fmt", "Deps": [], "TimeStart": "2024-01-01T00:00:00.000Z", "TimeDone": "2024-01-01T00:00:00.001Z"},
+  {"ID": 4, "Mode": "build", "Package": "example.com/slow", "Deps": [], "Cmd": ["compile"], "TimeStart": "2024-01-01T00:00:00.000Z", "TimeDone": "2024-01-01T00:00:00.300Z"},
+  {"ID": 5, "Mode": "nop", "Package": "", "Deps": [0]}
+]`)
+	timings, err := ParseActionGraph(data)
+	if err != nil {
+		t.Fatalf("ParseActionGraph() error = %v", err)
+	}
+	if timings.TotalMs != 1010 {
+		t.Errorf("TotalMs = %v, want 1010", timings.TotalMs)
+	}
+	if timings.CacheHits != 1 || timings.CacheMisses != 2 {
+		t.Errorf("cache hits/misses = %d/%d, want 1/2", timings.CacheHits, timings.CacheMisses)
+	}
+	wantPath := []string{"compile example.com/slow", "compile app", "link app"}
+	if !slices.Equal(timings.CriticalPath, wantPath) {
+		t.Errorf("CriticalPath = %v, want %v", timings.CriticalPath, wantPath)
+	}
+	if len(timings.Units) != 4 {
+		t.Fatalf("Units = %+v, want 4 compile/link units", timings.Units)
+	}
+	for _, unit := range timings.Units {
+		if unit.Package == "fmt" && (!unit.Cached || unit.Critical) {
+			t.Errorf("fmt unit = %+v, want cached and off the critical path", unit)
+		}
+	}
+	if slowest := timings.Slowest(); slowest[0].Mode != "link" {
+		t.Errorf("Slowest()[0] = %+v, want link", slowest[0])
+	}
+}
+
+func TestWriteTimingsReport(t *testing.T) {
+	root := t.TempDir()
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := []*Result{
+		{Name: "api", Target: "linux/amd64", TimingsData: &Timings{Name: "api", Target: "linux/amd64", Started: started, TotalMs: 500}},
+		{Name: "api", Target: "windows/amd64", Err: errors.New("build failed")},
+		{Name: "api", Target: "darwin/arm64", TimingsData: &Timings{Name: "api", Target: "darwin/arm64", Started: started.Add(time.Second), TotalMs: 1000}},
+	}
+
+	path, err := WriteTimingsReport(root, results)
+	if err != nil {
+		t.Fatalf("WriteTimingsReport() error = %v", err)
+	}
+	if want := filepath.Join("target", "timings.html"); path != want {
+		t.Fatalf("WriteTimingsReport() = %q, want %q", path, want)
+	}
+	html, err := os.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range []string{"linux/amd64", "darwin/arm64"} {
+		if !strings.Contains(string(html), target) {
+			t.Errorf("timings.html does not mention %s", target)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "target", "timings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report TimingsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Builds) != 2 || report.TotalMs != 2000 || !report.Started.Equal(started) {
+		t.Fatalf("report = %+v, want 2 builds spanning 2000ms", report)
+	}
+
+	if path, err := WriteTimingsReport(root, results[1:2]); err != nil || path != "" {
+		t.Fatalf("WriteTimingsReport() without timings = %q, %v; want no report", path, err)
+	}
+}
+
+func TestPGOProfileResolution(t *testing.T) {
+	root := writeTestModule(t, "api")
+	cfg := NewConfig()
+	gcfg := gocarconfig.DefaultConfig()
+	builder := NewBuilder(root, "api", "standard", cfg, gcfg)
+
+	defaultPath := filepath.Join("cmd", "api", PGOFileName)
+	if got := builder.DefaultPGOPath(); got != defaultPath {
+		t.Fatalf("DefaultPGOPath() = %q, want %q", got, defaultPath)
+	}
+	if got := builder.PGOProfile(); got != "" {
+		t.Fatalf("PGOProfile() without default.pgo = %q, want empty", got)
+	}
+	if err := os.WriteFile(filepath.Join(root, defaultPath), []byte("profile"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := builder.PGOProfile(); got != defaultPath {
+		t.Fatalf("PGOProfile() = %q, want %q", got, defaultPath)
+	}
+
+	profile := gcfg.Profile.Profiles["debug"]
+	profile.Pgo = PgoOff
+	gcfg.Profile.Profiles["debug"] = profile
+	if got := builder.PGOProfile(); got != "" {
+		t.Fatalf("PGOProfile() with pgo=off = %q, want empty", got)
+	}
+	profile.Pgo = "profiles/cpu.pprof"
+	gcfg.Profile.Profiles["debug"] = profile
+	if got := builder.PGOProfile(); got != "profiles/cpu.pprof" {
+		t.Fatalf("PGOProfile() = %q, want explicit path", got)
+	}
+
+	if got := DefaultPGOPath(root, filepath.Join("cmd", "api", "main.go")); got != defaultPath {
+		t.Fatalf("DefaultPGOPath(file entry) = %q, want %q", got, defaultPath)
+	}
+}
+
+func TestArtifactTemplates(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Profile = "release"
+	cfg.Release = true
+	cfg.SetTarget("windows", "amd64")
+	gcfg := gocarconfig.DefaultConfig()
+	gcfg.Project.Version = "1.4.0"
+	gcfg.Build.Output = "dist"
+	gcfg.Build.ArtifactDir = "."
+	gcfg.Build.ArtifactName = "{name}_{version}_{os}_{arch}"
+	builder := NewBuilder("/repo", "myapp", "standard", cfg, gcfg)
+
+	if got, want := builder.GetRelativeOutputPath(), filepath.Join("dist", "myapp_1.4.0_windows_amd64.exe"); got != want {
+		t.Fatalf("GetRelativeOutputPath() = %q, want %q", got, want)
+	}
+	cfg.SetTarget("darwin", "arm64")
+	if got, want := builder.UniversalOutputPath(), filepath.Join("dist", "myapp_1.4.0_darwin_universal"); got != want {
+		t.Fatalf("UniversalOutputPath() = %q, want %q", got, want)
+	}
+
+	gcfg.Build.ArtifactDir = "{profile}/{os}{variant}"
+	gcfg.Build.ArtifactName = "lib{name}{ext}"
+	cfg.SetTarget("linux", "arm")
+	cfg.TargetVariant = "v7"
+	cfg.Buildmode = "c-shared"
+	if got, want := builder.GetRelativeOutputPath(), filepath.Join("dist", "release", "linuxv7", "libmyapp.so"); got != want {
+		t.Fatalf("GetRelativeOutputPath() = %q, want %q", got, want)
+	}
+
+	gcfg.Build.ArtifactDir = "../outside"
+	result := builder.Compile()
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "escapes the build output directory") {
+		t.Fatalf("Compile() error = %v, want escaping artifact_dir to be rejected", result.Err)
+	}
+}
+
+func TestBuilderQueriesGitOnce(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := writeTestModule(t, "api")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	git("tag", "v1.0.0")
+
+	cfg := NewConfig()
+	cfg.SetTarget("linux", "amd64")
+	gcfg := gocarconfig.DefaultConfig()
+	gcfg.Project.Version = gocarconfig.VersionFromGit
+	gcfg.Build.ArtifactName = "{name}_{version}_{commit}"
+	gcfg.Build.Vars = map[string]string{"main.commit": "{{.ShortCommit}}"}
+	builder := NewBuilder(root, "api", "standard", cfg, gcfg)
+
+	path := builder.GetRelativeOutputPath()
+	flags, err := builder.resolveFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(path, "api_1.0.0_") || !strings.Contains(flags.Ldflags, "main.version=1.0.0") {
+		t.Fatalf("path = %q, ldflags = %q, want version 1.0.0", path, flags.Ldflags)
+	}
+
+	// 同一 Builder 不再重新查询 git，新的 Builder 看到新的提交与标签
+	git("commit", "-q", "--allow-empty", "-m", "next")
+	git("tag", "v2.0.0")
+	again, _ := builder.resolveFlags()
+	if got := builder.GetRelativeOutputPath(); got != path || again.Ldflags != flags.Ldflags {
+		t.Fatalf("second lookup = %q, %q, want cached %q, %q", got, again.Ldflags, path, flags.Ldflags)
+	}
+	if got := NewBuilder(root, "api", "standard", cfg, gcfg).GetRelativeOutputPath(); !strings.Contains(got, "api_2.0.0_") {
+		t.Fatalf("new builder path = %q, want version 2.0.0", got)
+	}
+}
+
+func TestExplainAnnotatesSources(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Profile = "ci"
+	cfg.ProfileOrigin = CLIOrigin("--profile")
+	cfg.WithCGO = true
+	cfg.SetTarget("linux", "arm64")
+	cfg.TargetOrigin = "[build].targets"
+
+	gcfg := gocarconfig.DefaultConfig()
+	gcfg.Project.Version = "2.0.0"
+	gcfg.Build.Ldflags = "-X main.commit=abc"
+	gcfg.Build.Tags = []string{"netgo"}
+	gcfg.Build.ExtraEnv = []string{"GOFLAGS=-mod=mod"}
+	race := true
+	gcfg.Profile.Profiles["ci"] = gocarconfig.ProfileConfig{Inherits: "release", Race: &race}
+	builder := NewBuilder("/repo", "api", "standard", cfg, gcfg)
+
+	plan, err := builder.Explain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Args[0] != "go" || plan.Args[1] != "build" || plan.Dir != "/repo" {
+		t.Fatalf("Explain() command = %v in %s", plan.Args, plan.Dir)
+	}
+
+	sources := map[string]string{}
+	for _, setting := range append(plan.Env, plan.Settings...) {
+		sources[setting.Name+" "+setting.Value] = setting.Source
+	}
+	for key, want := range map[string]string{
+		"profile ci":                     "CLI flag (--profile)",
+		"GOARCH arm64":                   "[build].targets",
+		"CGO_ENABLED 1":                  "CLI flag (--with-cgo)",
+		"GOFLAGS -mod=mod":               "[build].extra_env",
+		"-ldflags -s -w":                 "[profile.release] via inherits (ci -> release)",
+		"-ldflags -X main.version=2.0.0": "[project].version",
+		"-ldflags -X main.commit=abc":    "[build].ldflags",
+		"-race ":                         "[profile.ci]",
+		"-trimpath ":                     "[profile.release] via inherits (ci -> release)",
+		"-tags netgo":                    "[build].tags",
+		"entry ./cmd/api":                OriginDefault,
+	} {
+		if sources[key] != want {
+			t.Errorf("source of %q = %q, want %q", key, sources[key], want)
+		}
+	}
+}
+
+func TestExplainInheritedProfileOrigins(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Profile = "ci"
+	cfg.Timings = true
+
+	gcfg := gocarconfig.DefaultConfig()
+	race := true
+	gcfg.Profile.Profiles["staging"] = gocarconfig.ProfileConfig{Inherits: "release", Gcflags: "-N -l"}
+	gcfg.Profile.Profiles["ci"] = gocarconfig.ProfileConfig{Inherits: "staging", Race: &race, Tags: []string{"ci"}}
+	builder := NewBuilder("/repo", "api", "standard", cfg, gcfg)
+
+	plan, err := builder.Explain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := map[string]string{}
+	for _, setting := range plan.Settings {
+		sources[setting.Name+" "+setting.Value] = setting.Source
+	}
+	for key, want := range map[string]string{
+		"-race ":         "[profile.ci]",
+		"-tags ci":       "[profile.ci]",
+		"-gcflags -N -l": "[profile.staging] via inherits (ci -> staging)",
+		"-ldflags -s -w": "[profile.release] via inherits (ci -> staging -> release)",
+		"-trimpath ":     "[profile.release] via inherits (ci -> staging -> release)",
+	} {
+		if sources[key] != want {
+			t.Errorf("source of %q = %q, want %q", key, sources[key], want)
+		}
+	}
+
+	// --timings 时执行计划中的命令与 Compile 实际执行的命令一致
+	if plan.Args[2] != "-debug-actiongraph="+filepath.Join(os.TempDir(), actionGraphPattern) {
+		t.Fatalf("Explain() with --timings = %v, want -debug-actiongraph after go build", plan.Args)
+	}
+}
+
+func TestWriteFatMachO(t *testing.T) {
+	dir := t.TempDir()
+	// 只有 mach_header_64、没有加载命令的最小 Mach-O 可执行文件
+	thin := func(name string, cpu macho.Cpu, subCpu uint32) string {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, macho.FileHeader{Magic: macho.Magic64, Cpu: cpu, SubCpu: subCpu, Type: macho.TypeExec})
+		buf.Write(make([]byte, 4)) // reserved
+		buf.WriteString(name)
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	amd64 := thin("amd64", macho.CpuAmd64, 3)
+	arm64 := thin("arm64", macho.CpuArm64, 0)
+
+	output := filepath.Join(dir, "darwin-universal", "app")
+	if err := WriteFatMachO(output, []string{amd64, arm64}); err != nil {
+		t.Fatal(err)
+	}
+	fat, err := macho.OpenFat(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fat.Close()
+	if len(fat.Arches) != 2 {
+		t.Fatalf("got %d arches, want 2", len(fat.Arches))
+	}
+	for i, want := range []struct {
+		cpu    macho.Cpu
+		subCpu uint32
+		align  uint32
+		input  string
+	}{{macho.CpuAmd64, 3, 12, amd64}, {macho.CpuArm64, 0, 14, arm64}} {
+		arch := fat.Arches[i]
+		if arch.Cpu != want.cpu || arch.SubCpu != want.subCpu || arch.Align != want.align || arch.Offset%(1<<want.align) != 0 {
+			t.Fatalf("arch %d = %+v", i, arch.FatArchHeader)
+		}
+		content, _ := os.ReadFile(want.input)
+		got := make([]byte, arch.Size)
+		if f, err := os.Open(output); err == nil {
+			f.ReadAt(got, int64(arch.Offset))
+			f.Close()
+		}
+		if !bytes.Equal(got, content) {
+			t.Fatalf("arch %d content differs from %s", i, want.input)
+		}
+	}
+	if info, err := os.Stat(output); err != nil || info.Mode().Perm() != 0755 {
+		t.Fatalf("output mode = %v, %v", info, err)
+	}
+
+	if err := WriteFatMachO(output, []string{amd64, amd64}); err == nil {
+		t.Fatal("merging the same architecture twice should fail")
+	}
+}
+
+func TestCompileEmbedsWindowsResources(t *testing.T) {
+	root := writeTestModule(t, "api")
+	cfg := NewConfig()
+	cfg.SetTarget("windows", "amd64")
+	gcfg := gocarconfig.DefaultConfig()
+	gcfg.Project.Version = "2.1.0"
+	gcfg.Windows.CompanyName = "Acme"
+	gcfg.Windows.ExecutionLevel = "asInvoker"
+
+	builder := NewBuilder(root, "api", "standard", cfg, gcfg)
+	syso := filepath.Join("cmd", "api", "rsrc_windows_amd64.syso")
+	if got := builder.WindowsResourcePath(); got != syso {
+		t.Fatalf("WindowsResourcePath() = %q, want %q", got, syso)
+	}
+	result := builder.Compile()
+	if !result.OK() {
+		t.Fatalf("build failed: %v\n%s", result.Err, result.Output)
+	}
+	if _, err := os.Stat(filepath.Join(root, syso)); !os.IsNotExist(err) {
+		t.Fatalf("resource file should be removed after the build: %v", err)
+	}
+	exe, err := pe.Open(builder.GetOutputPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exe.Close()
+	if exe.Section(".rsrc") == nil {
+		t.Fatal("executable has no .rsrc section")
+	}
+
+	// 资源参与指纹，版本号变化时重新构建
+	if again := builder.Compile(); !again.OK() || !again.Fresh {
+		t.Fatalf("unchanged rebuild should be fresh: fresh=%v err=%v", again.Fresh, again.Err)
+	}
+	gcfg.Project.Version = "2.2.0"
+	builder = NewBuilder(root, "api", "standard", cfg, gcfg)
+	if changed := builder.Compile(); !changed.OK() || changed.Fresh {
+		t.Fatalf("version change should trigger a rebuild: fresh=%v err=%v", changed.Fresh, changed.Err)
+	}
+
+	// 中断的构建遗留的 gocar 生成的文件 (内容为旧版本) 被覆盖并在构建后删除
+	stale, err := winres.Build("amd64", &winres.Options{Version: &winres.VersionInfo{FileVersion: winres.ParseVersion("1.0.0")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, syso), stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Force = true
+	if result := builder.Compile(); !result.OK() {
+		t.Fatalf("stale generated resource file should be replaced: %v", result.Err)
+	}
+	if _, err := os.Stat(filepath.Join(root, syso)); !os.IsNotExist(err) {
+		t.Fatalf("resource file should be removed after the build: %v", err)
+	}
+
+	// 不覆盖非 gocar 生成的同名文件
+	if err := os.WriteFile(filepath.Join(root, syso), []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := builder.Compile(); result.OK() || !strings.Contains(result.Err.Error(), "already exists") {
+		t.Fatalf("existing resource file should be reported, got %v", result.Err)
+	}
+
+	cfg.SetTarget("linux", "amd64")
+	if got := builder.WindowsResourcePath(); got != "" {
+		t.Fatalf("non-windows targets should not get resources, got %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package build
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// 支持通过 --buildmode 指定的构建模式
+var Buildmodes = []string{"exe", "pie", "c-shared", "c-archive", "plugin"}
+
+// buildmode 返回生效的构建模式：--buildmode 优先，其次为 profile 中的 buildmode
+func (b *Builder) buildmode() string {
+	if b.config.Buildmode != "" {
+		return b.config.Buildmode
+	}
+	if profile := b.profile(); profile != nil {
+		return profile.Buildmode
+	}
+	return ""
+}
+
+// Executable 检查产物是否为可执行文件，c-shared、c-archive 与 plugin 构建的是库
+func (b *Builder) Executable() bool {
+	switch b.buildmode() {
+	case "", "exe", "pie":
+		return true
+	}
+	return false
+}
+
+// RequiresCgo 检查构建模式是否需要 cgo
+func RequiresCgo(buildmode string) bool {
+	return buildmode == "c-shared" || buildmode == "c-archive" || buildmode == "plugin"
+}
+
+// artifactFileName 根据目标系统与构建模式返回产物文件名：
+// c-shared 为 lib<name>.so / lib<name>.dylib / <name>.dll，c-archive 为 lib<name>.a，
+// plugin 为 <name>.so，其余为可执行文件 (windows 追加 .exe)
+func artifactFileName(name, goos, buildmode string) string {
+	switch buildmode {
+	case "c-shared":
+		switch goos {
+		case "windows":
+			return name + ".dll"
+		case "darwin", "ios":
+			return "lib" + name + ".dylib"
+		default:
+			return "lib" + name + ".so"
+		}
+	case "c-archive":
+		return "lib" + name + ".a"
+	case "plugin":
+		return name + ".so"
+	}
+	if goos == "windows" {
+		return name + ".exe"
+	}
+	return name
+}
+
+// artifactExt 返回产物的扩展名 (含 "."，可执行文件在非 Windows 平台上为空)，用于 {ext} 占位符
+func artifactExt(goos, buildmode string) string {
+	return filepath.Ext(artifactFileName("artifact", goos, buildmode))
+}
+
+// headerPath 返回 c-shared/c-archive 构建生成的 C 头文件路径，其他模式返回空字符串。
+// go build 会将头文件写在产物旁，文件名为去掉扩展名的产物名加 .h。
+func headerPath(outputPath, buildmode string) string {
+	if buildmode != "c-shared" && buildmode != "c-archive" {
+		return ""
+	}
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".h"
+}
+
+// Validate 在构建前检查构建模式与目标、入口包是否匹配
+func (b *Builder) Validate() error {
+	buildmode := b.buildmode()
+	if buildmode == "" {
+		return nil
+	}
+	if !slices.Contains(Buildmodes, buildmode) {
+		return fmt.Errorf("unsupported buildmode %q (expected one of: %s)", buildmode, strings.Join(Buildmodes, ", "))
+	}
+	if buildmode == "plugin" && b.config.TargetOS == "windows" {
+		return fmt.Errorf("buildmode plugin is not supported on windows")
+	}
+
+	entry := b.entry()
+	dir := entry
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(b.projectRoot, dir)
+	}
+	pkgName, exports, err := scanEntryPackage(dir)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", entry, err)
+	}
+	if pkgName != "main" {
+		return fmt.Errorf("buildmode %s requires a main package, but %s is package %s", buildmode, entry, pkgName)
+	}
+	if buildmode == "c-shared" || buildmode == "c-archive" {
+		if exports == 0 {
+			return fmt.Errorf("buildmode %s requires at least one //export function in %s", buildmode, entry)
+		}
+	}
+	return nil
+}
+
+// scanEntryPackage 解析入口目录（不含测试文件），返回包名与 //export 注释数量
+func scanEntryPackage(dir string) (string, int, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", 0, err
+	}
+	files := []string{dir}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	fset := token.NewFileSet()
+	pkgName := ""
+	exports := 0
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return "", 0, err
+		}
+		if pkgName == "" {
+			pkgName = parsed.Name.Name
+		}
+		for _, group := range parsed.Comments {
+			for _, comment := range group.List {
+				if strings.HasPrefix(comment.Text, "//export ") {
+					exports++
+				}
+			}
+		}
+	}
+	if pkgName == "" {
+		return "", 0, fmt.Errorf("no Go files found")
+	}
+	return pkgName, exports, nil
+}
@@ -4,11 +4,21 @@ import "runtime"
 
 // Config 构建配置
 type Config struct {
-	Release    bool   // 是否为发布模式
-	Profile    string // 构建 profile 名称
-	TargetOS   string // 目标操作系统
-	TargetArch string // 目标架构
-	WithCGO    bool   // 是否启用 CGO
+	Release       bool   // 是否为发布模式
+	Profile       string // 构建 profile 名称
+	TargetOS      string // 目标操作系统
+	TargetArch    string // 目标架构
+	TargetVariant string // 目标架构子版本 (如 arm 的 v7、amd64 的 v3)，为空时使用工具链默认值
+	WithCGO       bool   // 是否启用 CGO
+	Force         bool   // 忽略构建指纹，强制重新构建
+	Buildmode     string // 构建模式 (--buildmode)，为空时使用 profile 中的 buildmode
+	KeepSymbols   bool   // 保留符号表，从 ldflags 中去掉 -s/-w (gocar bloat 使用)
+	OutputDir     string // 覆盖输出根目录 (相对项目根目录)，为空时使用 [build].output
+	SkipHooks     bool   // 不执行 post_build 钩子 (gocar bloat 的分析构建)
+	NoFingerprint bool   // 不读写构建指纹，总是执行 go build (gocar bloat 的分析构建)
+	Timings       bool   // 记录动作图并生成构建耗时报告 (--timings)
+	ProfileOrigin string // profile 的来源 (如 --release)，为空表示默认值，用于 --dry-run 标注
+	TargetOrigin  string // 目标平台的来源 (如 --target、[build].targets)，为空表示当前平台
 }
 
 // NewConfig 创建默认构建配置
@@ -30,7 +40,7 @@ func (c *Config) SetTarget(os, arch string) {
 
 // IsCurrentPlatform 检查是否为当前平台
 func (c *Config) IsCurrentPlatform() bool {
-	return c.TargetOS == runtime.GOOS && c.TargetArch == runtime.GOARCH
+	return c.TargetOS == runtime.GOOS && c.TargetArch == runtime.GOARCH && c.TargetVariant == ""
 }
 
 // BuildMode 返回构建模式字符串
@@ -0,0 +1,97 @@
+package build
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"gocar/internal/diag"
+)
+
+// 消息格式
+const (
+	MessageFormatHuman = "human"
+	MessageFormatJSON  = "json"
+	MessageFormatSARIF = "sarif"
+)
+
+// 构建事件类型 (--message-format json)
+const (
+	EventBuildStarted       = "build-started"
+	EventCompilerDiagnostic = "compiler-diagnostic"
+	EventArtifact           = "artifact"
+	EventBuildFinished      = "build-finished"
+)
+
+// Event 以换行分隔的 JSON 输出的构建事件
+type Event struct {
+	Reason     string           `json:"reason"`
+	Name       string           `json:"name,omitempty"`
+	Target     string           `json:"target,omitempty"`
+	Profile    string           `json:"profile,omitempty"`
+	Diagnostic *diag.Diagnostic `json:"message,omitempty"`
+	Path       string           `json:"path,omitempty"`
+	SHA256     string           `json:"sha256,omitempty"`
+	Size       int64            `json:"size,omitempty"`
+	Fresh      bool             `json:"fresh,omitempty"`
+	Timings    string           `json:"timings,omitempty"`
+	Success    *bool            `json:"success,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	DurationMs int64            `json:"duration_ms,omitempty"`
+}
+
+// EventWriter 串行写入 JSON 事件
+type EventWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewEventWriter 创建事件输出器
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{enc: json.NewEncoder(w)}
+}
+
+// Emit 输出单个事件
+func (w *EventWriter) Emit(event Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.enc.Encode(event)
+}
+
+// EmitStarted 输出 build-started 事件
+func (w *EventWriter) EmitStarted(b *Builder) {
+	w.Emit(Event{Reason: EventBuildStarted, Name: b.Name(), Target: b.Target(), Profile: b.config.BuildMode()})
+}
+
+// EmitResult 输出构建结果对应的诊断与产物事件
+func (w *EventWriter) EmitResult(result *Result) {
+	for _, d := range result.Diagnostics {
+		w.Emit(Event{Reason: EventCompilerDiagnostic, Name: result.Name, Target: result.Target, Diagnostic: &d})
+	}
+	if !result.OK() {
+		return
+	}
+	w.Emit(Event{
+		Reason:     EventArtifact,
+		Name:       result.Name,
+		Target:     result.Target,
+		Profile:    result.Profile,
+		Path:       result.Artifact,
+		SHA256:     result.SHA256,
+		Size:       result.Size,
+		Fresh:      result.Fresh,
+		Timings:    result.Timings,
+		DurationMs: result.Duration.Milliseconds(),
+	})
+}
+
+// EmitFinished 输出 build-finished 事件
+func (w *EventWriter) EmitFinished(err error, elapsed time.Duration) {
+	success := err == nil
+	event := Event{Reason: EventBuildFinished, Success: &success, DurationMs: elapsed.Milliseconds()}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	w.Emit(event)
+}
@@ -0,0 +1,228 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gocar/internal/config"
+)
+
+// OriginDefault 未经配置或命令行修改的默认值
+const OriginDefault = "default"
+
+// Setting 解析后的单项设置及其来源 (default、profile、[build]、命令行参数等)
+type Setting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// Plan 一次执行的完整命令、工作目录与 gocar 添加或覆盖的环境变量 (--dry-run / --explain)
+type Plan struct {
+	Title    string
+	Args     []string  // 完整命令行，Args[0] 为可执行文件
+	Dir      string    // 工作目录
+	Env      []Setting // gocar 添加或覆盖的环境变量，其余变量继承自当前环境
+	Settings []Setting // 命令行中各参数的来源
+}
+
+// CLIOrigin 返回命令行参数来源的标注
+func CLIOrigin(flag string) string {
+	return "CLI flag (" + flag + ")"
+}
+
+// Explain 返回构建命令的执行计划，不执行构建也不读写构建指纹
+func (b *Builder) Explain() (*Plan, error) {
+	flags, err := b.resolveFlags()
+	if err != nil {
+		return nil, err
+	}
+	rel, err := b.relativeOutputPath()
+	if err != nil {
+		return nil, err
+	}
+	cmd := b.buildCommand(filepath.Join(b.projectRoot, rel), flags)
+	if b.config.Timings {
+		// 与 Compile 相同，动作图写入临时文件 (实际构建时由 os.CreateTemp 生成文件名)
+		addActionGraph(cmd, filepath.Join(os.TempDir(), actionGraphPattern))
+	}
+
+	profileOrigin := b.config.ProfileOrigin
+	if profileOrigin == "" {
+		profileOrigin = OriginDefault
+	}
+	settings := []Setting{
+		{Name: "profile", Value: b.config.BuildMode(), Source: profileOrigin},
+		{Name: "target", Value: b.Target(), Source: b.targetOrigin()},
+	}
+	settings = append(settings, flags.ldflagParts...)
+
+	add := func(name, value, field string) {
+		settings = append(settings, Setting{Name: name, Value: value, Source: flags.origins[field]})
+	}
+	if flags.Gcflags != "" {
+		add("-gcflags", flags.Gcflags, "gcflags")
+	}
+	if flags.Asmflags != "" {
+		add("-asmflags", flags.Asmflags, "asmflags")
+	}
+	for _, flag := range []struct {
+		name  string
+		set   bool
+		field string
+	}{
+		{"-trimpath", flags.Trimpath, "trimpath"},
+		{"-race", flags.Race, "race"},
+		{"-msan", flags.Msan, "msan"},
+		{"-asan", flags.Asan, "asan"},
+		{"-cover", flags.Cover, "cover"},
+	} {
+		if flag.set {
+			add(flag.name, "", flag.field)
+		}
+	}
+	if len(flags.Coverpkg) > 0 {
+		add("-coverpkg", strings.Join(flags.Coverpkg, ","), "coverpkg")
+	}
+	if flags.Buildmode != "" {
+		add("-buildmode", flags.Buildmode, "buildmode")
+	}
+	if flags.Mod != "" {
+		add("-mod", flags.Mod, "mod")
+	}
+	if flags.Pgo != "" {
+		add("-pgo", flags.Pgo, "pgo")
+	} else if pgo := b.PGOProfile(); pgo != "" {
+		// 未设置 pgo 时 go build 默认使用 main 包目录下的 default.pgo
+		settings = append(settings, Setting{Name: "pgo", Value: pgo, Source: OriginDefault + " (-pgo=auto)"})
+	}
+	if len(flags.Tags) > 0 {
+		add("-tags", strings.Join(flags.Tags, ","), "tags")
+	}
+	if path := b.WindowsResourcePath(); path != "" {
+		settings = append(settings, Setting{Name: "resources", Value: path, Source: "[windows]"})
+	}
+	if b.config.Timings {
+		settings = append(settings, Setting{Name: "-debug-actiongraph", Value: filepath.Join(os.TempDir(), actionGraphPattern), Source: CLIOrigin("--timings")})
+	}
+	settings = append(settings,
+		Setting{Name: "-o", Value: rel, Source: b.outputOrigin()},
+		Setting{Name: "entry", Value: b.entry(), Source: b.entryOrigin()},
+	)
+
+	return &Plan{
+		Title:    "build " + b.appName + " (" + b.Target() + ", " + b.config.BuildMode() + ")",
+		Args:     cmd.Args,
+		Dir:      cmd.Dir,
+		Env:      b.envSettings(),
+		Settings: settings,
+	}, nil
+}
+
+// targetOrigin 返回目标平台的来源
+func (b *Builder) targetOrigin() string {
+	if b.config.TargetOrigin != "" {
+		return b.config.TargetOrigin
+	}
+	return OriginDefault + " (host)"
+}
+
+// binOrigin 返回 [[bin]] 设置的来源
+func (b *Builder) binOrigin() string {
+	return "[[bin]] " + b.bin.Name
+}
+
+// outputOrigin 返回产物路径的来源
+func (b *Builder) outputOrigin() string {
+	origins := []string{}
+	if b.config.OutputDir != "" {
+		origins = append(origins, "CLI output directory")
+	} else if b.gocarConfig != nil && b.gocarConfig.Build.Output != "" && b.gocarConfig.GetBuildOutputRoot() != "bin" {
+		origins = append(origins, "[build].output")
+	}
+	if b.gocarConfig != nil && b.gocarConfig.Build.ArtifactDir != "" {
+		origins = append(origins, "[build].artifact_dir")
+	}
+	if b.gocarConfig != nil && b.gocarConfig.Build.ArtifactName != "" {
+		origins = append(origins, "[build].artifact_name")
+	}
+	if len(origins) == 0 {
+		return OriginDefault
+	}
+	return strings.Join(origins, " + ")
+}
+
+// entryOrigin 返回构建入口的来源
+func (b *Builder) entryOrigin() string {
+	switch {
+	case b.bin != nil && b.bin.Entry != "":
+		return b.binOrigin()
+	case b.gocarConfig != nil && b.gocarConfig.Build.Entry != "":
+		return "[build].entry"
+	}
+	return OriginDefault
+}
+
+// profileOrigin 返回 profile 字段 (toml 键名) 的来源。
+// 沿 inherits 链找到设置该字段的 profile，来自父 profile 的值标注继承链；
+// 当前 profile 中与内置 debug/release profile 相同的值视为默认值。
+func (b *Builder) profileOrigin(field string) string {
+	if b.gocarConfig == nil {
+		return OriginDefault
+	}
+	_, name, ok := b.gocarConfig.GetProfileForBuild(b.config.Profile, b.config.Release)
+	if !ok {
+		return OriginDefault
+	}
+
+	chain := []string{name}
+	for parent := b.gocarConfig.Profile.Profiles[name].Inherits; parent != "" && !slices.Contains(chain, parent); parent = b.gocarConfig.Profile.Profiles[parent].Inherits {
+		chain = append(chain, parent)
+	}
+
+	// 设置了该字段的 profile，均未设置时取最顶层的 profile
+	source := chain[len(chain)-1]
+	for _, layer := range chain {
+		if profileFieldSet(profileField(b.gocarConfig.Profile.Profiles[layer], field)) {
+			source = layer
+			break
+		}
+	}
+
+	origin := "[profile." + source + "]"
+	if source != name {
+		return origin + " via inherits (" + strings.Join(chain[:slices.Index(chain, source)+1], " -> ") + ")"
+	}
+	if builtIn, ok := config.DefaultConfig().Profile.Profiles[source]; ok {
+		value, builtInValue := profileField(b.gocarConfig.Profile.Profiles[source], field), profileField(builtIn, field)
+		if value.IsValid() && reflect.DeepEqual(value.Interface(), builtInValue.Interface()) {
+			return OriginDefault + " (" + source + " profile)"
+		}
+	}
+	return origin
+}
+
+// profileFieldSet 判断 profile 字段是否被设置 (与 mergeProfile 的覆盖规则一致)
+func profileFieldSet(value reflect.Value) bool {
+	if !value.IsValid() {
+		return false
+	}
+	if value.Kind() == reflect.Slice {
+		return value.Len() > 0
+	}
+	return !value.IsZero()
+}
+
+// profileField 按 toml 键名取 ProfileConfig 的字段值
+func profileField(profile config.ProfileConfig, field string) reflect.Value {
+	value := reflect.ValueOf(profile)
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("toml") == field {
+			return value.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,262 @@
+package build
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fingerprintVersion 指纹格式版本，格式变化时递增以使旧指纹失效
+const fingerprintVersion = "1"
+
+// listedPackage go list -deps -json 输出中与指纹相关的字段
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	Standard   bool
+	Module     *struct {
+		Path    string
+		Version string
+		Main    bool
+		Replace *struct {
+			Path    string
+			Version string
+		}
+	}
+	GoFiles    []string
+	CgoFiles   []string
+	CFiles     []string
+	CXXFiles   []string
+	HFiles     []string
+	SFiles     []string
+	SysoFiles  []string
+	EmbedFiles []string
+}
+
+// listFields 传给 go list -json= 的字段列表
+const listFields = "ImportPath,Dir,Standard,Module,GoFiles,CgoFiles,CFiles,CXXFiles,HFiles,SFiles,SysoFiles,EmbedFiles"
+
+// fingerprintEnvExclude 不影响构建产物的 Go 环境变量
+var fingerprintEnvExclude = map[string]bool{
+	"GOCACHE":    true,
+	"GOMODCACHE": true,
+	"GOTMPDIR":   true,
+	"GOENV":      true,
+}
+
+// fingerprintPath 返回指纹文件路径（与产物位于同一目录）
+func fingerprintPath(outputPath string) string {
+	return filepath.Join(filepath.Dir(outputPath), "."+filepath.Base(outputPath)+".fingerprint")
+}
+
+// isFresh 检查产物是否存在且指纹未变化
+func isFresh(outputPath, fingerprint string) bool {
+	if fingerprint == "" {
+		return false
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		return false
+	}
+	stored, err := os.ReadFile(fingerprintPath(outputPath))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(stored)) == fingerprint
+}
+
+// writeFingerprint 在产物旁写入指纹
+func writeFingerprint(outputPath, fingerprint string) error {
+	if fingerprint == "" {
+		return nil
+	}
+	return os.WriteFile(fingerprintPath(outputPath), []byte(fingerprint+"\n"), 0644)
+}
+
+// removeFingerprint 删除产物旁的指纹，构建失败时调用
+func removeFingerprint(outputPath string) {
+	_ = os.Remove(fingerprintPath(outputPath))
+}
+
+// computeFingerprint 计算构建指纹。
+// 指纹覆盖入口依赖闭包中的源文件、go.mod/go.sum、完整构建参数、
+// 影响构建的环境变量、Go 版本与 PGO profile。第三方模块以 path@version 表示，
+// replace 到本地目录的模块按源文件计算。
+func computeFingerprint(cmd *exec.Cmd, goVersion, pgoProfile string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "gocar-fingerprint %s\n", fingerprintVersion)
+
+	// 构建参数（包含目标路径、ldflags、tags 等）
+	fmt.Fprintf(h, "args %q\n", cmd.Args)
+
+	// 环境变量
+	for _, kv := range fingerprintEnv(cmd.Env) {
+		fmt.Fprintf(h, "env %s\n", kv)
+	}
+
+	// Go 版本
+	fmt.Fprintf(h, "go %s\n", goVersion)
+
+	// PGO profile 内容变化会改变编译结果 (default.pgo 不在 go list 的文件列表中)
+	if pgoProfile != "" {
+		path := pgoProfile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cmd.Dir, path)
+		}
+		fmt.Fprintf(h, "pgo %s\n", pgoProfile)
+		if err := hashFile(h, path); err != nil {
+			return "", err
+		}
+	}
+
+	// 模块文件
+	for _, name := range []string{"go.mod", "go.sum", "go.work", "go.work.sum"} {
+		if err := hashFile(h, filepath.Join(cmd.Dir, name)); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	// 入口依赖闭包
+	packages, err := listDeps(cmd)
+	if err != nil {
+		return "", err
+	}
+	for _, pkg := range packages {
+		if pkg.Standard {
+			continue
+		}
+		// 第三方模块以版本表示；replace 到本地目录的模块 (没有版本) 与主模块一样哈希源文件
+		if module := pkg.Module; module != nil && !module.Main && module.Version != "" {
+			if module.Replace == nil {
+				fmt.Fprintf(h, "pkg %s %s@%s\n", pkg.ImportPath, module.Path, module.Version)
+				continue
+			}
+			if module.Replace.Version != "" {
+				fmt.Fprintf(h, "pkg %s %s@%s => %s@%s\n", pkg.ImportPath, module.Path, module.Version, module.Replace.Path, module.Replace.Version)
+				continue
+			}
+		}
+
+		fmt.Fprintf(h, "pkg %s\n", pkg.ImportPath)
+		files := [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.CXXFiles, pkg.HFiles, pkg.SFiles, pkg.SysoFiles, pkg.EmbedFiles}
+		for _, group := range files {
+			for _, name := range group {
+				fmt.Fprintf(h, "file %s\n", name)
+				if err := hashFile(h, filepath.Join(pkg.Dir, name)); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// listDeps 使用与构建相同的 tags、-mod 和环境变量列出入口的依赖闭包
+func listDeps(cmd *exec.Cmd) ([]listedPackage, error) {
+	args := []string{"list", "-deps", "-json=" + listFields}
+	for _, arg := range cmd.Args[2 : len(cmd.Args)-1] {
+		if strings.HasPrefix(arg, "-tags=") || strings.HasPrefix(arg, "-mod=") {
+			args = append(args, arg)
+		}
+	}
+	args = append(args, cmd.Args[len(cmd.Args)-1])
+
+	list := exec.Command("go", args...)
+	list.Dir = cmd.Dir
+	list.Env = cmd.Env
+	var stderr bytes.Buffer
+	list.Stderr = &stderr
+	output, err := list.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var packages []listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode go list output: %w", err)
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// fingerprintEnv 提取影响构建的环境变量，后出现的同名变量覆盖先前的值
+func fingerprintEnv(env []string) []string {
+	values := map[string]string{}
+	for _, kv := range env {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || fingerprintEnvExclude[key] {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(key, "GO"), strings.HasPrefix(key, "CGO_"):
+		case key == "CC", key == "CXX", key == "AR", key == "PKG_CONFIG":
+		default:
+			continue
+		}
+		values[key] = value
+	}
+
+	result := make([]string, 0, len(values))
+	for key, value := range values {
+		result = append(result, key+"="+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// goEnv 读取单个 go env 变量
+func goEnv(dir string, env []string, key string) (string, error) {
+	cmd := exec.Command("go", "env", key)
+	cmd.Dir = dir
+	cmd.Env = env
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env %s failed: %w", key, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// fileDigest 计算文件的 sha256 与大小
+func fileDigest(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fileHash := sha256.New()
+	if _, err := io.Copy(fileHash, f); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%x\n", fileHash.Sum(nil))
+	return nil
+}
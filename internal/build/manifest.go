@@ -0,0 +1,131 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestFileName 构建清单文件名，位于输出根目录
+const ManifestFileName = "manifest.json"
+
+// manifestVersion 清单格式版本
+const manifestVersion = 1
+
+// Manifest 构建清单，记录输出根目录下的所有产物
+type Manifest struct {
+	Version   int                `json:"version"`
+	Artifacts []ManifestArtifact `json:"artifacts"`
+}
+
+// ManifestArtifact 单个产物记录
+type ManifestArtifact struct {
+	Name       string   `json:"name"`
+	Path       string   `json:"path"`
+	Header     string   `json:"header,omitempty"`
+	SHA256     string   `json:"sha256"`
+	Size       int64    `json:"size"`
+	Target     string   `json:"target"`
+	OS         string   `json:"os"`
+	Arch       string   `json:"arch"`
+	Variant    string   `json:"variant,omitempty"`
+	Profile    string   `json:"profile"`
+	Ldflags    string   `json:"ldflags"`
+	Gcflags    string   `json:"gcflags"`
+	Tags       []string `json:"tags"`
+	GoVersion  string   `json:"go_version"`
+	DurationMs int64    `json:"duration_ms"`
+	BuiltAt    string   `json:"built_at"`
+}
+
+// ReadManifest 读取输出根目录中的构建清单，不存在时返回空清单
+func ReadManifest(outputRoot string) (*Manifest, error) {
+	manifest := &Manifest{Version: manifestVersion}
+	data, err := os.ReadFile(filepath.Join(outputRoot, ManifestFileName))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFileName, err)
+	}
+	return manifest, nil
+}
+
+// UpdateManifest 将成功的构建结果合并到输出根目录的 manifest.json。
+// 同一路径的旧记录会被替换，其他产物的记录保持不变。
+func UpdateManifest(outputRoot string, results []*Result) error {
+	manifest, err := ReadManifest(outputRoot)
+	if err != nil {
+		return err
+	}
+
+	byPath := map[string]ManifestArtifact{}
+	for _, artifact := range manifest.Artifacts {
+		byPath[artifact.Path] = artifact
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, result := range results {
+		if !result.OK() {
+			continue
+		}
+		path := filepath.ToSlash(result.Artifact)
+		if previous, ok := byPath[path]; ok && result.Fresh && previous.SHA256 == result.SHA256 {
+			continue
+		}
+		targetOS, targetArch, variant, _ := ParseTarget(result.Target)
+		tags := result.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		byPath[path] = ManifestArtifact{
+			Name:       result.Name,
+			Path:       path,
+			Header:     filepath.ToSlash(result.Header),
+			SHA256:     result.SHA256,
+			Size:       result.Size,
+			Target:     result.Target,
+			OS:         targetOS,
+			Arch:       targetArch,
+			Variant:    variant,
+			Profile:    result.Profile,
+			Ldflags:    result.Ldflags,
+			Gcflags:    result.Gcflags,
+			Tags:       tags,
+			GoVersion:  result.GoVersion,
+			DurationMs: result.Duration.Milliseconds(),
+			BuiltAt:    now,
+		}
+	}
+
+	manifest.Version = manifestVersion
+	manifest.Artifacts = manifest.Artifacts[:0]
+	for _, artifact := range byPath {
+		manifest.Artifacts = append(manifest.Artifacts, artifact)
+	}
+	sort.Slice(manifest.Artifacts, func(i, j int) bool {
+		return manifest.Artifacts[i].Path < manifest.Artifacts[j].Path
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outputRoot, 0755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免并发读取到半写入的清单
+	path := filepath.Join(outputRoot, ManifestFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
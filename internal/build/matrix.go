@@ -0,0 +1,125 @@
+package build
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"gocar/internal/config"
+	"gocar/internal/diag"
+)
+
+// Result 单个目标的构建结果
+type Result struct {
+	Name        string            // 应用名称
+	Target      string            // 目标平台 <os>/<arch>
+	Profile     string            // 构建 profile
+	Artifact    string            // 相对输出路径
+	Header      string            // c-shared/c-archive 生成的 C 头文件相对路径
+	Duration    time.Duration     // 构建耗时
+	Output      []byte            // go build 输出
+	Diagnostics []diag.Diagnostic // 从 go build 输出中解析的诊断
+	HookOutput  []byte            // post_build 钩子输出，不参与诊断解析
+	Fresh       bool              // 指纹未变化，跳过了构建
+	Err         error             // 构建错误
+	SHA256      string            // 产物 sha256
+	Size        int64             // 产物大小（字节）
+	Ldflags     string            // 最终 ldflags
+	Gcflags     string            // 最终 gcflags
+	Tags        []string          // 最终构建标签
+	GoVersion   string            // Go 工具链版本
+	Timings     string            // --timings 生成的该产物 HTML 报告相对路径
+	TimingsData *Timings          // --timings 解析的耗时，汇总到 target/timings.html
+}
+
+// OK 返回构建是否成功
+func (r *Result) OK() bool {
+	return r.Err == nil
+}
+
+// DefaultJobs 返回默认并行构建数
+func DefaultJobs() int {
+	return runtime.NumCPU()
+}
+
+// BuildMatrix 并行构建多个目标，最多同时运行 jobs 个构建。
+// 单个目标失败不会中断其他目标，结果按 builders 的顺序返回。
+// onDone 在每个目标完成后被串行调用，可用于输出进度。
+func BuildMatrix(builders []*Builder, jobs int, onDone func(*Result)) []*Result {
+	if jobs <= 0 {
+		jobs = DefaultJobs()
+	}
+	if jobs > len(builders) {
+		jobs = len(builders)
+	}
+
+	results := make([]*Result, len(builders))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i, builder := range builders {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := builder.Compile()
+			results[i] = result
+			if onDone != nil {
+				mu.Lock()
+				onDone(result)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// PrintSummary 输出构建结果汇总表
+func PrintSummary(w io.Writer, results []*Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BIN\tTARGET\tSTATUS\tDURATION\tARTIFACT")
+	for _, result := range results {
+		status := "ok"
+		artifact := result.Artifact
+		if result.Fresh {
+			status = "fresh"
+		}
+		if !result.OK() {
+			status = "FAILED"
+			artifact = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", result.Name, result.Target, status, result.Duration.Round(time.Millisecond), artifact)
+	}
+	tw.Flush()
+}
+
+// PrintHookOutput 原样输出 post_build 钩子的输出，每行加上 prefix 缩进
+func PrintHookOutput(w io.Writer, output []byte, prefix string) {
+	if len(output) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s%s hook output:\n", prefix, config.HookPostBuild)
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		fmt.Fprintf(w, "%s  %s\n", prefix, line)
+	}
+}
+
+// CountFailed 统计失败的构建数
+func CountFailed(results []*Result) int {
+	failed := 0
+	for _, result := range results {
+		if !result.OK() {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,61 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// PGO 设置
+const (
+	PgoAuto = "auto" // 使用 main 包目录下的 default.pgo (go build 的默认行为)
+	PgoOff  = "off"  // 禁用 PGO
+
+	// PGOFileName -pgo=auto 时在 main 包目录中查找的 profile 文件名
+	PGOFileName = "default.pgo"
+)
+
+// DefaultPGOPath 返回构建入口对应的 default.pgo 路径 (与 entry 同为相对项目根目录或绝对路径)。
+// 入口为单个文件时使用其所在目录。
+func DefaultPGOPath(projectRoot, entry string) string {
+	dir := entry
+	path := dir
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectRoot, path)
+	}
+	if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	return filepath.Join(dir, PGOFileName)
+}
+
+// DefaultPGOPath 返回当前构建入口的 default.pgo 路径
+func (b *Builder) DefaultPGOPath() string {
+	return DefaultPGOPath(b.projectRoot, b.entry())
+}
+
+// PGOProfile 返回本次构建实际使用的 profile 文件，未启用 PGO 或 auto 模式下文件不存在时返回空字符串
+func (b *Builder) PGOProfile() string {
+	pgo := ""
+	if profile := b.profile(); profile != nil {
+		pgo = profile.Pgo
+	}
+	switch pgo {
+	case PgoOff:
+		return ""
+	case "", PgoAuto:
+		path := b.DefaultPGOPath()
+		if _, err := os.Stat(b.absPath(path)); err != nil {
+			return ""
+		}
+		return path
+	}
+	return pgo
+}
+
+// absPath 将相对项目根目录的路径转换为绝对路径
+func (b *Builder) absPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(b.projectRoot, path)
+}
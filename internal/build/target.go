@@ -1,7 +1,12 @@
 package build
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -14,20 +19,202 @@ var CommonTargets = []string{
 	"windows/amd64",
 }
 
-// ParseTarget 解析目标平台字符串
-func ParseTarget(target string) (os, arch string, err error) {
+// Platform go tool dist list -json 中的单个目标平台
+type Platform struct {
+	GOOS         string `json:"GOOS"`
+	GOARCH       string `json:"GOARCH"`
+	CgoSupported bool   `json:"CgoSupported"`
+	FirstClass   bool   `json:"FirstClass"`
+}
+
+// String 返回 <os>/<arch> 形式的目标名称
+func (p Platform) String() string {
+	return p.GOOS + "/" + p.GOARCH
+}
+
+// IsCommon 检查是否为常见目标平台
+func (p Platform) IsCommon() bool {
+	for _, target := range CommonTargets {
+		if target == p.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTarget 解析目标平台字符串 <os>/<arch>[/<variant>]。
+// variant 为可选的架构子版本，例如 linux/arm/v7、linux/amd64/v3、linux/mips/softfloat。
+func ParseTarget(target string) (os, arch, variant string, err error) {
 	parts := strings.Split(target, "/")
-	if len(parts) != 2 {
-		return "", "", fmt.Errorf("invalid target format '%s', expected format: <os>/<arch>", target)
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid target format '%s', expected format: <os>/<arch>[/<variant>]", target)
+	}
+	if len(parts) == 2 {
+		return parts[0], parts[1], "", nil
+	}
+	variant, err = canonicalVariant(parts[1], parts[2])
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid target '%s': %w", target, err)
+	}
+	return parts[0], parts[1], variant, nil
+}
+
+// archVariants 每个架构对应的子版本环境变量及其可选值
+var archVariants = map[string]struct {
+	env    string
+	values []string
+}{
+	"arm":      {"GOARM", []string{"v5", "v6", "v7"}},
+	"amd64":    {"GOAMD64", []string{"v1", "v2", "v3", "v4"}},
+	"arm64":    {"GOARM64", []string{"v8.0", "v8.1", "v8.2", "v8.3", "v8.4", "v8.5", "v8.6", "v8.7", "v8.8", "v8.9", "v9.0", "v9.1", "v9.2", "v9.3", "v9.4", "v9.5"}},
+	"386":      {"GO386", []string{"sse2", "softfloat"}},
+	"mips":     {"GOMIPS", []string{"hardfloat", "softfloat"}},
+	"mipsle":   {"GOMIPS", []string{"hardfloat", "softfloat"}},
+	"mips64":   {"GOMIPS64", []string{"hardfloat", "softfloat"}},
+	"mips64le": {"GOMIPS64", []string{"hardfloat", "softfloat"}},
+	"ppc64":    {"GOPPC64", []string{"power8", "power9", "power10"}},
+	"ppc64le":  {"GOPPC64", []string{"power8", "power9", "power10"}},
+	"riscv64":  {"GORISCV64", []string{"rva20u64", "rva22u64", "rva23u64"}},
+}
+
+// canonicalVariant 校验架构子版本并返回规范形式（arm 的 7 规范为 v7）
+func canonicalVariant(arch, variant string) (string, error) {
+	spec, ok := archVariants[arch]
+	if !ok {
+		return "", fmt.Errorf("architecture %s has no variants", arch)
+	}
+	if arch == "arm" && !strings.HasPrefix(variant, "v") {
+		variant = "v" + variant
+	}
+	for _, value := range spec.values {
+		if value == variant {
+			return variant, nil
+		}
+	}
+	return "", fmt.Errorf("unknown %s variant %q (expected one of: %s)", arch, variant, strings.Join(spec.values, ", "))
+}
+
+// VariantEnv 返回架构子版本对应的环境变量，例如 arm/v7 -> GOARM=7
+func VariantEnv(arch, variant string) (string, error) {
+	variant, err := canonicalVariant(arch, variant)
+	if err != nil {
+		return "", err
+	}
+	spec := archVariants[arch]
+	if arch == "arm" {
+		variant = strings.TrimPrefix(variant, "v")
+	}
+	return spec.env + "=" + variant, nil
+}
+
+// LoadPlatforms 返回当前工具链支持的目标平台。
+// 结果按 Go 版本缓存在用户缓存目录中，切换工具链后会重新读取。
+func LoadPlatforms(dir string) ([]Platform, error) {
+	goVersion, err := goEnv(dir, nil, "GOVERSION")
+	if err != nil {
+		return nil, err
+	}
+
+	cachePath := ""
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		cachePath = filepath.Join(cacheDir, "gocar", "dist-list-"+goVersion+".json")
+		if data, err := os.ReadFile(cachePath); err == nil {
+			var platforms []Platform
+			if err := json.Unmarshal(data, &platforms); err == nil && len(platforms) > 0 {
+				return platforms, nil
+			}
+		}
+	}
+
+	cmd := exec.Command("go", "tool", "dist", "list", "-json")
+	cmd.Dir = dir
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go tool dist list failed: %w", err)
+	}
+	var platforms []Platform
+	if err := json.Unmarshal(data, &platforms); err != nil {
+		return nil, fmt.Errorf("failed to parse go tool dist list output: %w", err)
+	}
+
+	// 缓存写入失败不影响结果
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+			_ = os.WriteFile(cachePath, data, 0644)
+		}
+	}
+	return platforms, nil
+}
+
+// FindPlatform 查找目标平台
+func FindPlatform(platforms []Platform, goos, goarch string) (Platform, bool) {
+	for _, p := range platforms {
+		if p.GOOS == goos && p.GOARCH == goarch {
+			return p, true
+		}
 	}
-	return parts[0], parts[1], nil
+	return Platform{}, false
 }
 
-// ValidateTarget 验证目标平台
-func ValidateTarget(os, arch string) error {
-	// 这里可以添加更严格的验证逻辑
-	if os == "" || arch == "" {
+// ValidateTarget 验证目标平台是否被当前工具链支持，不支持时给出相近的建议
+func ValidateTarget(platforms []Platform, goos, goarch string) error {
+	if goos == "" || goarch == "" {
 		return fmt.Errorf("OS and architecture cannot be empty")
 	}
-	return nil
+	if _, ok := FindPlatform(platforms, goos, goarch); ok {
+		return nil
+	}
+
+	target := goos + "/" + goarch
+	if suggestions := suggestTargets(platforms, target); len(suggestions) > 0 {
+		return fmt.Errorf("unsupported target %q; did you mean %s? (run 'gocar targets' to list supported targets)", target, strings.Join(suggestions, " or "))
+	}
+	return fmt.Errorf("unsupported target %q (run 'gocar targets' to list supported targets)", target)
+}
+
+// suggestTargets 返回编辑距离最小的目标平台（最多 3 个）
+func suggestTargets(platforms []Platform, target string) []string {
+	maxDistance := max(2, len(target)/3)
+	best := maxDistance + 1
+	suggestions := []string{}
+	for _, p := range platforms {
+		d := editDistance(target, p.String())
+		switch {
+		case d < best:
+			best = d
+			suggestions = []string{p.String()}
+		case d == best:
+			suggestions = append(suggestions, p.String())
+		}
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > 3 {
+		suggestions = suggestions[:3]
+	}
+	return suggestions
+}
+
+// editDistance 计算两个字符串的 Levenshtein 距离，相邻字符交换计为一次编辑
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
 }
@@ -0,0 +1,368 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimingsDir 单个产物的构建耗时报告目录 (位于输出根目录下)
+const TimingsDir = "gocar-timings"
+
+// 汇总的构建耗时报告 (相对项目根目录)，与 cargo build --timings 的位置一致
+const (
+	TimingsReportDir  = "target"
+	TimingsReportHTML = "timings.html"
+	TimingsReportJSON = "timings.json"
+)
+
+// actionGraphPattern 动作图临时文件名模板 (os.CreateTemp)
+const actionGraphPattern = "gocar-actiongraph-*.json"
+
+// addActionGraph 在 go build 之后插入 -debug-actiongraph，记录构建动作的耗时
+func addActionGraph(cmd *exec.Cmd, path string) {
+	cmd.Args = slices.Insert(cmd.Args, 2, "-debug-actiongraph="+path)
+}
+
+// actionGraphEntry go build -debug-actiongraph 输出中的单个动作
+type actionGraphEntry struct {
+	ID        int
+	Mode      string
+	Package   string
+	Deps      []int
+	Cmd       []string // 实际执行的编译/链接命令，命中缓存时为空
+	TimeStart time.Time
+	TimeDone  time.Time
+}
+
+// TimingUnit 单个包的编译或链接耗时
+type TimingUnit struct {
+	Package    string  `json:"package"`
+	Mode       string  `json:"mode"`     // compile 或 link
+	StartMs    float64 `json:"start_ms"` // 相对构建开始的毫秒数
+	DurationMs float64 `json:"duration_ms"`
+	Cached     bool    `json:"cached"`   // 命中构建缓存，未重新编译
+	Critical   bool    `json:"critical"` // 位于关键路径上
+}
+
+// Timings 构建耗时报告 (gocar build --timings)
+type Timings struct {
+	Name         string       `json:"name"`
+	Target       string       `json:"target"`
+	Profile      string       `json:"profile"`
+	GoVersion    string       `json:"go_version,omitempty"`
+	Started      time.Time    `json:"started"`
+	TotalMs      float64      `json:"total_ms"`
+	CacheHits    int          `json:"cache_hits"`
+	CacheMisses  int          `json:"cache_misses"`
+	CriticalPath []string     `json:"critical_path"`
+	Units        []TimingUnit `json:"units"`
+}
+
+// TimingsReport 一次 gocar build --timings 的汇总报告，包含每个产物的耗时
+type TimingsReport struct {
+	Started time.Time  `json:"started"`
+	TotalMs float64    `json:"total_ms"` // 从最早开始的构建到最晚结束的构建
+	Builds  []*Timings `json:"builds"`
+}
+
+// newTimingsReport 汇总多个产物的耗时报告
+func newTimingsReport(builds []*Timings) *TimingsReport {
+	report := &TimingsReport{Builds: builds}
+	var end time.Time
+	for _, timings := range builds {
+		if report.Started.IsZero() || timings.Started.Before(report.Started) {
+			report.Started = timings.Started
+		}
+		if done := timings.Started.Add(time.Duration(timings.TotalMs * float64(time.Millisecond))); done.After(end) {
+			end = done
+		}
+	}
+	report.TotalMs = milliseconds(end.Sub(report.Started))
+	return report
+}
+
+// ParseActionGraph 解析 -debug-actiongraph 输出，得到每个包的编译与链接耗时。
+// 关键路径从最后完成的动作开始，逐级回溯到最晚完成的依赖。
+func ParseActionGraph(data []byte) (*Timings, error) {
+	var actions []actionGraphEntry
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return nil, fmt.Errorf("failed to parse action graph: %w", err)
+	}
+
+	byID := map[int]*actionGraphEntry{}
+	var start, end time.Time
+	var last *actionGraphEntry
+	for i := range actions {
+		action := &actions[i]
+		byID[action.ID] = action
+		if action.TimeStart.IsZero() || action.TimeDone.IsZero() {
+			continue
+		}
+		if start.IsZero() || action.TimeStart.Before(start) {
+			start = action.TimeStart
+		}
+		if action.TimeDone.After(end) {
+			end = action.TimeDone
+			last = action
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("action graph contains no executed actions")
+	}
+
+	critical := map[int]bool{}
+	path := []*actionGraphEntry{}
+	for action := last; action != nil; {
+		critical[action.ID] = true
+		path = append(path, action)
+		var next *actionGraphEntry
+		for _, id := range action.Deps {
+			dep, ok := byID[id]
+			if ok && !dep.TimeDone.IsZero() && (next == nil || dep.TimeDone.After(next.TimeDone)) {
+				next = dep
+			}
+		}
+		action = next
+	}
+
+	timings := &Timings{Started: start, TotalMs: milliseconds(end.Sub(start))}
+	for i := len(path) - 1; i >= 0; i-- {
+		if mode := unitMode(path[i].Mode); mode != "" {
+			timings.CriticalPath = append(timings.CriticalPath, mode+" "+path[i].Package)
+		}
+	}
+	for _, action := range actions {
+		mode := unitMode(action.Mode)
+		if mode == "" || action.TimeStart.IsZero() || action.TimeDone.IsZero() {
+			continue
+		}
+		unit := TimingUnit{
+			Package:    action.Package,
+			Mode:       mode,
+			StartMs:    milliseconds(action.TimeStart.Sub(start)),
+			DurationMs: milliseconds(action.TimeDone.Sub(action.TimeStart)),
+			Cached:     len(action.Cmd) == 0,
+			Critical:   critical[action.ID],
+		}
+		if mode == "compile" {
+			if unit.Cached {
+				timings.CacheHits++
+			} else {
+				timings.CacheMisses++
+			}
+		}
+		timings.Units = append(timings.Units, unit)
+	}
+	sort.SliceStable(timings.Units, func(i, j int) bool {
+		if timings.Units[i].StartMs != timings.Units[j].StartMs {
+			return timings.Units[i].StartMs < timings.Units[j].StartMs
+		}
+		return timings.Units[i].Package < timings.Units[j].Package
+	})
+	return timings, nil
+}
+
+// unitMode 将动作类型映射为报告中的单元类型，其余动作 (缓存检查、安装等) 不计入报告
+func unitMode(mode string) string {
+	switch mode {
+	case "build":
+		return "compile"
+	case "link":
+		return "link"
+	}
+	return ""
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Slowest 返回按耗时从大到小排序的单元
+func (t *Timings) Slowest() []TimingUnit {
+	units := slices.Clone(t.Units)
+	sort.SliceStable(units, func(i, j int) bool {
+		return units[i].DurationMs > units[j].DurationMs
+	})
+	return units
+}
+
+// timingsPaths 返回耗时报告的相对路径 (HTML 与 JSON)
+func (b *Builder) timingsPaths() (string, string) {
+	targetDir := strings.ReplaceAll(b.Target(), "/", "-")
+	base := filepath.Join(b.outputRoot(), TimingsDir, fmt.Sprintf("%s-%s-%s", b.appName, b.config.BuildMode(), targetDir))
+	return base + ".html", base + ".json"
+}
+
+// writeTimings 读取动作图并写入该产物的 HTML 与 JSON 报告，返回 HTML 报告的相对路径。
+// 解析结果保存在 result.TimingsData 中，供 WriteTimingsReport 汇总。
+func (b *Builder) writeTimings(actionGraph string, result *Result) (string, error) {
+	data, err := os.ReadFile(actionGraph)
+	if err != nil {
+		return "", err
+	}
+	timings, err := ParseActionGraph(data)
+	if err != nil {
+		return "", err
+	}
+	timings.Name = result.Name
+	timings.Target = result.Target
+	timings.Profile = result.Profile
+	timings.GoVersion = result.GoVersion
+	result.TimingsData = timings
+
+	htmlPath, jsonPath := b.timingsPaths()
+	if err := writeTimingsFiles(filepath.Join(b.projectRoot, htmlPath), filepath.Join(b.projectRoot, jsonPath), timings, newTimingsReport([]*Timings{timings})); err != nil {
+		return "", err
+	}
+	return htmlPath, nil
+}
+
+// WriteTimingsReport 将所有产物的耗时汇总写入 target/timings.html 与 target/timings.json，
+// 返回 HTML 报告的相对路径。没有产物记录耗时 (如全部构建失败) 时返回空字符串。
+func WriteTimingsReport(projectRoot string, results []*Result) (string, error) {
+	builds := []*Timings{}
+	for _, result := range results {
+		if result.TimingsData != nil {
+			builds = append(builds, result.TimingsData)
+		}
+	}
+	if len(builds) == 0 {
+		return "", nil
+	}
+	report := newTimingsReport(builds)
+	htmlPath := filepath.Join(TimingsReportDir, TimingsReportHTML)
+	jsonPath := filepath.Join(TimingsReportDir, TimingsReportJSON)
+	if err := writeTimingsFiles(filepath.Join(projectRoot, htmlPath), filepath.Join(projectRoot, jsonPath), report, report); err != nil {
+		return "", err
+	}
+	return htmlPath, nil
+}
+
+// writeTimingsFiles 将 data 写为 JSON，将 report 渲染为 HTML
+func writeTimingsFiles(htmlPath, jsonPath string, data any, report *TimingsReport) error {
+	if err := os.MkdirAll(filepath.Dir(htmlPath), 0755); err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(jsonPath, append(encoded, '\n'), 0644); err != nil {
+		return err
+	}
+
+	file, err := os.Create(htmlPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return timingsTemplate.Execute(file, report)
+}
+
+// timingsTemplate 自包含的 HTML 甘特图，不依赖外部资源，每个产物一节
+var timingsTemplate = template.Must(template.New("timings").Funcs(template.FuncMap{
+	"ms": func(v float64) string {
+		if v >= 1000 {
+			return fmt.Sprintf("%.2fs", v/1000)
+		}
+		return fmt.Sprintf("%.1fms", v)
+	},
+	"percent": func(v, total float64) string {
+		if total <= 0 {
+			return "0"
+		}
+		return fmt.Sprintf("%.3f", v*100/total)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Build timings{{if eq (len .Builds) 1}}: {{(index .Builds 0).Name}} {{(index .Builds 0).Target}}{{end}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 22px; } h2 { font-size: 18px; margin-top: 36px; } h3 { font-size: 15px; margin-top: 22px; }
+table { border-collapse: collapse; font-size: 13px; }
+th, td { padding: 3px 10px; text-align: left; border-bottom: 1px solid #eee; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.legend span { display: inline-block; margin-right: 16px; font-size: 12px; }
+.legend i { display: inline-block; width: 12px; height: 12px; margin-right: 4px; vertical-align: middle; }
+.chart { font-size: 12px; }
+.row { position: relative; height: 18px; }
+.row:hover { background: #f5f5f5; }
+.label { position: absolute; left: 0; width: 320px; overflow: hidden; white-space: nowrap; text-overflow: ellipsis; line-height: 18px; }
+.track { position: absolute; left: 330px; right: 8px; top: 0; bottom: 0; }
+.bar { position: absolute; top: 3px; height: 12px; min-width: 1px; background: #4e8fd6; }
+.bar.cached { background: #c9ccd1; }
+.bar.link { background: #9463d3; }
+.bar.critical { box-shadow: 0 0 0 2px #d9433b; }
+tr.critical td:first-child { color: #d9433b; font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>Build timings</h1>
+<table>
+<tr><th>Started</th><td>{{.Started.Format "2006-01-02 15:04:05"}}</td></tr>
+<tr><th>Total time</th><td>{{ms .TotalMs}}</td></tr>
+</table>
+{{- if gt (len .Builds) 1}}
+<table>
+<tr><th>Binary</th><th>Target</th><th>Profile</th><th>Duration</th><th>Compiled</th><th>Cached</th></tr>
+{{- range .Builds}}
+<tr><td>{{.Name}}</td><td>{{.Target}}</td><td>{{.Profile}}</td><td class="num">{{ms .TotalMs}}</td><td class="num">{{.CacheMisses}}</td><td class="num">{{.CacheHits}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Builds}}
+
+<h2>{{.Name}} {{.Target}}</h2>
+<table>
+<tr><th>Target</th><td>{{.Target}}</td></tr>
+<tr><th>Profile</th><td>{{.Profile}}</td></tr>
+{{- if .GoVersion}}
+<tr><th>Go version</th><td>{{.GoVersion}}</td></tr>
+{{- end}}
+<tr><th>Started</th><td>{{.Started.Format "2006-01-02 15:04:05"}}</td></tr>
+<tr><th>Total time</th><td>{{ms .TotalMs}}</td></tr>
+<tr><th>Packages</th><td>{{.CacheMisses}} compiled, {{.CacheHits}} cached</td></tr>
+</table>
+
+<h3>Critical path</h3>
+<ol>
+{{- range .CriticalPath}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+
+<h3>Timeline</h3>
+<div class="legend">
+<span><i style="background:#4e8fd6"></i>compiled</span>
+<span><i style="background:#c9ccd1"></i>cached</span>
+<span><i style="background:#9463d3"></i>link</span>
+<span><i style="box-shadow:0 0 0 2px #d9433b"></i>critical path</span>
+</div>
+<div class="chart">
+{{- $total := .TotalMs}}
+{{- range .Units}}
+<div class="row"><div class="label" title="{{.Mode}} {{.Package}}">{{.Package}}</div><div class="track"><div class="bar{{if eq .Mode "link"}} link{{else if .Cached}} cached{{end}}{{if .Critical}} critical{{end}}" style="left: {{percent .StartMs $total}}%; width: {{percent .DurationMs $total}}%" title="{{.Mode}} {{.Package}}: {{ms .DurationMs}} at {{ms .StartMs}}"></div></div></div>
+{{- end}}
+</div>
+
+<h3>Units</h3>
+<table>
+<tr><th>Package</th><th>Mode</th><th>Start</th><th>Duration</th><th>Cache</th></tr>
+{{- range .Slowest}}
+<tr{{if .Critical}} class="critical"{{end}}><td>{{.Package}}</td><td>{{.Mode}}</td><td class="num">{{ms .StartMs}}</td><td class="num">{{ms .DurationMs}}</td><td>{{if .Cached}}hit{{else}}miss{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
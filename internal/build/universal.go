@@ -0,0 +1,180 @@
+package build
+
+import (
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UniversalTarget 通用二进制在结果与 manifest.json 中的目标名称
+const UniversalTarget = "darwin/universal"
+
+// UniversalTargets 返回通用二进制 (--universal) 包含的目标平台，目前只支持 darwin
+func UniversalTargets(goos string) ([]string, error) {
+	if goos != "darwin" {
+		return nil, fmt.Errorf("universal binaries are only supported for darwin, got %q", goos)
+	}
+	return []string{"darwin/amd64", "darwin/arm64"}, nil
+}
+
+// UniversalOutputPath 返回通用二进制的相对输出路径，按 [build].artifact_name/artifact_dir 模板以 arch=universal 计算，
+// 默认为 <output>/<profile>/darwin-universal/<name>
+func (b *Builder) UniversalOutputPath() string {
+	path, err := b.artifactPath("universal", "")
+	if err != nil {
+		return filepath.Join(b.outputRoot(), b.defaultArtifactPath("universal", ""))
+	}
+	return path
+}
+
+// MergeUniversal 将同一二进制各架构的构建结果合并为通用二进制。
+// builders 与 results 一一对应；各架构均未重新构建且通用二进制已存在时跳过合并。
+func MergeUniversal(builders []*Builder, results []*Result) *Result {
+	start := time.Now()
+	first := builders[0]
+	result := &Result{
+		Name:      first.Name(),
+		Target:    UniversalTarget,
+		Profile:   first.config.BuildMode(),
+		Artifact:  first.UniversalOutputPath(),
+		Ldflags:   results[0].Ldflags,
+		Gcflags:   results[0].Gcflags,
+		Tags:      results[0].Tags,
+		GoVersion: results[0].GoVersion,
+		Fresh:     true,
+	}
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	inputs := make([]string, len(builders))
+	for i, builder := range builders {
+		if !results[i].OK() {
+			result.Err = fmt.Errorf("%s %s failed", builder.Name(), builder.Target())
+			return result
+		}
+		if !results[i].Fresh {
+			result.Fresh = false
+		}
+		inputs[i] = builder.GetOutputPath()
+	}
+
+	outputPath := filepath.Join(first.projectRoot, result.Artifact)
+	if !result.Fresh || !fileExists(outputPath) {
+		result.Fresh = false
+		if err := WriteFatMachO(outputPath, inputs); err != nil {
+			result.Err = fmt.Errorf("failed to merge universal binary: %w", err)
+			return result
+		}
+	}
+
+	sum, size, err := fileDigest(outputPath)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to read artifact: %w", err)
+		return result
+	}
+	result.SHA256 = sum
+	result.Size = size
+	return result
+}
+
+// fatArch 待写入通用二进制的单个架构
+type fatArch struct {
+	header macho.FatArchHeader
+	path   string
+}
+
+// WriteFatMachO 将多个单架构 Mach-O 文件合并为通用二进制 (fat Mach-O)，效果等同 lipo -create。
+// 文件头与架构表为大端序，各架构的内容按页对齐 (arm64 为 16KB，其余为 4KB)。
+func WriteFatMachO(output string, inputs []string) error {
+	archs := make([]fatArch, 0, len(inputs))
+	seen := map[macho.Cpu]string{}
+	offset := uint32(8 + 20*len(inputs)) // fat_header + fat_arch 表
+	for _, input := range inputs {
+		file, err := macho.Open(input)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(input), err)
+		}
+		cpu, subCpu := file.Cpu, file.SubCpu
+		file.Close()
+		if other, ok := seen[cpu]; ok {
+			return fmt.Errorf("%s and %s are both built for %s", other, input, cpu)
+		}
+		seen[cpu] = input
+
+		info, err := os.Stat(input)
+		if err != nil {
+			return err
+		}
+		if info.Size() > int64(^uint32(0)) {
+			return fmt.Errorf("%s is too large for a fat Mach-O file", input)
+		}
+		align := uint32(12)
+		if cpu == macho.CpuArm64 {
+			align = 14
+		}
+		offset = alignUp(offset, 1<<align)
+		archs = append(archs, fatArch{
+			header: macho.FatArchHeader{Cpu: cpu, SubCpu: subCpu, Offset: offset, Size: uint32(info.Size()), Align: align},
+			path:   input,
+		})
+		offset += uint32(info.Size())
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeFatArchs(tmp, archs); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), output)
+}
+
+// writeFatArchs 写入 fat 文件头、架构表以及对齐后的各架构内容
+func writeFatArchs(w io.WriteSeeker, archs []fatArch) error {
+	if err := binary.Write(w, binary.BigEndian, [2]uint32{macho.MagicFat, uint32(len(archs))}); err != nil {
+		return err
+	}
+	for _, arch := range archs {
+		if err := binary.Write(w, binary.BigEndian, arch.header); err != nil {
+			return err
+		}
+	}
+	for _, arch := range archs {
+		// 对齐产生的空洞在文件中读为 0
+		if _, err := w.Seek(int64(arch.header.Offset), io.SeekStart); err != nil {
+			return err
+		}
+		f, err := os.Open(arch.path)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func alignUp(n, align uint32) uint32 {
+	return (n + align - 1) &^ (align - 1)
+}
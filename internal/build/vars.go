@@ -0,0 +1,193 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gocar/internal/util"
+)
+
+// VarsData [build.vars] 模板可用的数据
+type VarsData struct {
+	Name        string // 二进制名称
+	Version     string // 项目版本号 (project.version，"git" 时由 git describe 推导)
+	Commit      string // 完整提交哈希
+	ShortCommit string // 短提交哈希
+	Date        string // 构建日期 (RFC3339 UTC)：SOURCE_DATE_EPOCH 优先，其次为 HEAD 提交时间与最新源文件的修改时间
+	Dirty       bool   // 工作区是否有未提交的修改
+	Target      string // 目标平台 <os>/<arch>
+	OS          string // 目标操作系统
+	Arch        string // 目标架构
+	Variant     string // 目标架构子版本
+	Profile     string // 构建 profile
+}
+
+// varsData 收集模板数据，git 信息不可用时对应字段为空
+func (b *Builder) varsData() VarsData {
+	data := VarsData{
+		Name:    b.appName,
+		Target:  b.Target(),
+		OS:      b.config.TargetOS,
+		Arch:    b.config.TargetArch,
+		Variant: b.config.TargetVariant,
+		Profile: b.config.BuildMode(),
+	}
+	data.Version = b.version()
+	data.Commit = b.commit()
+	data.ShortCommit = shortCommit(data.Commit)
+	if data.Commit != "" {
+		data.Dirty = b.cached("dirty", func() string {
+			if status, err := util.GitOutput(b.projectRoot, "status", "--porcelain"); err == nil && status != "" {
+				return "true"
+			}
+			return ""
+		}) != ""
+	}
+	data.Date = b.cached("date", func() string {
+		return SourceDate(b.projectRoot).Format(time.RFC3339)
+	})
+
+	return data
+}
+
+// cached 返回按键缓存的值。版本号与 git 信息在同一 Builder 中只查询一次，
+// 计算产物路径、ldflags 与钩子环境变量时不再重复执行 git。
+func (b *Builder) cached(key string, compute func() string) string {
+	b.cacheMu.Lock()
+	defer b.cacheMu.Unlock()
+	if value, ok := b.cache[key]; ok {
+		return value
+	}
+	if b.cache == nil {
+		b.cache = map[string]string{}
+	}
+	value := compute()
+	b.cache[key] = value
+	return value
+}
+
+// version 返回项目版本号 (project.version，"git" 时由 git describe 推导)
+func (b *Builder) version() string {
+	if b.gocarConfig == nil {
+		return ""
+	}
+	return b.cached("version", func() string {
+		return b.gocarConfig.GetVersion(b.projectRoot)
+	})
+}
+
+// commit 返回 HEAD 的完整提交哈希，不在 git 仓库中时为空
+func (b *Builder) commit() string {
+	return b.cached("commit", func() string {
+		commit, err := util.GitOutput(b.projectRoot, "rev-parse", "HEAD")
+		if err != nil {
+			return ""
+		}
+		return commit
+	})
+}
+
+// shortCommit 返回 7 位短提交哈希
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// SourceDate 返回可复现的构建时间：SOURCE_DATE_EPOCH > HEAD 提交时间 > 最新源文件的修改时间 > 当前时间
+func SourceDate(projectRoot string) time.Time {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if sec, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			return time.Unix(sec, 0).UTC()
+		}
+	}
+	if ts, err := util.GitOutput(projectRoot, "log", "-1", "--format=%ct"); err == nil {
+		if sec, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			return time.Unix(sec, 0).UTC()
+		}
+	}
+	// 不在 git 仓库中时使用源文件的修改时间，源码不变时日期 (及构建指纹) 也不变
+	if modTime, ok := newestSourceModTime(projectRoot); ok {
+		return modTime
+	}
+	return time.Now().UTC()
+}
+
+// newestSourceModTime 返回项目中 .go 文件、go.mod、go.sum 与 .gocar.toml 的最新修改时间 (精确到秒)，跳过隐藏目录
+func newestSourceModTime(projectRoot string) (time.Time, bool) {
+	var newest time.Time
+	filepath.WalkDir(projectRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != projectRoot && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") && name != "go.mod" && name != "go.sum" && name != ".gocar.toml" {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	if newest.IsZero() {
+		return time.Time{}, false
+	}
+	return newest.Truncate(time.Second).UTC(), true
+}
+
+// renderVars 渲染 [build.vars]，返回按符号排序的 -X 参数
+func renderVars(vars map[string]string, data VarsData) (string, error) {
+	symbols := make([]string, 0, len(vars))
+	for symbol := range vars {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	flags := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		tmpl, err := template.New(symbol).Option("missingkey=error").Parse(vars[symbol])
+		if err != nil {
+			return "", fmt.Errorf("invalid [build.vars] template for %s: %w", symbol, err)
+		}
+		var value bytes.Buffer
+		if err := tmpl.Execute(&value, data); err != nil {
+			return "", fmt.Errorf("invalid [build.vars] template for %s: %w", symbol, err)
+		}
+		flag, err := quoteLdflag(symbol + "=" + value.String())
+		if err != nil {
+			return "", fmt.Errorf("invalid [build.vars] value for %s: %w", symbol, err)
+		}
+		flags = append(flags, "-X "+flag)
+	}
+	return strings.Join(flags, " "), nil
+}
+
+// quoteLdflag 为包含空白或引号的 -X 参数加引号，以便 go build 正确拆分 -ldflags。
+// go build 不支持转义，同时包含单引号和双引号的值无法表示。
+func quoteLdflag(value string) (string, error) {
+	switch {
+	case !strings.ContainsAny(value, " \t\n'\""):
+		return value, nil
+	case !strings.Contains(value, "'"):
+		return "'" + value + "'", nil
+	case !strings.Contains(value, `"`):
+		return `"` + value + `"`, nil
+	default:
+		return "", fmt.Errorf("value cannot contain both single and double quotes")
+	}
+}
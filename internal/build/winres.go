@@ -0,0 +1,123 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gocar/internal/config"
+	"gocar/internal/winres"
+)
+
+// resourceLocks 按 .syso 路径加锁，同一入口的多个 windows 构建 (如 amd64 与 amd64/v3) 依次生成和删除资源文件
+var resourceLocks sync.Map
+
+// WindowsResourcePath 返回构建时在入口包目录生成的 rsrc_windows_<arch>.syso 路径 (相对项目根目录)。
+// 未配置 [windows]、不是 windows 目标或产物不是可执行文件/DLL 时返回空字符串。
+func (b *Builder) WindowsResourcePath() string {
+	if b.gocarConfig == nil || !b.gocarConfig.Windows.Enabled() || b.config.TargetOS != "windows" {
+		return ""
+	}
+	if !b.Executable() && b.buildmode() != "c-shared" {
+		return ""
+	}
+	// 入口为单个文件时 go build 不会链接目录中的 .syso
+	dir := b.entry()
+	if stat, err := os.Stat(b.absPath(dir)); err != nil || !stat.IsDir() {
+		return ""
+	}
+	return filepath.Join(dir, winres.SysoName(b.config.TargetArch))
+}
+
+// writeWindowsResources 生成 Windows 资源文件，返回构建结束后删除它的清理函数。
+// 资源文件在计算指纹前生成，图标或版本信息变化时会重新构建。
+func (b *Builder) writeWindowsResources() (func(), error) {
+	path := b.WindowsResourcePath()
+	if path == "" {
+		return func() {}, nil
+	}
+	opts, err := b.windowsResources()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Windows resources: %w", err)
+	}
+	data, err := winres.Build(b.config.TargetArch, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Windows resources: %w", err)
+	}
+
+	abs := b.absPath(path)
+	lock, _ := resourceLocks.LoadOrStore(abs, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	// 不是 gocar 生成的同名文件 (如手动维护的资源文件) 不覆盖；
+	// 构建被中断时遗留的 gocar 生成的文件直接覆盖
+	if existing, err := os.ReadFile(abs); err == nil && !winres.IsGenerated(existing) {
+		mu.Unlock()
+		return nil, fmt.Errorf("%s already exists; remove it or the [windows] section from %s", path, config.ConfigFileName)
+	}
+	if err := os.WriteFile(abs, data, 0644); err != nil {
+		mu.Unlock()
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return func() {
+		_ = os.Remove(abs)
+		mu.Unlock()
+	}, nil
+}
+
+// windowsResources 根据 [windows] 配置组装图标、manifest 与版本信息
+func (b *Builder) windowsResources() (*winres.Options, error) {
+	windows := b.gocarConfig.Windows
+	opts := &winres.Options{}
+	if windows.Icon != "" {
+		opts.Icon = b.absPath(windows.Icon)
+	}
+	if windows.Manifest != "" {
+		manifest, err := os.ReadFile(b.absPath(windows.Manifest))
+		if err != nil {
+			return nil, err
+		}
+		opts.Manifest = manifest
+	} else if windows.HasManifest() {
+		opts.Manifest = winres.Manifest(winres.ManifestOptions{
+			ExecutionLevel: windows.ExecutionLevel,
+			DPIAwareness:   windows.DPIAwareness,
+			LongPathAware:  windows.LongPathAware,
+		})
+	}
+
+	// 文件版本未配置时使用项目版本号，产品版本未配置时与文件版本相同
+	fileVersion := windows.FileVersion
+	if fileVersion == "" {
+		fileVersion = b.version()
+	}
+	productVersion := windows.ProductVersion
+	if productVersion == "" {
+		productVersion = fileVersion
+	}
+	productName := windows.ProductName
+	if productName == "" {
+		productName = b.appName
+	}
+	description := windows.FileDescription
+	if description == "" {
+		description = productName
+	}
+	opts.Version = &winres.VersionInfo{
+		FileVersion:    winres.ParseVersion(fileVersion),
+		ProductVersion: winres.ParseVersion(productVersion),
+		DLL:            b.buildmode() == "c-shared",
+		Strings: map[string]string{
+			"CompanyName":      windows.CompanyName,
+			"FileDescription":  description,
+			"FileVersion":      fileVersion,
+			"InternalName":     b.appName,
+			"LegalCopyright":   windows.Copyright,
+			"OriginalFilename": filepath.Base(b.GetRelativeOutputPath()),
+			"ProductName":      productName,
+			"ProductVersion":   productVersion,
+		},
+	}
+	return opts, nil
+}
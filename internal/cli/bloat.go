@@ -0,0 +1,336 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"gocar/internal/bloat"
+	"gocar/internal/build"
+	"gocar/internal/config"
+	"gocar/internal/util"
+)
+
+// BloatCommand bloat 命令
+type BloatCommand struct{}
+
+// bloatOutputDir 体积分析构建的输出目录 (位于 [build].output 下)，与正常构建产物分开
+const bloatOutputDir = "gocar-bloat"
+
+// Run 执行 bloat 命令
+func (c *BloatCommand) Run(args []string) error {
+	opts := newBuildOptions()
+	top := 20
+	by := "package"
+	jsonOutput := false
+	diffAgainst := ""
+
+	for i := 0; i < len(args); {
+		next, ok, err := opts.parse(args, i)
+		if err != nil {
+			return err
+		}
+		if ok {
+			i = next
+			continue
+		}
+
+		arg := args[i]
+		switch arg {
+		case "help", "--help", "-h":
+			fmt.Print(c.Help())
+			return nil
+		case "--top", "-n":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s value %q: expected a non-negative integer", arg, args[i+1])
+			}
+			top = n
+			i++
+		case "--by":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--by requires a value")
+			}
+			by = args[i+1]
+			if by != "package" && by != "module" && by != "symbol" {
+				return fmt.Errorf("invalid --by %q (expected: package, module, symbol)", by)
+			}
+			i++
+		case "--json":
+			jsonOutput = true
+		case "--diff":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--diff requires a binary path or git ref")
+			}
+			diffAgainst = args[i+1]
+			i++
+		default:
+			return fmt.Errorf("unknown option '%s' (run 'gocar bloat --help' for usage)", arg)
+		}
+		i++
+	}
+
+	ctx, err := loadBuildContext()
+	if err != nil {
+		return err
+	}
+
+	// 保留符号表构建到单独目录，不覆盖正常的 (可能已裁剪的) 产物；
+	// 分析用的构建不执行 post_build 钩子 (包括 --diff 检出的旧版本中的钩子)，也不使用构建指纹
+	opts.config.KeepSymbols = true
+	opts.config.SkipHooks = true
+	opts.config.NoFingerprint = true
+	opts.config.OutputDir = filepath.Join(ctx.cfg.GetBuildOutputRoot(), bloatOutputDir)
+	builders, err := opts.builders(ctx)
+	if err != nil {
+		return err
+	}
+	if len(builders) != 1 {
+		return fmt.Errorf("gocar bloat analyzes one binary at a time; select it with --bin and --target")
+	}
+	builder := builders[0]
+
+	if !jsonOutput {
+		builder.PrintBuildInfo()
+	}
+	artifact, err := compileForBloat(builder)
+	if err != nil {
+		return err
+	}
+	report, err := bloat.Analyze(artifact)
+	if err != nil {
+		return err
+	}
+	report.Binary = builder.GetRelativeOutputPath()
+
+	if diffAgainst == "" {
+		entries := bloatEntries(report, by)
+		if top > 0 && len(entries) > top {
+			entries = entries[:top]
+		}
+		if jsonOutput {
+			return printJSON(struct {
+				*bloat.Report
+				By      string        `json:"by"`
+				Entries []bloat.Entry `json:"entries"`
+			}{report, by, entries})
+		}
+		printBloatReport(report, by, entries)
+		return nil
+	}
+
+	old, err := c.analyzeBaseline(ctx, opts, builder, diffAgainst, jsonOutput)
+	if err != nil {
+		return err
+	}
+	deltas := bloat.Diff(bloatEntries(old, by), bloatEntries(report, by))
+	if top > 0 && len(deltas) > top {
+		deltas = deltas[:top]
+	}
+	if jsonOutput {
+		return printJSON(struct {
+			Old    *bloat.Report `json:"old"`
+			New    *bloat.Report `json:"new"`
+			By     string        `json:"by"`
+			Deltas []bloat.Delta `json:"deltas"`
+		}{old, report, by, deltas})
+	}
+	printBloatDiff(old, report, by, deltas)
+	return nil
+}
+
+// compileForBloat 构建并返回产物的绝对路径，编译输出写入 stderr
+func compileForBloat(builder *build.Builder) (string, error) {
+	result := builder.Compile()
+	if len(result.Output) > 0 {
+		fmt.Fprint(os.Stderr, string(result.Output))
+	}
+	if result.Err != nil {
+		return "", result.Err
+	}
+	return builder.GetOutputPath(), nil
+}
+
+// analyzeBaseline 分析 --diff 指定的旧版本：已存在的文件直接读取，否则视为 git 引用，
+// 检出到临时 worktree 中以相同参数构建
+func (c *BloatCommand) analyzeBaseline(ctx *buildContext, opts *buildOptions, current *build.Builder, ref string, quiet bool) (*bloat.Report, error) {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		return bloat.Analyze(ref)
+	}
+	if _, err := util.GitOutput(ctx.projectRoot, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("--diff %q is neither a file nor a git ref", ref)
+	}
+
+	topLevel, err := util.GitOutput(ctx.projectRoot, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate git repository: %w", err)
+	}
+	projectRoot, err := filepath.EvalSymlinks(ctx.projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	subdir, err := filepath.Rel(topLevel, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "gocar-bloat-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+	worktree := filepath.Join(tempDir, "src")
+	if _, err := util.GitOutput(ctx.projectRoot, "worktree", "add", "--detach", worktree, ref); err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", ref, err)
+	}
+	defer util.GitOutput(ctx.projectRoot, "worktree", "remove", "--force", worktree)
+
+	root := filepath.Join(worktree, subdir)
+	cfg, err := config.Load(root)
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	bins, err := selectBins(cfg, root, opts.binName, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+	buildConfig := *opts.config
+	buildConfig.OutputDir = filepath.Join(cfg.GetBuildOutputRoot(), bloatOutputDir)
+	builders, err := newBuilders(root, ctx.appName, ctx.projectMode, &buildConfig, cfg, bins, []string{current.Target()})
+	if err != nil {
+		return nil, err
+	}
+	for _, builder := range builders {
+		if builder.Name() != current.Name() {
+			continue
+		}
+		if !quiet {
+			fmt.Printf("Building %s at %s for comparison\n", builder.Name(), ref)
+		}
+		artifact, err := compileForBloat(builder)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ref, err)
+		}
+		report, err := bloat.Analyze(artifact)
+		if err != nil {
+			return nil, err
+		}
+		report.Binary = ref
+		return report, nil
+	}
+	return nil, fmt.Errorf("binary %q not found at %s", current.Name(), ref)
+}
+
+// bloatEntries 按 --by 返回聚合结果
+func bloatEntries(report *bloat.Report, by string) []bloat.Entry {
+	switch by {
+	case "module":
+		return report.Modules
+	case "symbol":
+		return report.TopSymbols(0)
+	default:
+		return report.Packages
+	}
+}
+
+func printBloatReport(report *bloat.Report, by string, entries []bloat.Entry) {
+	fmt.Printf("\n%s: %s, %s attributed to %d symbols\n\n", report.Binary,
+		bloat.FormatSize(int64(report.FileSize)), bloat.FormatSize(int64(report.Total)), len(report.Symbols))
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "SIZE\tSHARE\tSYMBOLS\t  %s\n", bloatColumn(by))
+	for _, entry := range entries {
+		share := float64(entry.Size) * 100 / float64(report.Total)
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%d\t  %s\n", bloat.FormatSize(int64(entry.Size)), share, entry.Symbols, entry.Name)
+	}
+	tw.Flush()
+}
+
+func printBloatDiff(old, report *bloat.Report, by string, deltas []bloat.Delta) {
+	fmt.Printf("\n%s -> %s: %s -> %s (%s)\n\n", old.Binary, report.Binary,
+		bloat.FormatSize(int64(old.FileSize)), bloat.FormatSize(int64(report.FileSize)),
+		formatDelta(int64(report.FileSize)-int64(old.FileSize)))
+	if len(deltas) == 0 {
+		fmt.Printf("No %s changed size\n", by)
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "OLD\tNEW\tDELTA\t  %s\n", bloatColumn(by))
+	for _, d := range deltas {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t  %s\n", bloat.FormatSize(int64(d.Old)), bloat.FormatSize(int64(d.New)), formatDelta(d.Delta), d.Name)
+	}
+	tw.Flush()
+}
+
+// bloatColumn 返回名称列的表头
+func bloatColumn(by string) string {
+	switch by {
+	case "module":
+		return "MODULE"
+	case "symbol":
+		return "SYMBOL"
+	default:
+		return "PACKAGE"
+	}
+}
+
+func formatDelta(delta int64) string {
+	if delta > 0 {
+		return "+" + bloat.FormatSize(delta)
+	}
+	return bloat.FormatSize(delta)
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// Help 返回帮助信息
+func (c *BloatCommand) Help() string {
+	return `gocar bloat - Show what makes up the size of a binary
+
+USAGE:
+    gocar bloat [OPTIONS]
+
+OPTIONS:
+    --release                Analyze the release build
+    --profile <name>         Analyze the build of a profile
+    --target <os/arch>       Target platform (default: current platform)
+    --bin <name>             Binary to analyze (default: build.entry)
+    --buildmode <mode>       Build mode (see 'gocar build --help')
+    --by <kind>              Group by package (default), module or symbol
+    --top, -n <N>            Show the N largest entries (default: 20, 0 = all)
+    --diff <binary|git-ref>  Compare against an existing binary or a git ref
+    --json                   Print the report as JSON
+    --with-cgo               Enable CGO
+    --force                  Force rebuild even if the build fingerprint is unchanged
+    --help                   Show this help message
+
+DESCRIPTION:
+    Builds the binary with the selected profile, keeping the symbol table
+    (-s and -w are dropped from ldflags), and attributes the size of every
+    symbol to its Go package and module. Symbol sizes are read from the
+    ELF, Mach-O or PE symbol table; module paths come from the embedded
+    build info. The analysis build goes to <output>/gocar-bloat/ so normal
+    artifacts are left untouched.
+
+    --diff accepts a path to an older binary (built without -s) or any git
+    ref. A git ref is checked out into a temporary worktree and built with
+    the same options, then the entries that grew or shrank are listed.
+
+EXAMPLES:
+    gocar bloat --release
+    gocar bloat --release --by module --top 10
+    gocar bloat --release --diff v1.2.0
+    gocar bloat --target linux/arm64 --json
+`
+}
@@ -2,99 +2,630 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"gocar/internal/build"
 	"gocar/internal/config"
+	"gocar/internal/diag"
 	"gocar/internal/project"
 )
 
 // BuildCommand build 命令
 type BuildCommand struct{}
 
+// buildOptions build 类命令 (build、package 等) 共享的构建参数
+type buildOptions struct {
+	config  *build.Config
+	profile string
+	targets []string
+	// targetFlag 指定目标平台的参数 (--target、--targets 或 --all-common)，用于 --dry-run 标注来源
+	targetFlag string
+	binName    string
+	allBins    bool
+	jobs       int
+	// hostOnly 只构建当前平台，忽略 [build].targets (install、pgo collect)
+	hostOnly bool
+}
+
+func newBuildOptions() *buildOptions {
+	return &buildOptions{config: build.NewConfig()}
+}
+
+// parse 尝试解析 args[i] 处的共享构建参数。
+// 返回下一个待解析的位置，以及该参数是否被识别。
+func (o *buildOptions) parse(args []string, i int) (int, bool, error) {
+	arg := args[i]
+	value := func() (string, error) {
+		if i+1 >= len(args) {
+			return "", fmt.Errorf("%s requires a value", arg)
+		}
+		return args[i+1], nil
+	}
+
+	switch arg {
+	case "--release":
+		o.config.Release = true
+		o.config.Profile = "release"
+		o.config.ProfileOrigin = build.CLIOrigin(arg)
+	case "--with-cgo":
+		o.config.WithCGO = true
+	case "--force":
+		o.config.Force = true
+	case "--profile":
+		v, err := value()
+		if err != nil {
+			return i, true, err
+		}
+		o.profile = v
+		return i + 2, true, nil
+	case "--target":
+		v, err := value()
+		if err != nil {
+			return i, true, err
+		}
+		o.targets = append(o.targets, v)
+		o.targetFlag = arg
+		return i + 2, true, nil
+	case "--targets":
+		v, err := value()
+		if err != nil {
+			return i, true, err
+		}
+		o.targets = append(o.targets, splitTargetList(v)...)
+		o.targetFlag = arg
+		return i + 2, true, nil
+	case "--all-common":
+		o.targets = append(o.targets, build.CommonTargets...)
+		o.targetFlag = arg
+	case "--bin":
+		v, err := value()
+		if err != nil {
+			return i, true, err
+		}
+		o.binName = v
+		return i + 2, true, nil
+	case "--all-bins":
+		o.allBins = true
+	case "--buildmode":
+		v, err := value()
+		if err != nil {
+			return i, true, err
+		}
+		if !slices.Contains(build.Buildmodes, v) {
+			return i, true, fmt.Errorf("invalid --buildmode %q (expected one of: %s)", v, strings.Join(build.Buildmodes, ", "))
+		}
+		o.config.Buildmode = v
+		return i + 2, true, nil
+	case "-j", "--jobs":
+		v, err := value()
+		if err != nil {
+			return i, true, err
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return i, true, fmt.Errorf("invalid %s value %q: expected a positive integer", arg, v)
+		}
+		o.jobs = n
+		return i + 2, true, nil
+	default:
+		return i, false, nil
+	}
+	return i + 1, true, nil
+}
+
+// buildContext 检测项目并加载配置后的构建上下文
+type buildContext struct {
+	projectRoot string
+	appName     string
+	projectMode string
+	cfg         *config.GocarConfig
+}
+
+// loadBuildContext 检测项目、加载并校验配置
+func loadBuildContext() (*buildContext, error) {
+	// Get project info
+	projectRoot, appName, projectMode, err := project.DetectProject()
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	// Load config
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		cfg = config.DefaultConfig()
+	}
+
+	appName = cfg.GetProjectName(appName)
+
+	if err := cfg.Validate(projectRoot); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", config.ConfigFileName, err)
+	}
+
+	return &buildContext{projectRoot: projectRoot, appName: appName, projectMode: projectMode, cfg: cfg}, nil
+}
+
+// builders 根据构建参数为每个二进制与目标平台创建构建器
+func (o *buildOptions) builders(ctx *buildContext) ([]*build.Builder, error) {
+	if o.profile != "" {
+		if _, _, ok := ctx.cfg.GetProfileForBuild(o.profile, false); !ok {
+			return nil, fmt.Errorf("unknown profile %q (available: %v)", o.profile, ctx.cfg.ListProfiles())
+		}
+		o.config.Profile = o.profile
+		o.config.Release = o.profile == "release"
+		o.config.ProfileOrigin = build.CLIOrigin("--profile")
+	}
+
+	bins, err := selectBins(ctx.cfg, ctx.projectRoot, o.binName, o.allBins)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未指定目标时使用配置文件中的 [build].targets
+	targets := o.targets
+	if len(targets) > 0 {
+		o.config.TargetOrigin = build.CLIOrigin(o.targetFlag)
+	} else if len(ctx.cfg.Build.Targets) > 0 && !o.hostOnly {
+		targets = ctx.cfg.Build.Targets
+		o.config.TargetOrigin = "[build].targets"
+	}
+
+	builders, err := newBuilders(ctx.projectRoot, ctx.appName, ctx.projectMode, o.config, ctx.cfg, bins, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	// 构建模式与入口包不匹配时在构建前报错
+	for _, builder := range builders {
+		if err := builder.Validate(); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", builder.Name(), builder.Target(), err)
+		}
+	}
+	return builders, nil
+}
+
 // Run 执行 build 命令
 func (c *BuildCommand) Run(args []string) error {
-	buildConfig := build.NewConfig()
-	target := ""
-	profile := ""
+	opts := newBuildOptions()
+	messageFormat := build.MessageFormatHuman
+	explain := explainOff
+	universal := ""
 
 	// Parse arguments
-	for i := 0; i < len(args); i++ {
+	for i := 0; i < len(args); {
+		next, ok, err := opts.parse(args, i)
+		if err != nil {
+			return err
+		}
+		if ok {
+			i = next
+			continue
+		}
+
 		arg := args[i]
 		switch arg {
 		case "help", "--help", "-h":
 			fmt.Print(c.Help())
 			return nil
-		case "--release":
-			buildConfig.Release = true
-			buildConfig.Profile = "release"
-		case "--with-cgo":
-			buildConfig.WithCGO = true
-		case "--profile":
-			if i+1 < len(args) {
-				profile = args[i+1]
-				i++ // skip next arg
-			} else {
-				return fmt.Errorf("--profile requires a value")
+		case "--message-format":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--message-format requires a value")
+			}
+			messageFormat = args[i+1]
+			if err := validateMessageFormat(messageFormat); err != nil {
+				return err
 			}
-		case "--target":
-			if i+1 < len(args) {
-				target = args[i+1]
-				i++ // skip next arg
-			} else {
-				return fmt.Errorf("--target requires a value")
+			i++ // skip next arg
+		case "--timings":
+			opts.config.Timings = true
+		case "--universal":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--universal requires a value")
 			}
+			universal = args[i+1]
+			i++ // skip next arg
+		case "--dry-run", "--explain":
+			explain, _ = parseExplainFlag(arg)
 		default:
 			return fmt.Errorf("unknown option '%s' (run 'gocar build --help' for usage)", arg)
 		}
+		i++
 	}
 
-	// Get project info
-	projectRoot, appName, projectMode, err := project.DetectProject()
+	// --universal 构建各架构后合并为一个通用二进制
+	if universal != "" {
+		if len(opts.targets) > 0 {
+			return fmt.Errorf("--universal cannot be combined with %s", opts.targetFlag)
+		}
+		targets, err := build.UniversalTargets(universal)
+		if err != nil {
+			return err
+		}
+		opts.targets = targets
+		opts.targetFlag = "--universal"
+	}
+
+	ctx, err := loadBuildContext()
 	if err != nil {
-		return fmt.Errorf("%w", err)
+		return err
 	}
 
-	// Load config
-	cfg, err := config.Load(projectRoot)
+	builders, err := opts.builders(ctx)
 	if err != nil {
-		fmt.Printf("Warning: %v\n", err)
-		cfg = config.DefaultConfig()
+		return err
 	}
+	jobs := opts.jobs
 
-	appName = cfg.GetProjectName(appName)
+	if explain != explainOff {
+		// --explain 与 json/sarif 同时使用时执行计划写入 stderr，stdout 保持为纯 JSON
+		planOutput := io.Writer(os.Stdout)
+		if explain == explainRun && messageFormat != build.MessageFormatHuman {
+			planOutput = os.Stderr
+		}
+		if err := printBuildPlans(planOutput, builders); err != nil {
+			return err
+		}
+		if universal != "" {
+			for _, group := range universalGroups(builders) {
+				fmt.Fprintf(planOutput, "Merge %s into %s\n\n", group[0].Name(), group[0].UniversalOutputPath())
+			}
+		}
+		if explain == explainDryRun {
+			return nil
+		}
+	}
 
-	if err := cfg.Validate(projectRoot); err != nil {
-		return fmt.Errorf("invalid %s: %w", config.ConfigFileName, err)
+	// JSON/SARIF 模式下钩子输出写入 stderr，stdout 保持为纯 JSON
+	hookOutput := io.Writer(os.Stdout)
+	if messageFormat != build.MessageFormatHuman {
+		hookOutput = os.Stderr
+	}
+	hookEnv := buildHookEnv(builders)
+	if err := ctx.runHook(config.HookPreBuild, hookEnv, hookOutput); err != nil {
+		return ctx.withFailureHook("build", hookEnv, hookOutput, err)
+	}
+
+	var results []*build.Result
+	switch messageFormat {
+	case build.MessageFormatJSON:
+		// JSON 模式在 build-finished 事件之前合并通用二进制并输出其 artifact 事件
+		results, err = runBuildJSON(builders, jobs, universal != "")
+	case build.MessageFormatSARIF:
+		results, err = runBuildSARIF(ctx.projectRoot, builders, jobs)
+	default:
+		results, err = runBuilds(builders, jobs)
 	}
-	if profile != "" {
-		if _, _, ok := cfg.GetProfileForBuild(profile, false); !ok {
-			return fmt.Errorf("unknown profile %q (available: %v)", profile, cfg.ListProfiles())
+	if opts.config.Timings {
+		writeTimingsReport(hookOutput, ctx.projectRoot, results)
+	}
+	if err == nil && universal != "" && messageFormat != build.MessageFormatJSON {
+		err = mergeUniversal(hookOutput, builders, results, nil)
+	}
+	return ctx.withFailureHook("build", hookEnv, hookOutput, err)
+}
+
+// writeTimingsReport 写入所有产物汇总的 target/timings.html 与 target/timings.json
+func writeTimingsReport(w io.Writer, projectRoot string, results []*build.Result) {
+	report, err := build.WriteTimingsReport(projectRoot, results)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: failed to write build timings report: %v\n", err)
+		return
+	}
+	if report != "" {
+		fmt.Fprintf(w, "Timings report: %s\n", report)
+	}
+}
+
+// universalGroups 按二进制名称对构建器分组，每组包含同一二进制的各个架构
+func universalGroups(builders []*build.Builder) [][]*build.Builder {
+	groups := [][]*build.Builder{}
+	index := map[string]int{}
+	for _, builder := range builders {
+		i, ok := index[builder.Name()]
+		if !ok {
+			i = len(groups)
+			index[builder.Name()] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], builder)
+	}
+	return groups
+}
+
+// mergeUniversal 将每个二进制的各架构产物合并为通用二进制，并记录到 manifest.json。
+// events 不为空时 (--message-format json) 合并结果以 artifact 事件输出，否则输出到 w。
+func mergeUniversal(w io.Writer, builders []*build.Builder, results []*build.Result, events *build.EventWriter) error {
+	byBuilder := map[*build.Builder]*build.Result{}
+	for i, builder := range builders {
+		byBuilder[builder] = results[i]
+	}
+
+	merged := []*build.Result{}
+	for _, group := range universalGroups(builders) {
+		groupResults := make([]*build.Result, len(group))
+		for i, builder := range group {
+			groupResults[i] = byBuilder[builder]
+		}
+		result := build.MergeUniversal(group, groupResults)
+		if result.Err != nil {
+			return fmt.Errorf("%s: %w", result.Name, result.Err)
+		}
+		if events != nil {
+			events.EmitResult(result)
+		} else if result.Fresh {
+			fmt.Fprintf(w, "Fresh universal binary: %s\n", result.Artifact)
+		} else {
+			fmt.Fprintf(w, "Universal binary: %s\n", result.Artifact)
 		}
-		buildConfig.Profile = profile
-		buildConfig.Release = profile == "release"
+		merged = append(merged, result)
 	}
 
-	// Parse target if specified
-	if target != "" {
-		targetOS, targetArch, err := build.ParseTarget(target)
+	if err := build.UpdateManifest(builders[0].OutputRoot(), merged); err != nil {
+		fmt.Fprintf(w, "Warning: failed to write %s: %v\n", build.ManifestFileName, err)
+	}
+	return nil
+}
+
+// printBuildPlans 输出每个构建的执行计划 (--dry-run / --explain)
+func printBuildPlans(w io.Writer, builders []*build.Builder) error {
+	for _, builder := range builders {
+		plan, err := builder.Explain()
 		if err != nil {
-			return fmt.Errorf("%v; expected format: <os>/<arch> (example: linux/amd64)", err)
+			return fmt.Errorf("%s %s: %w", builder.Name(), builder.Target(), err)
 		}
-		buildConfig.SetTarget(targetOS, targetArch)
+		printPlan(w, plan)
 	}
+	return nil
+}
 
-	// Create builder
-	builder := build.NewBuilder(projectRoot, appName, projectMode, buildConfig, cfg)
+// runBuilds 执行构建：单个构建直接输出，多个构建并行执行并输出汇总表。
+// 构建结束后更新输出目录中的 manifest.json。
+func runBuilds(builders []*build.Builder, jobs int) ([]*build.Result, error) {
+	var results []*build.Result
+	var err error
+	if len(builders) == 1 {
+		builder := builders[0]
 
-	// Print build info
-	builder.PrintBuildInfo()
+		// Print build info
+		builder.PrintBuildInfo()
 
-	// Execute build
-	if err := builder.Build(); err != nil {
-		return err
+		// Execute build
+		result, buildErr := builder.Build()
+		if buildErr != nil {
+			return []*build.Result{result}, buildErr
+		}
+		results = []*build.Result{result}
+	} else {
+		results, err = runBuildMatrix(builders, jobs)
 	}
 
-	return nil
+	if manifestErr := build.UpdateManifest(builders[0].OutputRoot(), results); manifestErr != nil {
+		fmt.Printf("Warning: failed to write %s: %v\n", build.ManifestFileName, manifestErr)
+	}
+	return results, err
+}
+
+// runBuildJSON 以 --message-format json 构建，输出换行分隔的 JSON 事件。
+// universal 为 true 时 (--universal) 在 build-finished 事件之前合并通用二进制。
+func runBuildJSON(builders []*build.Builder, jobs int, universal bool) ([]*build.Result, error) {
+	start := time.Now()
+	events := build.NewEventWriter(os.Stdout)
+	for _, builder := range builders {
+		events.EmitStarted(builder)
+	}
+
+	// 钩子输出不是 JSON 事件，写到 stderr
+	results := build.BuildMatrix(builders, jobs, func(result *build.Result) {
+		events.EmitResult(result)
+		build.PrintHookOutput(os.Stderr, result.HookOutput, "")
+	})
+
+	var err error
+	if failed := build.CountFailed(results); failed > 0 {
+		err = fmt.Errorf("%d of %d targets failed", failed, len(results))
+	}
+	if manifestErr := build.UpdateManifest(builders[0].OutputRoot(), results); manifestErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write %s: %v\n", build.ManifestFileName, manifestErr)
+	}
+	if err == nil && universal {
+		err = mergeUniversal(os.Stderr, builders, results, events)
+	}
+	events.EmitFinished(err, time.Since(start))
+
+	// 错误信息由 main 输出到 stderr，stdout 保持为纯 JSON
+	return results, err
+}
+
+// runBuildSARIF 以 --message-format sarif 构建，所有目标的诊断去重后合并为一份 SARIF 日志
+func runBuildSARIF(projectRoot string, builders []*build.Builder, jobs int) ([]*build.Result, error) {
+	results := build.BuildMatrix(builders, jobs, func(result *build.Result) {
+		build.PrintHookOutput(os.Stderr, result.HookOutput, "")
+	})
+
+	var err error
+	if failed := build.CountFailed(results); failed > 0 {
+		err = fmt.Errorf("%d of %d targets failed", failed, len(results))
+	}
+	if manifestErr := build.UpdateManifest(builders[0].OutputRoot(), results); manifestErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write %s: %v\n", build.ManifestFileName, manifestErr)
+	}
+
+	var diagnostics []diag.Diagnostic
+	for _, result := range results {
+		diagnostics = append(diagnostics, result.Diagnostics...)
+	}
+	if sarifErr := diag.WriteSARIF(os.Stdout, projectRoot, Version, diag.Dedupe(diagnostics)); sarifErr != nil {
+		return results, sarifErr
+	}
+	return results, err
+}
+
+// selectBins 根据 --bin / --all-bins 选择要构建的二进制。
+// 返回空列表表示构建默认应用 (build.entry)。
+func selectBins(cfg *config.GocarConfig, projectRoot, binName string, allBins bool) ([]config.BinConfig, error) {
+	if binName != "" {
+		bin, ok := cfg.GetBin(projectRoot, binName)
+		if !ok {
+			return nil, fmt.Errorf("unknown binary %q (available: %v)", binName, cfg.ListBins(projectRoot))
+		}
+		return []config.BinConfig{bin}, nil
+	}
+	if allBins {
+		bins := cfg.GetBins(projectRoot)
+		if len(bins) == 0 {
+			return nil, fmt.Errorf("no binaries found: declare [[bin]] in %s or add cmd/<name>/main.go", config.ConfigFileName)
+		}
+		return bins, nil
+	}
+	// 显式声明 [[bin]] 时默认构建全部声明的二进制
+	if len(cfg.Bins) > 0 {
+		return cfg.GetBins(projectRoot), nil
+	}
+	return nil, nil
+}
+
+// newBuilders 为每个二进制与目标平台的组合创建构建器
+func newBuilders(projectRoot, appName, projectMode string, buildConfig *build.Config, cfg *config.GocarConfig, bins []config.BinConfig, targets []string) ([]*build.Builder, error) {
+	targets = uniqueTargets(targets)
+
+	configs := []*build.Config{buildConfig}
+	if len(targets) > 0 {
+		// 工具链不可用时跳过校验，由 go build 报告错误
+		platforms, err := build.LoadPlatforms(projectRoot)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping target validation: %v\n", err)
+		}
+		// --with-cgo、profile 中的 cgo_enabled = true 与需要 cgo 的构建模式都会启用 cgo
+		profile, _, ok := cfg.GetProfileForBuild(buildConfig.Profile, buildConfig.Release)
+		buildmode := buildConfig.Buildmode
+		if buildmode == "" && ok {
+			buildmode = profile.Buildmode
+		}
+		cgoForced := buildConfig.WithCGO || build.RequiresCgo(buildmode)
+		if ok && config.Enabled(profile.CgoEnabled) {
+			cgoForced = true
+		}
+
+		configs = make([]*build.Config, 0, len(targets))
+		for _, target := range targets {
+			targetOS, targetArch, variant, err := build.ParseTarget(target)
+			if err != nil {
+				return nil, err
+			}
+			if platforms != nil {
+				if err := build.ValidateTarget(platforms, targetOS, targetArch); err != nil {
+					return nil, err
+				}
+				if platform, _ := build.FindPlatform(platforms, targetOS, targetArch); cgoForced && !platform.CgoSupported {
+					fmt.Fprintf(os.Stderr, "Warning: CGO is enabled but %s does not support cgo\n", target)
+				}
+			}
+			targetConfig := *buildConfig
+			targetConfig.SetTarget(targetOS, targetArch)
+			targetConfig.TargetVariant = variant
+			configs = append(configs, &targetConfig)
+		}
+	}
+
+	builders := []*build.Builder{}
+	for _, targetConfig := range configs {
+		if len(bins) == 0 {
+			builders = append(builders, build.NewBuilder(projectRoot, appName, projectMode, targetConfig, cfg))
+			continue
+		}
+		for _, bin := range bins {
+			builder := build.NewBuilder(projectRoot, appName, projectMode, targetConfig, cfg)
+			builder.SetBin(bin)
+			builders = append(builders, builder)
+		}
+	}
+
+	// 自定义的产物模板缺少区分目标或二进制的占位符时，多个产物会写到同一路径
+	seen := map[string]*build.Builder{}
+	for _, builder := range builders {
+		path := builder.GetRelativeOutputPath()
+		if other, ok := seen[path]; ok {
+			return nil, fmt.Errorf("%s (%s) and %s (%s) would both be written to %s; add {name}, {os}, {arch} or {variant} to [build].artifact_name or artifact_dir",
+				other.Name(), other.Target(), builder.Name(), builder.Target(), path)
+		}
+		seen[path] = builder
+	}
+	return builders, nil
+}
+
+// runBuildMatrix 并行构建多个目标并输出汇总表
+func runBuildMatrix(builders []*build.Builder, jobs int) ([]*build.Result, error) {
+	if jobs <= 0 {
+		jobs = build.DefaultJobs()
+	}
+	fmt.Printf("Building %d artifacts (jobs: %d)...\n", len(builders), min(jobs, len(builders)))
+
+	results := build.BuildMatrix(builders, jobs, func(result *build.Result) {
+		if result.Fresh {
+			fmt.Printf("  Fresh %s %s\n", result.Name, result.Target)
+		} else if result.OK() {
+			fmt.Printf("  Finished %s %s in %s\n", result.Name, result.Target, result.Duration.Round(time.Millisecond))
+			if result.Timings != "" {
+				fmt.Printf("    Timings: %s\n", result.Timings)
+			}
+		} else {
+			fmt.Printf("  Failed %s %s: %v\n", result.Name, result.Target, result.Err)
+		}
+		if len(result.Output) > 0 {
+			var rendered strings.Builder
+			diag.RenderOutput(&rendered, builders[0].ProjectRoot(), diag.ToolBuild, result.Output)
+			fmt.Print(indentOutput(rendered.String(), "    "))
+		}
+		build.PrintHookOutput(os.Stdout, result.HookOutput, "    ")
+	})
+
+	fmt.Println()
+	build.PrintSummary(os.Stdout, results)
+
+	if failed := build.CountFailed(results); failed > 0 {
+		return results, fmt.Errorf("%d of %d targets failed", failed, len(results))
+	}
+	return results, nil
+}
+
+// splitTargetList 解析逗号分隔的目标列表
+func splitTargetList(value string) []string {
+	targets := []string{}
+	for _, target := range strings.Split(value, ",") {
+		target = strings.TrimSpace(target)
+		if target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// uniqueTargets 去除重复目标，保持原有顺序
+func uniqueTargets(targets []string) []string {
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		unique = append(unique, target)
+	}
+	return unique
+}
+
+// indentOutput 为多行输出添加缩进
+func indentOutput(output, prefix string) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n") + "\n"
 }
 
 // Help 返回帮助信息
@@ -107,8 +638,26 @@ USAGE:
 OPTIONS:
     --release              Build in release mode (optimized binary)
     --profile <name>       Build with a named profile from .gocar.toml
-    --target <os>/<arch>   Cross-compile for target platform
+    --bin <name>           Build only the named binary ([[bin]] or cmd/<name>)
+    --all-bins             Build every binary ([[bin]] or discovered cmd/*/main.go)
+    --target <os>/<arch>[/<variant>]
+                           Cross-compile for target platform
+    --targets <list>       Build several comma-separated targets in parallel
+    --all-common           Build all common targets (see below) in parallel
+    -j, --jobs <n>         Maximum number of parallel target builds (default: CPU count)
     --with-cgo             Force enable CGO (sets CGO_ENABLED=1)
+    --buildmode <mode>     Build mode: exe, pie, c-shared, c-archive, plugin
+    --force                Rebuild even if the build fingerprint is unchanged
+    --message-format <fmt> Output format: human (default), json (NDJSON events)
+                           or sarif (SARIF 2.1.0 log of compiler diagnostics)
+    --timings              Write a per-package build timings report to
+                           target/timings.html and target/timings.json
+    --universal darwin     Build darwin/amd64 and darwin/arm64 and merge them into
+                           one universal binary (bin/<profile>/darwin-universal)
+    --dry-run              Print the resolved go build command, working directory
+                           and environment, with the source of every setting,
+                           without building or running hooks
+    --explain              Like --dry-run, then build as usual
     --help                 Show this help message
 
 EXAMPLES:
@@ -117,8 +666,19 @@ EXAMPLES:
     gocar build --profile ci                     Build with [profile.ci]
     gocar build --target linux/amd64             Cross-compile for Linux AMD64
     gocar build --release --target linux/arm64   Cross-compile for Linux ARM (release)
+    gocar build --target linux/arm/v7            Cross-compile for ARMv7 (GOARM=7)
+    gocar build --release --all-common -j 4      Build all common targets, 4 at a time
+    gocar build --targets linux/amd64,darwin/arm64
+                                                 Build a target matrix in parallel
+    gocar build --release --universal darwin     Build a macOS universal binary
+    gocar build --bin worker                     Build only cmd/worker
+    gocar build --all-bins --release             Build every binary in release mode
     gocar build --with-cgo                       Build with CGO enabled
+    gocar build --buildmode c-shared             Build lib<name>.so and its C header
     gocar build --release --with-cgo             Build in release mode with CGO enabled
+    gocar build --release --timings              Report compile/link times per package
+    gocar build --release --dry-run              Show the go build command and where
+                                                 each flag and env var comes from
 
 COMMON TARGETS:
     linux/amd64     Linux AMD 64-bit
@@ -126,5 +686,55 @@ COMMON TARGETS:
     darwin/amd64    macOS Intel
     darwin/arm64    macOS Apple Silicon
     windows/amd64   Windows 64-bit
+
+NOTES:
+    Without --target/--targets/--all-common, [build].targets in .gocar.toml
+    is used when set. Multiple targets end with a per-target summary table.
+    Targets are checked against 'go tool dist list' before building; run
+    'gocar targets' to list them.
+
+    --universal darwin merges the darwin/amd64 and darwin/arm64 artifacts of
+    each binary into a fat Mach-O file, like 'lipo -create' but without
+    needing Xcode, so it also works on Linux. The per-architecture artifacts
+    are kept; the merge is skipped when both were fresh.
+
+    An optional third component selects an architecture variant and writes to
+    its own directory (e.g. bin/debug/linux-arm-v7):
+      arm/v5|v6|v7 (GOARM)        amd64/v1..v4 (GOAMD64)
+      arm64/v8.0..v9.5 (GOARM64)  386/sse2|softfloat (GO386)
+      mips[le]/hardfloat|softfloat (GOMIPS), mips64[le]/... (GOMIPS64)
+      ppc64[le]/power8|power9|power10 (GOPPC64)
+      riscv64/rva20u64|rva22u64|rva23u64 (GORISCV64)
+
+    Builds are skipped ("Fresh") when the sources of the entry's dependency
+    closure, go.mod/go.sum, profile flags, environment and target are unchanged
+    since the last build. The fingerprint is stored next to the artifact.
+
+    Every build updates <output>/manifest.json with each artifact's path,
+    sha256, size, target, profile, resolved flags, Go version and duration.
+    --message-format json prints build-started, compiler-diagnostic, artifact
+    and build-finished events, one JSON object per line. --message-format
+    sarif prints the compiler diagnostics of all targets as one SARIF 2.1.0
+    log for code review bots and IDEs. In human mode compiler errors are
+    shown with the offending source line and a caret under the column.
+
+    --buildmode (or buildmode in the profile) names the artifact per target:
+    c-shared -> lib<name>.so / lib<name>.dylib / <name>.dll, c-archive ->
+    lib<name>.a, plugin -> <name>.so. c-shared and c-archive also write the
+    C header (lib<name>.h) next to the library. These modes enable CGO and
+    require a main package; c-shared/c-archive also need an //export function.
+
+    --timings always runs go build (Go's build cache still applies) with
+    -debug-actiongraph and writes target/timings.html (a self-contained Gantt
+    chart) and target/timings.json covering every artifact of the build, with
+    per-package compile and link durations, cache hits/misses and the critical
+    path. Each artifact also gets its own report in
+    <output>/gocar-timings/<name>-<profile>-<target>.html/.json.
+
+    [hooks].pre_build runs once before building and aborts the build when it
+    fails. [hooks].post_build runs after every successful build of each
+    artifact, including fresh ones, with GOCAR_ARTIFACT set; its output is
+    printed as-is under "post_build hook output:". on_failure runs when the
+    build fails.
 `
 }
@@ -2,9 +2,13 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"slices"
 
+	"gocar/internal/build"
+	"gocar/internal/diag"
 	"gocar/internal/project"
-	"gocar/internal/util"
 )
 
 // CheckCommand check 命令
@@ -15,6 +19,11 @@ func (c *CheckCommand) Run(args []string) error {
 	runTests := true
 	race := false
 
+	messageFormat, args, err := extractMessageFormat(args)
+	if err != nil {
+		return err
+	}
+	explain, args := extractExplainMode(args)
 	for _, arg := range args {
 		switch arg {
 		case "help", "--help", "-h":
@@ -34,13 +43,14 @@ func (c *CheckCommand) Run(args []string) error {
 		return fmt.Errorf("%w", err)
 	}
 
-	fmt.Printf("Checking '%s'...\n", appName)
+	// json/sarif 模式下 stdout 只输出诊断，所有步骤的诊断合并输出
+	human := messageFormat == build.MessageFormatHuman
 
 	steps := []struct {
 		name string
 		args []string
 	}{
-		{name: "vet", args: []string{"vet", "./..."}},
+		{name: diag.ToolVet, args: []string{"vet", "./..."}},
 	}
 
 	if runTests {
@@ -51,17 +61,70 @@ func (c *CheckCommand) Run(args []string) error {
 		steps = append(steps, struct {
 			name string
 			args []string
-		}{name: "test", args: testArgs})
+		}{name: diag.ToolTest, args: testArgs})
+	}
+
+	if explain != explainOff {
+		planOutput := io.Writer(os.Stdout)
+		if explain == explainRun && !human {
+			planOutput = os.Stderr
+		}
+		for _, step := range steps {
+			settings := []build.Setting{{Name: "packages", Value: "./...", Source: build.OriginDefault}}
+			if slices.Contains(step.args, "-race") {
+				settings = append(settings, build.Setting{Name: "-race", Source: build.CLIOrigin("--race")})
+			}
+			printPlan(planOutput, &build.Plan{
+				Title:    "check " + appName + ": go " + step.name,
+				Args:     append([]string{"go"}, step.args...),
+				Dir:      projectRoot,
+				Settings: settings,
+			})
+		}
+		if explain == explainDryRun {
+			return nil
+		}
 	}
 
+	if human {
+		fmt.Printf("Checking '%s'...\n", appName)
+	}
+
+	var all []diag.Diagnostic
+	var stepErr error
 	for _, step := range steps {
-		fmt.Printf("Running go %s...\n", step.name)
-		if err := util.RunCommand(projectRoot, "go", step.args...); err != nil {
-			return fmt.Errorf("go %s failed: %w", step.name, err)
+		if human {
+			fmt.Printf("Running go %s...\n", step.name)
+		}
+		output, diagnostics, err := runGoDiagnosed(projectRoot, step.name, step.args)
+		all = append(all, diagnostics...)
+		if human {
+			if step.name == diag.ToolTest {
+				fmt.Print(string(output))
+				if err != nil {
+					printTestFailures(os.Stdout, projectRoot, diagnostics)
+				}
+			} else {
+				diag.Render(os.Stdout, projectRoot, diagnostics)
+			}
+		}
+		if err != nil {
+			stepErr = fmt.Errorf("go %s failed: %w", step.name, err)
+			break
 		}
 	}
 
-	fmt.Println("Check passed")
+	if !human {
+		if err := writeDiagnostics(os.Stdout, messageFormat, projectRoot, all); err != nil {
+			return err
+		}
+	}
+	if stepErr != nil {
+		return stepErr
+	}
+	if human {
+		fmt.Println("Check passed")
+	}
 	return nil
 }
 
@@ -75,11 +138,18 @@ USAGE:
 OPTIONS:
     --no-test      Skip go test ./...
     --race         Run tests with the race detector
+    --message-format <fmt>
+                   Output format: human (default), json (one
+                   compiler-diagnostic event per line) or sarif; vet and
+                   test diagnostics are combined into one report
+    --dry-run      Print the go vet and go test commands without running them
+    --explain      Like --dry-run, then run the checks as usual
     --help         Show this help message
 
 EXAMPLES:
     gocar check            Run go vet and go test
     gocar check --race     Run checks and race-enabled tests
     gocar check --no-test  Run only go vet
+    gocar check --message-format sarif > check.sarif
 `
 }
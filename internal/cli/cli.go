@@ -3,7 +3,9 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"os"
 
+	"gocar/internal/build"
 	"gocar/internal/config"
 	"gocar/internal/project"
 )
@@ -51,8 +53,17 @@ func NewApp() *App {
 	app.commands["tidy"] = &TidyCommand{}
 	app.commands["test"] = &TestCommand{}
 	app.commands["check"] = &CheckCommand{}
+	app.commands["package"] = &PackageCommand{}
+	app.commands["image"] = &ImageCommand{}
+	app.commands["targets"] = &TargetsCommand{}
+	app.commands["bloat"] = &BloatCommand{}
+	app.commands["pgo"] = &PgoCommand{}
+	app.commands["cov"] = &CovCommand{}
+	app.commands["install"] = &InstallCommand{}
+	app.commands["uninstall"] = &UninstallCommand{}
 	app.commands["commands"] = &CommandsCommand{}
 	app.commands["doctor"] = &DoctorCommand{}
+	app.commands["completion"] = &CompletionCommand{commands: app.commands}
 	app.commands["init"] = &InitCommand{}
 
 	return app
@@ -80,6 +91,9 @@ func (a *App) Run(args []string) error {
 	case "version", "-v", "--version":
 		fmt.Printf("gocar %s\n", Version)
 		return nil
+	case "__complete":
+		// 补全脚本的回调，不出现在帮助中，也不能被自定义命令覆盖
+		return runComplete(args[2:])
 	}
 
 	// 执行命令
@@ -130,10 +144,37 @@ func (a *App) tryRunCustomCommand(cmdName string, args []string) error {
 	}
 
 	// 检查是否有这个自定义命令
-	if _, ok := cfg.GetCommand(cmdName); !ok {
+	script, ok := cfg.GetCommand(cmdName)
+	if !ok {
 		return ErrCommandNotFound
 	}
 
+	// 命令名之后紧跟的 --dry-run / --explain 由 gocar 处理，其余参数原样传给自定义命令
+	explain := explainOff
+	for len(args) > 0 {
+		mode, ok := parseExplainFlag(args[0])
+		if !ok {
+			break
+		}
+		explain = mode
+		args = args[1:]
+	}
+	if explain != explainOff {
+		settings := []build.Setting{{Name: "command", Value: script, Source: "[commands]." + cmdName}}
+		if len(args) > 0 {
+			settings = append(settings, build.Setting{Name: "args", Value: shellJoin(args), Source: "CLI args"})
+		}
+		printPlan(os.Stdout, &build.Plan{
+			Title:    "custom command " + cmdName,
+			Args:     cfg.CustomCommandArgs(cmdName, args),
+			Dir:      projectRoot,
+			Settings: settings,
+		})
+		if explain == explainDryRun {
+			return nil
+		}
+	}
+
 	// 执行自定义命令
 	fmt.Printf("Running custom command: %s\n\n", cmdName)
 	if err := cfg.RunCustomCommand(projectRoot, cmdName, args); err != nil {
@@ -155,6 +196,7 @@ COMMANDS:
 CUSTOM COMMANDS:
     Define custom commands in .gocar.toml [commands] section.
     Custom commands can override built-in commands (except: new, init).
+    gocar <name> --dry-run prints the shell command without running it.
     Example: gocar lint, gocar doc
 
 EXAMPLES:
@@ -1,11 +1,20 @@
 package cli
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gocar/internal/config"
+	"gocar/internal/image"
+)
 
 func TestNewAppRegistersCoreCommands(t *testing.T) {
 	app := NewApp()
 
-	for _, name := range []string{"new", "init", "build", "run", "clean", "fmt", "vet", "add", "update", "tidy", "test", "check", "commands", "doctor"} {
+	for _, name := range []string{"new", "init", "build", "run", "clean", "fmt", "vet", "add", "update", "tidy", "test", "check", "package", "image", "targets", "bloat", "pgo", "cov", "install", "uninstall", "commands", "doctor", "completion"} {
 		if app.commands[name] == nil {
 			t.Fatalf("command %q was not registered", name)
 		}
@@ -20,3 +29,77 @@ func TestProtectedCommands(t *testing.T) {
 		t.Fatal("project commands should be overrideable")
 	}
 }
+
+func TestRunParseArgs(t *testing.T) {
+	cmd := &RunCommand{}
+
+	binName, explain, rest, err := cmd.parseArgs([]string{"--bin", "worker", "--dry-run", "--port", "8080"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binName != "worker" || explain != explainDryRun || len(rest) != 2 || rest[0] != "--port" {
+		t.Fatalf("parseArgs() = %q, %v, %q", binName, explain, rest)
+	}
+
+	// -- 之后的参数原样传给应用，包括 gocar 自身的参数名
+	binName, explain, rest, err = cmd.parseArgs([]string{"--", "--dry-run", "--bin", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binName != "" || explain != explainOff || len(rest) != 3 || rest[0] != "--dry-run" {
+		t.Fatalf("parseArgs(-- --dry-run) = %q, %v, %q; want --dry-run forwarded", binName, explain, rest)
+	}
+}
+
+// writeTestProject 创建只有一个入口 cmd/app 的标准项目
+func writeTestProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		"go.mod":                               "module example.com/app\n\ngo 1.21\n",
+		filepath.Join("cmd", "app", "main.go"): "package main\n\nfunc main() {}\n",
+	} {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestHostOnlyBuildersIgnoreConfiguredTargets(t *testing.T) {
+	root := writeTestProject(t)
+	cfg := config.DefaultConfig()
+	cfg.Build.Targets = []string{"linux/amd64", "linux/arm64"}
+	ctx := &buildContext{projectRoot: root, appName: "app", projectMode: "standard", cfg: cfg}
+
+	opts := newBuildOptions()
+	opts.hostOnly = true
+	builders, err := opts.builders(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builders) != 1 || builders[0].Target() != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Fatalf("host-only builders = %d, want one builder for the host", len(builders))
+	}
+
+	if builders, err := newBuildOptions().builders(ctx); err != nil || len(builders) != 2 {
+		t.Fatalf("builders() = %d, %v, want [build].targets", len(builders), err)
+	}
+}
+
+func TestImageRejectsMultiPlatformDockerBeforeBuilding(t *testing.T) {
+	root := writeTestProject(t)
+	t.Chdir(root)
+
+	err := (&ImageCommand{}).Run([]string{"--format", "docker", "--targets", "linux/amd64,linux/arm64"})
+	if !errors.Is(err, image.ErrDockerMultiPlatform) {
+		t.Fatalf("Run() error = %v, want %v", err, image.ErrDockerMultiPlatform)
+	}
+	if _, err := os.Stat(filepath.Join(root, "bin")); !os.IsNotExist(err) {
+		t.Fatal("targets were built before the docker format was rejected")
+	}
+}
@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gocar/internal/build"
+	"gocar/internal/config"
+	"gocar/internal/project"
+)
+
+// CompletionCommand completion 命令
+type CompletionCommand struct {
+	commands map[string]Command
+}
+
+// completionShells 支持的 shell 与对应的脚本生成函数
+var completionShells = map[string]func([]completionSpec) string{
+	"bash":       bashCompletion,
+	"zsh":        zshCompletion,
+	"fish":       fishCompletion,
+	"powershell": powershellCompletion,
+}
+
+// Run 执行 completion 命令
+func (c *CompletionCommand) Run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing shell (expected: bash, zsh, fish or powershell)")
+	}
+	switch args[0] {
+	case "help", "--help", "-h":
+		fmt.Print(c.Help())
+		return nil
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected argument '%s' (run 'gocar completion --help' for usage)", args[1])
+	}
+	generate, ok := completionShells[args[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q (expected: bash, zsh, fish or powershell)", args[0])
+	}
+	fmt.Print(generate(completionSpecs(c.commands)))
+	return nil
+}
+
+// completionWord 可补全的命令、子命令或选项取值
+type completionWord struct {
+	Name        string
+	Description string
+}
+
+// completionFlag 从命令帮助中解析出的选项
+type completionFlag struct {
+	Names       []string // 如 ["-j", "--jobs"]
+	Value       string   // 取值占位符，如 <name>；开关选项为空
+	Description string
+}
+
+// completionSpec 一个内置命令的补全信息
+type completionSpec struct {
+	completionWord
+	Subcommands []completionWord
+	Flags       []completionFlag
+}
+
+// completionSources 取值由 gocar __complete 动态提供的选项
+var completionSources = map[string]string{
+	"--profile": "profiles",
+	"--bin":     "bins",
+	"--target":  "targets",
+	"--targets": "targets",
+}
+
+// completionChoices 取值固定的选项，键为选项名或 "命令 选项名" (同名选项在不同命令中取值不同时)
+var completionChoices = map[string][]string{
+	"--message-format": {"human", "json", "sarif"},
+	"--buildmode":      {"exe", "pie", "c-shared", "c-archive", "plugin"},
+	"package --format": {"deb", "rpm", "apk"},
+	"image --format":   {"oci", "docker"},
+	"bloat --by":       {"package", "module", "symbol"},
+}
+
+// completionLists 取值为逗号分隔列表的选项
+var completionLists = map[string]bool{
+	"--targets":        true,
+	"package --format": true,
+}
+
+// completionValue 选项取值的补全方式
+type completionValue struct {
+	Source string   // gocar __complete 的类型，为空时使用 Words
+	Words  []string // 固定的取值
+	List   bool     // 逗号分隔的列表，如 --targets
+}
+
+// value 返回命令中选项取值的补全方式，第二个返回值表示选项是否需要取值；没有候选项时为零值 (补全文件名)
+func (f completionFlag) value(command string) (completionValue, bool) {
+	if f.Value == "" {
+		return completionValue{}, false
+	}
+	long := f.Names[len(f.Names)-1]
+	value := completionValue{List: completionLists[long] || completionLists[command+" "+long]}
+	if source, ok := completionSources[long]; ok {
+		value.Source = source
+	} else if words, ok := completionChoices[command+" "+long]; ok {
+		value.Words = words
+	} else if words, ok := completionChoices[long]; ok {
+		value.Words = words
+	} else if !strings.HasPrefix(f.Value, "<") {
+		// 如 --universal darwin，占位符本身就是唯一的取值
+		value.Words = []string{f.Value}
+	}
+	return value, true
+}
+
+// empty 检查是否没有候选项 (补全文件名)
+func (v completionValue) empty() bool {
+	return v.Source == "" && len(v.Words) == 0
+}
+
+// helpOptionPattern 帮助中的选项行: "    -j, --jobs <n>   说明"、"    --top, -n <N>   说明"
+var helpOptionPattern = regexp.MustCompile(`^ {4}((?:-{1,2}[A-Za-z][\w-]*(?:, )?)+)(?: ([^ ]+))?(?: +(.*))?$`)
+
+// helpWordPattern 帮助中子命令或 shell 的说明行: "    merge     说明"
+var helpWordPattern = regexp.MustCompile(`^ {4}([a-z][\w-]*)(?: {2,}(.*))?$`)
+
+// completionSpecs 根据内置命令表与各命令帮助中的 OPTIONS、SUBCOMMANDS 段生成补全信息
+func completionSpecs(commands map[string]Command) []completionSpec {
+	specs := make([]completionSpec, 0, len(builtInCommands))
+	for _, info := range builtInCommands {
+		spec := completionSpec{completionWord: completionWord{Name: info.Name, Description: info.Description}}
+		if cmd, ok := commands[info.Name]; ok {
+			spec.Subcommands, spec.Flags = parseHelpCompletions(cmd.Help())
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// parseHelpCompletions 解析帮助文本，说明换行时取下一行作为说明
+func parseHelpCompletions(help string) ([]completionWord, []completionFlag) {
+	var words []completionWord
+	var flags []completionFlag
+	section := ""
+	lines := strings.Split(help, "\n")
+	for i, line := range lines {
+		if line != "" && !strings.HasPrefix(line, " ") {
+			section = strings.TrimSpace(line)
+			continue
+		}
+		description := func(s string) string {
+			if s == "" && i+1 < len(lines) {
+				next := strings.TrimSpace(lines[i+1])
+				if !strings.HasPrefix(next, "-") {
+					s = next
+				}
+			}
+			return strings.TrimSpace(s)
+		}
+		switch section {
+		case "OPTIONS:":
+			m := helpOptionPattern.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			names := strings.Split(m[1], ", ")
+			// 短选项在前，长选项在后
+			sort.Slice(names, func(a, b int) bool { return len(names[a]) < len(names[b]) })
+			flags = append(flags, completionFlag{Names: names, Value: m[2], Description: description(m[3])})
+		case "SUBCOMMANDS:", "SHELLS:":
+			if m := helpWordPattern.FindStringSubmatch(line); m != nil {
+				words = append(words, completionWord{Name: m[1], Description: description(m[2])})
+			}
+		}
+	}
+	return words, flags
+}
+
+// runComplete 执行隐藏的 gocar __complete <kind>，按行输出当前项目中的候选项，供补全脚本调用。
+// 不在项目中或配置无法加载时不输出任何内容。
+func runComplete(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: gocar __complete commands|profiles|targets|bins")
+	}
+	if args[0] == "targets" {
+		platforms, err := build.LoadPlatforms("")
+		if err != nil {
+			return nil
+		}
+		for _, p := range platforms {
+			fmt.Println(p)
+		}
+		return nil
+	}
+
+	projectRoot, _, _, err := project.DetectProject()
+	if err != nil {
+		return nil
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil
+	}
+	var items []string
+	switch args[0] {
+	case "commands":
+		// 与内置命令同名的自定义命令已经在内置命令中
+		for name := range cfg.Commands {
+			if !isBuiltInCommandName(name) {
+				items = append(items, name)
+			}
+		}
+		sort.Strings(items)
+	case "profiles":
+		items = cfg.ListProfiles()
+	case "bins":
+		items = cfg.ListBins(projectRoot)
+	default:
+		return fmt.Errorf("unknown completion kind %q", args[0])
+	}
+	for _, item := range items {
+		fmt.Println(item)
+	}
+	return nil
+}
+
+// Help 返回帮助信息
+func (c *CompletionCommand) Help() string {
+	return `gocar completion - Generate shell completion scripts
+
+USAGE:
+    gocar completion <shell>
+
+SHELLS:
+    bash          source <(gocar completion bash), e.g. in ~/.bashrc
+    zsh           source <(gocar completion zsh), e.g. in ~/.zshrc, or save
+                  the output as _gocar in a directory on $fpath
+    fish          gocar completion fish > ~/.config/fish/completions/gocar.fish
+    powershell    gocar completion powershell | Out-String | Invoke-Expression,
+                  e.g. in $PROFILE
+
+DESCRIPTION:
+    Prints a completion script for commands, subcommands and options. Values of
+    --profile, --bin, --target and --targets, and the custom commands from
+    [commands], are completed from the .gocar.toml of the current project
+    when you press tab, so the script does not need to be regenerated when
+    the configuration changes.
+
+EXAMPLES:
+    gocar completion bash > /etc/bash_completion.d/gocar
+    gocar completion zsh > "${fpath[1]}/_gocar"
+`
+}
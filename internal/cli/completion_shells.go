@@ -0,0 +1,409 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// valueCase 需要补全取值的选项，按补全方式分组: 补全方式 -> "命令 选项" 列表
+type valueCase struct {
+	value completionValue
+	keys  []string
+}
+
+// valueCases 收集所有需要取值的选项，补全方式相同的选项合并为一组，保持首次出现的顺序
+func valueCases(specs []completionSpec) []valueCase {
+	var cases []valueCase
+	index := map[string]int{}
+	for _, spec := range specs {
+		for _, flag := range spec.Flags {
+			value, ok := flag.value(spec.Name)
+			if !ok {
+				continue
+			}
+			id := fmt.Sprintf("%s|%t|%s", value.Source, value.List, strings.Join(value.Words, " "))
+			i, seen := index[id]
+			if !seen {
+				i = len(cases)
+				index[id] = i
+				cases = append(cases, valueCase{value: value})
+			}
+			for _, name := range flag.Names {
+				cases[i].keys = append(cases[i].keys, spec.Name+" "+name)
+			}
+		}
+	}
+	return cases
+}
+
+// flagNames 返回命令的所有选项名
+func (s completionSpec) flagNames() []string {
+	var names []string
+	for _, flag := range s.Flags {
+		names = append(names, flag.Names...)
+	}
+	return names
+}
+
+func wordNames(words []completionWord) []string {
+	names := make([]string, 0, len(words))
+	for _, word := range words {
+		names = append(names, word.Name)
+	}
+	return names
+}
+
+func commandNames(specs []completionSpec) []string {
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	return names
+}
+
+func bashCompletion(specs []completionSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `# bash completion for gocar
+# Generated by: gocar completion bash
+
+_gocar_complete() {
+    gocar __complete "$1" 2>/dev/null
+}
+
+_gocar() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}" cmd="${COMP_WORDS[1]}" words=""
+    if [[ $COMP_CWORD -eq 1 ]]; then
+        COMPREPLY=($(compgen -W "%s $(_gocar_complete commands)" -- "$cur"))
+        return
+    fi
+
+    case "$cmd $prev" in
+`, strings.Join(commandNames(specs), " "))
+	for _, c := range valueCases(specs) {
+		fmt.Fprintf(&b, "        \"%s\")\n", strings.Join(c.keys, "\"|\""))
+		candidates := strings.Join(c.value.Words, " ")
+		if c.value.Source != "" {
+			candidates = "$(_gocar_complete " + c.value.Source + ")"
+		}
+		switch {
+		case c.value.empty():
+		case c.value.List:
+			b.WriteString("            local prefix=\"\"\n")
+			b.WriteString("            [[ $cur == *,* ]] && prefix=\"${cur%,*},\"\n")
+			fmt.Fprintf(&b, "            COMPREPLY=($(compgen -P \"$prefix\" -W \"%s\" -- \"${cur##*,}\"))\n", candidates)
+		default:
+			fmt.Fprintf(&b, "            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", candidates)
+		}
+		b.WriteString("            return ;;\n")
+	}
+	b.WriteString("    esac\n\n    case \"$cmd\" in\n")
+	for _, spec := range specs {
+		if len(spec.Flags) == 0 && len(spec.Subcommands) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "        %s)\n", spec.Name)
+		if len(spec.Subcommands) > 0 {
+			fmt.Fprintf(&b, "            if [[ $COMP_CWORD -eq 2 && $cur != -* ]]; then\n")
+			fmt.Fprintf(&b, "                COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(wordNames(spec.Subcommands), " "))
+			fmt.Fprintf(&b, "                return\n            fi\n")
+		}
+		fmt.Fprintf(&b, "            words=\"%s\" ;;\n", strings.Join(spec.flagNames(), " "))
+	}
+	b.WriteString(`    esac
+
+    if [[ $cur == -* ]]; then
+        COMPREPLY=($(compgen -W "$words" -- "$cur"))
+    fi
+}
+
+complete -o default -F _gocar gocar
+`)
+	return b.String()
+}
+
+// zshQuote 返回单引号包围的字符串
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshItem 返回 _describe 使用的 "名称:说明"
+func zshItem(name, description string) string {
+	return zshQuote(strings.ReplaceAll(name, ":", `\:`) + ":" + description)
+}
+
+func zshCompletion(specs []completionSpec) string {
+	var b strings.Builder
+	b.WriteString(`#compdef gocar
+# zsh completion for gocar
+# Generated by: gocar completion zsh
+
+_gocar_complete() {
+    gocar __complete "$1" 2>/dev/null
+}
+
+_gocar() {
+    local cmd=${words[2]} prev=${words[CURRENT-1]} cur=${words[CURRENT]}
+    local -a items
+    if (( CURRENT == 2 )); then
+        items=(
+`)
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "            %s\n", zshItem(spec.Name, spec.Description))
+	}
+	b.WriteString(`        )
+        items+=(${(f)"$(_gocar_complete commands)"})
+        _describe -t commands 'gocar command' items
+        return
+    fi
+
+    case "$cmd $prev" in
+`)
+	for _, c := range valueCases(specs) {
+		fmt.Fprintf(&b, "        \"%s\")\n", strings.Join(c.keys, "\"|\""))
+		candidates := strings.Join(c.value.Words, " ")
+		if c.value.Source != "" {
+			candidates = "${(f)\"$(_gocar_complete " + c.value.Source + ")\"}"
+		}
+		switch {
+		case c.value.empty():
+			b.WriteString("            _files\n")
+		case c.value.List:
+			b.WriteString("            compset -P '*,'\n")
+			fmt.Fprintf(&b, "            compadd -q -S , -- %s\n", candidates)
+		default:
+			fmt.Fprintf(&b, "            compadd -- %s\n", candidates)
+		}
+		b.WriteString("            return ;;\n")
+	}
+	b.WriteString("    esac\n\n    case $cmd in\n")
+	for _, spec := range specs {
+		if len(spec.Flags) == 0 && len(spec.Subcommands) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "        %s)\n", spec.Name)
+		if len(spec.Subcommands) > 0 {
+			b.WriteString("            if (( CURRENT == 3 )) && [[ $cur != -* ]]; then\n                items=(\n")
+			for _, sub := range spec.Subcommands {
+				fmt.Fprintf(&b, "                    %s\n", zshItem(sub.Name, sub.Description))
+			}
+			b.WriteString("                )\n                _describe -t commands 'subcommand' items\n                return\n            fi\n")
+		}
+		b.WriteString("            items=(\n")
+		for _, flag := range spec.Flags {
+			for _, name := range flag.Names {
+				fmt.Fprintf(&b, "                %s\n", zshItem(name, flag.Description))
+			}
+		}
+		b.WriteString("            ) ;;\n")
+	}
+	b.WriteString(`    esac
+
+    if [[ $cur == -* ]] && (( ${#items} )); then
+        _describe -t options 'option' items
+    else
+        _files
+    fi
+}
+
+if [[ $funcstack[1] == _gocar ]]; then
+    _gocar "$@"
+else
+    compdef _gocar gocar
+fi
+`)
+	return b.String()
+}
+
+// fishQuote 返回单引号包围的字符串
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func fishCompletion(specs []completionSpec) string {
+	var b strings.Builder
+	b.WriteString(`# fish completion for gocar
+# Generated by: gocar completion fish
+
+function __gocar_using_command
+    set -l tokens (commandline -opc)
+    test (count $tokens) -ge 2; and test "$tokens[2]" = $argv[1]
+end
+
+function __gocar_needs_subcommand
+    set -l tokens (commandline -opc)
+    test (count $tokens) -eq 2; and test "$tokens[2]" = $argv[1]
+end
+
+function __gocar_complete_list
+    set -l prefix (string match -r '.*,' -- (commandline -ct)); or set prefix ''
+    for item in $argv
+        echo $prefix$item
+    end
+end
+
+complete -c gocar -f -n __fish_use_subcommand -a '(gocar __complete commands 2>/dev/null)' -d 'Custom command'
+`)
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "complete -c gocar -f -n __fish_use_subcommand -a %s -d %s\n", spec.Name, fishQuote(spec.Description))
+	}
+	for _, spec := range specs {
+		if len(spec.Flags) == 0 && len(spec.Subcommands) == 0 {
+			continue
+		}
+		b.WriteString("\n")
+		for _, sub := range spec.Subcommands {
+			fmt.Fprintf(&b, "complete -c gocar -f -n '__gocar_needs_subcommand %s' -a %s -d %s\n", spec.Name, sub.Name, fishQuote(sub.Description))
+		}
+		for _, flag := range spec.Flags {
+			fmt.Fprintf(&b, "complete -c gocar -n '__gocar_using_command %s'", spec.Name)
+			for _, name := range flag.Names {
+				if strings.HasPrefix(name, "--") {
+					fmt.Fprintf(&b, " -l %s", strings.TrimPrefix(name, "--"))
+				} else {
+					fmt.Fprintf(&b, " -s %s", strings.TrimPrefix(name, "-"))
+				}
+			}
+			if value, ok := flag.value(spec.Name); ok {
+				candidates := strings.Join(value.Words, " ")
+				if value.Source != "" {
+					candidates = "(gocar __complete " + value.Source + " 2>/dev/null)"
+				}
+				switch {
+				case value.empty():
+					b.WriteString(" -r")
+				case value.List:
+					fmt.Fprintf(&b, " -x -a %s", fishQuote("(__gocar_complete_list "+candidates+")"))
+				default:
+					fmt.Fprintf(&b, " -x -a %s", fishQuote(candidates))
+				}
+			}
+			fmt.Fprintf(&b, " -d %s\n", fishQuote(flag.Description))
+		}
+	}
+	return b.String()
+}
+
+// powershellQuote 返回单引号包围的字符串
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// powershellTable 写入 [ordered]@{ 名称 = 说明 }，说明为空时使用名称 (CompletionResult 要求非空的提示)
+func powershellTable(b *strings.Builder, indent string, words []completionWord) {
+	b.WriteString("[ordered]@{\n")
+	for _, word := range words {
+		description := word.Description
+		if description == "" {
+			description = word.Name
+		}
+		fmt.Fprintf(b, "%s    %s = %s\n", indent, powershellQuote(word.Name), powershellQuote(description))
+	}
+	b.WriteString(indent + "}")
+}
+
+func powershellCompletion(specs []completionSpec) string {
+	var b strings.Builder
+	b.WriteString(`# powershell completion for gocar
+# Generated by: gocar completion powershell
+
+Register-ArgumentCompleter -Native -CommandName gocar -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $commands = `)
+	commands := make([]completionWord, 0, len(specs))
+	for _, spec := range specs {
+		commands = append(commands, spec.completionWord)
+	}
+	powershellTable(&b, "    ", commands)
+	b.WriteString("\n    $subcommands = @{\n")
+	for _, spec := range specs {
+		if len(spec.Subcommands) > 0 {
+			fmt.Fprintf(&b, "        %s = ", powershellQuote(spec.Name))
+			powershellTable(&b, "        ", spec.Subcommands)
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("    }\n    $options = @{\n")
+	for _, spec := range specs {
+		if len(spec.Flags) == 0 {
+			continue
+		}
+		var flags []completionWord
+		for _, flag := range spec.Flags {
+			for _, name := range flag.Names {
+				flags = append(flags, completionWord{Name: name, Description: flag.Description})
+			}
+		}
+		fmt.Fprintf(&b, "        %s = ", powershellQuote(spec.Name))
+		powershellTable(&b, "        ", flags)
+		b.WriteString("\n")
+	}
+	b.WriteString(`    }
+    # '@<kind>' values come from gocar __complete, a trailing ',' marks a comma-separated list,
+    # other values are fixed words and an empty value falls back to file names
+    $values = @{
+`)
+	for _, c := range valueCases(specs) {
+		value := strings.Join(c.value.Words, " ")
+		if c.value.Source != "" {
+			value = "@" + c.value.Source
+		}
+		if c.value.List {
+			value += ","
+		}
+		for _, key := range c.keys {
+			fmt.Fprintf(&b, "        %s = %s\n", powershellQuote(key), powershellQuote(value))
+		}
+	}
+	b.WriteString(`    }
+
+    $elements = @($commandAst.CommandElements | Where-Object { $_.Extent.StartOffset -lt $cursorPosition } | ForEach-Object { $_.Extent.Text })
+    if ($wordToComplete -ne '') {
+        $elements = @($elements | Select-Object -First ($elements.Count - 1))
+    }
+    $candidates = [ordered]@{}
+    if ($elements.Count -le 1) {
+        $candidates = $commands
+        foreach ($name in @(gocar __complete commands 2>$null)) {
+            $candidates[$name] = 'Custom command'
+        }
+    } else {
+        $cmd = $elements[1]
+        $key = "$cmd $($elements[-1])"
+        if ($values.ContainsKey($key)) {
+            $value = $values[$key]
+            $list = $value.EndsWith(',')
+            $value = $value.TrimEnd(',')
+            if ($value -eq '') {
+                return
+            }
+            if ($value.StartsWith('@')) {
+                $names = @(gocar __complete $value.TrimStart('@') 2>$null)
+            } else {
+                $names = $value -split ' '
+            }
+            $prefix = ''
+            if ($list -and $wordToComplete.Contains(',')) {
+                $prefix = $wordToComplete.Substring(0, $wordToComplete.LastIndexOf(',') + 1)
+            }
+            foreach ($name in $names) {
+                $candidates[$prefix + $name] = $name
+            }
+        } elseif ($elements.Count -eq 2 -and $subcommands.ContainsKey($cmd) -and -not $wordToComplete.StartsWith('-')) {
+            $candidates = $subcommands[$cmd]
+        } elseif ($wordToComplete.StartsWith('-') -and $options.ContainsKey($cmd)) {
+            $candidates = $options[$cmd]
+        } else {
+            return
+        }
+    }
+
+    foreach ($name in $candidates.Keys) {
+        if ($name.StartsWith($wordToComplete)) {
+            [System.Management.Automation.CompletionResult]::new($name, $name, 'ParameterValue', $candidates[$name])
+        }
+    }
+}
+`)
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompletionSpecsParseHelp(t *testing.T) {
+	app := NewApp()
+	specs := map[string]completionSpec{}
+	for _, spec := range completionSpecs(app.commands) {
+		specs[spec.Name] = spec
+	}
+	if len(specs) != len(builtInCommands) {
+		t.Fatalf("got %d specs, want one per built-in command (%d)", len(specs), len(builtInCommands))
+	}
+
+	flags := map[string]completionFlag{}
+	for _, flag := range specs["build"].Flags {
+		flags[flag.Names[len(flag.Names)-1]] = flag
+	}
+	if got := flags["--jobs"].Names; !reflect.DeepEqual(got, []string{"-j", "--jobs"}) {
+		t.Errorf("build --jobs names = %v, want [-j --jobs]", got)
+	}
+	if value, ok := flags["--profile"].value("build"); !ok || value.Source != "profiles" {
+		t.Errorf("build --profile value = %+v, %v, want profiles source", value, ok)
+	}
+	if value, ok := flags["--targets"].value("build"); !ok || value.Source != "targets" || !value.List {
+		t.Errorf("build --targets value = %+v, %v, want a targets list", value, ok)
+	}
+	if _, ok := flags["--release"].value("build"); ok {
+		t.Error("build --release should not take a value")
+	}
+	if flags["--message-format"].Description == "" {
+		t.Error("build --message-format is missing its description")
+	}
+
+	var subcommands []string
+	for _, word := range specs["cov"].Subcommands {
+		subcommands = append(subcommands, word.Name)
+	}
+	if want := []string{"merge", "report", "html", "textfmt"}; !reflect.DeepEqual(subcommands, want) {
+		t.Errorf("cov subcommands = %v, want %v", subcommands, want)
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	cmd := &CompletionCommand{commands: NewApp().commands}
+	specs := completionSpecs(cmd.commands)
+	for shell, want := range map[string]string{
+		"bash":       "complete -o default -F _gocar gocar",
+		"zsh":        "#compdef gocar",
+		"fish":       "gocar __complete profiles",
+		"powershell": "Register-ArgumentCompleter -Native -CommandName gocar",
+	} {
+		if script := completionShells[shell](specs); !strings.Contains(script, want) {
+			t.Errorf("%s script does not contain %q", shell, want)
+		}
+	}
+	if err := cmd.Run([]string{"tcsh"}); err == nil {
+		t.Error("Run(tcsh) expected an error")
+	}
+}
@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"gocar/internal/coverage"
+)
+
+// CovCommand cov 命令
+type CovCommand struct{}
+
+// covOptions cov 子命令的通用参数
+type covOptions struct {
+	inputs     []string // GOCOVERDIR 目录或文本 profile 文件
+	output     string
+	jsonOutput bool
+}
+
+// Run 执行 cov 命令
+func (c *CovCommand) Run(args []string) error {
+	if len(args) == 0 {
+		fmt.Print(c.Help())
+		return nil
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "help", "--help", "-h":
+		fmt.Print(c.Help())
+		return nil
+	case "merge", "report", "html", "textfmt":
+	default:
+		return fmt.Errorf("unknown cov subcommand '%s' (run 'gocar cov --help' for usage)", subcommand)
+	}
+
+	opts := covOptions{}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "help", "--help", "-h":
+			fmt.Print(c.Help())
+			return nil
+		case "-o", "--output":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			opts.output = args[i+1]
+			i++
+		case "-i", "--input":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			opts.inputs = append(opts.inputs, strings.Split(args[i+1], ",")...)
+			i++
+		case "--json":
+			if subcommand != "report" {
+				return fmt.Errorf("--json is only supported by 'gocar cov report'")
+			}
+			opts.jsonOutput = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return fmt.Errorf("unknown option '%s' (run 'gocar cov --help' for usage)", arg)
+			}
+			opts.inputs = append(opts.inputs, arg)
+		}
+	}
+	if len(opts.inputs) == 0 {
+		return fmt.Errorf("no coverage data given: pass one or more GOCOVERDIR directories or profile files")
+	}
+	for _, input := range opts.inputs {
+		if _, err := os.Stat(input); err != nil {
+			return fmt.Errorf("coverage input %s does not exist", input)
+		}
+	}
+
+	switch subcommand {
+	case "merge":
+		return c.merge(opts)
+	case "report":
+		return c.report(opts)
+	case "html":
+		return c.html(opts)
+	default:
+		return c.textfmt(opts)
+	}
+}
+
+// merge 使用 go tool covdata merge 将多个 GOCOVERDIR 合并为一个
+func (c *CovCommand) merge(opts covOptions) error {
+	if opts.output == "" {
+		return fmt.Errorf("gocar cov merge requires -o <dir>")
+	}
+	for _, input := range opts.inputs {
+		if info, err := os.Stat(input); err == nil && !info.IsDir() {
+			return fmt.Errorf("%s is a text profile; merge only accepts GOCOVERDIR directories (use 'gocar cov textfmt' to combine text profiles)", input)
+		}
+	}
+	if err := os.MkdirAll(opts.output, 0755); err != nil {
+		return err
+	}
+	if err := runCovdata("merge", opts.inputs, "-o="+opts.output); err != nil {
+		return err
+	}
+	fmt.Printf("Merged %d coverage director%s into %s\n", len(opts.inputs), plural(len(opts.inputs), "y", "ies"), opts.output)
+	return nil
+}
+
+// report 输出每个包的语句覆盖率
+func (c *CovCommand) report(opts covOptions) error {
+	profile, err := loadCoverage(opts.inputs)
+	if err != nil {
+		return err
+	}
+	packages := profile.Packages()
+	total := coverage.Total(packages)
+	if opts.jsonOutput {
+		return printJSON(struct {
+			Mode     string             `json:"mode"`
+			Packages []coverage.Package `json:"packages"`
+			Total    coverage.Package   `json:"total"`
+		}{profile.Mode, packages, total})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "PACKAGE\tSTATEMENTS\tCOVERED\tCOVERAGE\t")
+	for _, pkg := range append(packages, total) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\t\n", pkg.Package, pkg.Statements, pkg.Covered, pkg.Percent)
+	}
+	return w.Flush()
+}
+
+// html 生成 HTML 覆盖率报告 (go tool cover -html)
+func (c *CovCommand) html(opts covOptions) error {
+	if opts.output == "" {
+		opts.output = "coverage.html"
+	}
+	profile, err := loadCoverage(opts.inputs)
+	if err != nil {
+		return err
+	}
+	textProfile, err := writeTempProfile(profile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(textProfile)
+
+	cmd := exec.Command("go", "tool", "cover", "-html="+textProfile, "-o", opts.output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go tool cover failed: %w", err)
+	}
+	fmt.Printf("Wrote %s\n", opts.output)
+	return nil
+}
+
+// textfmt 将覆盖率数据合并为文本 profile (与 go test -coverprofile 格式相同)
+func (c *CovCommand) textfmt(opts covOptions) error {
+	profile, err := loadCoverage(opts.inputs)
+	if err != nil {
+		return err
+	}
+	if opts.output == "" {
+		return profile.Write(os.Stdout)
+	}
+	file, err := os.Create(opts.output)
+	if err != nil {
+		return err
+	}
+	if err := profile.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", opts.output)
+	return nil
+}
+
+// loadCoverage 读取并合并覆盖率数据：GOCOVERDIR 目录经 go tool covdata textfmt 转换，
+// 文件视为文本 profile (如 go test -coverprofile 的输出)
+func loadCoverage(inputs []string) (*coverage.Profile, error) {
+	var dirs []string
+	var profiles []*coverage.Profile
+	for _, input := range inputs {
+		info, err := os.Stat(input)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, input)
+			continue
+		}
+		profile, err := readProfile(input)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	if len(dirs) > 0 {
+		tempFile, err := os.CreateTemp("", "gocar-cov-*.out")
+		if err != nil {
+			return nil, err
+		}
+		tempFile.Close()
+		defer os.Remove(tempFile.Name())
+		if err := runCovdata("textfmt", dirs, "-o="+tempFile.Name()); err != nil {
+			return nil, err
+		}
+		profile, err := readProfile(tempFile.Name())
+		if err != nil {
+			return nil, fmt.Errorf("no coverage data in %s", strings.Join(dirs, ", "))
+		}
+		profiles = append(profiles, profile)
+	}
+	return coverage.Merge(profiles...)
+}
+
+func readProfile(path string) (*coverage.Profile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	profile, err := coverage.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return profile, nil
+}
+
+func writeTempProfile(profile *coverage.Profile) (string, error) {
+	file, err := os.CreateTemp("", "gocar-cov-*.out")
+	if err != nil {
+		return "", err
+	}
+	if err := profile.Write(file); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// runCovdata 执行 go tool covdata <mode> -i=<dirs>
+func runCovdata(mode string, dirs []string, extra ...string) error {
+	abs := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		path, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		abs = append(abs, path)
+	}
+	args := append([]string{"tool", "covdata", mode, "-i=" + strings.Join(abs, ",")}, extra...)
+	cmd := exec.Command("go", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("go tool covdata %s failed: %w\n%s", mode, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func plural(n int, singular, multiple string) string {
+	if n == 1 {
+		return singular
+	}
+	return multiple
+}
+
+// Help 返回帮助信息
+func (c *CovCommand) Help() string {
+	return `gocar cov - Merge and report coverage data
+
+USAGE:
+    gocar cov <subcommand> [OPTIONS] <input>...
+
+SUBCOMMANDS:
+    merge     Merge GOCOVERDIR directories into one (-o <dir> required)
+    report    Print statement coverage per package and in total
+    html      Write an HTML report (default: coverage.html)
+    textfmt   Write a text profile, as produced by go test -coverprofile
+              (default: stdout)
+
+INPUTS:
+    Each input is a GOCOVERDIR directory written by a binary built with
+    cover = true, or by 'gocar test --coverage --coverdir <dir>', or a text
+    profile file such as go test -coverprofile output. Inputs can be given
+    as arguments or with -i dir1,dir2. All inputs must use the same
+    coverage mode.
+
+OPTIONS:
+    -i, --input <list>    Comma-separated inputs
+    -o, --output <path>   Output directory (merge) or file (html, textfmt)
+    --json                Print the report as JSON (report only)
+
+EXAMPLES:
+    gocar test --coverage --coverdir coverage/unit
+    GOCOVERDIR=coverage/e2e ./bin/cover/linux-amd64/app
+    gocar cov report coverage/unit coverage/e2e
+    gocar cov html coverage/unit coverage/e2e -o coverage.html
+    gocar cov merge -i coverage/unit,coverage/e2e -o coverage/all
+`
+}
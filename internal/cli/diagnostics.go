@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+
+	"gocar/internal/build"
+	"gocar/internal/diag"
+)
+
+// extractMessageFormat 从参数中取出 --message-format <fmt>，返回格式与剩余参数。
+// "--" 之后的参数原样保留，不做解析。
+func extractMessageFormat(args []string) (string, []string, error) {
+	format := build.MessageFormatHuman
+	rest := []string{}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if args[i] != "--message-format" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return "", nil, fmt.Errorf("--message-format requires a value")
+		}
+		format = args[i+1]
+		if err := validateMessageFormat(format); err != nil {
+			return "", nil, err
+		}
+		i++
+	}
+	return format, rest, nil
+}
+
+// validateMessageFormat 检查 --message-format 的取值
+func validateMessageFormat(format string) error {
+	switch format {
+	case build.MessageFormatHuman, build.MessageFormatJSON, build.MessageFormatSARIF:
+		return nil
+	}
+	return fmt.Errorf("invalid --message-format %q (expected: human, json, sarif)", format)
+}
+
+// runGoDiagnosed 在项目根目录执行 go 子命令并捕获输出，同时解析其中的诊断
+func runGoDiagnosed(projectRoot, tool string, args []string) ([]byte, []diag.Diagnostic, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = projectRoot
+	output, err := cmd.CombinedOutput()
+	diagnostics := diag.Parse(tool, output)
+	diag.Resolve(projectRoot, diagnostics)
+	return output, diagnostics, err
+}
+
+// writeDiagnostics 以 json (每行一个 compiler-diagnostic 事件) 或 sarif 格式输出诊断
+func writeDiagnostics(w io.Writer, format, projectRoot string, diagnostics []diag.Diagnostic) error {
+	if format == build.MessageFormatSARIF {
+		return diag.WriteSARIF(w, projectRoot, Version, diagnostics)
+	}
+	events := build.NewEventWriter(w)
+	for _, d := range diagnostics {
+		events.Emit(build.Event{Reason: build.EventCompilerDiagnostic, Diagnostic: &d})
+	}
+	return nil
+}
+
+// printTestFailures 在 go test 原始输出之后汇总带位置的失败信息 (附源码片段)
+func printTestFailures(w io.Writer, projectRoot string, diagnostics []diag.Diagnostic) {
+	located := []diag.Diagnostic{}
+	for _, d := range diagnostics {
+		if d.HasLocation() {
+			located = append(located, d)
+		}
+	}
+	if len(located) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nFailures:")
+	diag.Render(w, projectRoot, located)
+}
@@ -8,7 +8,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"gocar/internal/build"
 	"gocar/internal/config"
 	"gocar/internal/project"
 )
@@ -37,26 +39,27 @@ func (c *DoctorCommand) Run(args []string) error {
 
 	projectRoot, appName, projectMode, err := project.DetectProject()
 	if err != nil {
-		fmt.Printf("ERR project: %v\n", err)
+		printCheck("ERR", "project: %v", err)
 		ok = false
 	} else {
-		fmt.Printf("OK  project: %s (%s mode)\n", appName, projectMode)
+		printCheck("OK", "project: %s (%s mode)", appName, projectMode)
 		fmt.Printf("  root: %s\n", projectRoot)
 
 		cfg, err := config.Load(projectRoot)
 		if err != nil {
-			fmt.Printf("ERR %s: %v\n", config.ConfigFileName, err)
+			printCheck("ERR", "%s: %v", config.ConfigFileName, err)
 			ok = false
 		} else if err := cfg.Validate(projectRoot); err != nil {
-			fmt.Printf("ERR %s: %v\n", config.ConfigFileName, err)
+			printCheck("ERR", "%s: %v", config.ConfigFileName, err)
 			ok = false
 		} else {
 			if config.Exists(projectRoot) {
-				fmt.Printf("OK  %s: valid\n", config.ConfigFileName)
+				printCheck("OK", "%s: valid", config.ConfigFileName)
 			} else {
-				fmt.Printf("OK  %s: not found, using defaults\n", config.ConfigFileName)
+				printCheck("OK", "%s: not found, using defaults", config.ConfigFileName)
 			}
 			fmt.Printf("  profiles: %v\n", cfg.ListProfiles())
+			printResolvedProfiles(cfg)
 			if !printCommandOverrideWarnings(cfg) {
 				ok = false
 			}
@@ -69,6 +72,9 @@ func (c *DoctorCommand) Run(args []string) error {
 					ok = false
 				}
 			}
+			if !printPGOCheck(projectRoot, appName, cfg) {
+				ok = false
+			}
 		}
 	}
 
@@ -83,15 +89,15 @@ func printGoEnvironment() bool {
 	versionCmd := exec.Command("go", "version")
 	versionOutput, err := versionCmd.Output()
 	if err != nil {
-		fmt.Printf("ERR go version: %v\n", err)
+		printCheck("ERR", "go version: %v", err)
 		return false
 	}
-	fmt.Printf("OK  go.version: %s\n", strings.TrimSpace(string(versionOutput)))
+	printCheck("OK", "go.version: %s", strings.TrimSpace(string(versionOutput)))
 
 	envCmd := exec.Command("go", "env", "GOPROXY", "GOMODCACHE", "CGO_ENABLED")
 	envOutput, err := envCmd.Output()
 	if err != nil {
-		fmt.Printf("ERR go env: %v\n", err)
+		printCheck("ERR", "go env: %v", err)
 		return false
 	}
 	lines := strings.Split(strings.TrimSpace(string(envOutput)), "\n")
@@ -101,40 +107,172 @@ func printGoEnvironment() bool {
 		if i < len(lines) {
 			value = lines[i]
 		}
-		fmt.Printf("OK  go.env.%s: %s\n", key, value)
+		printCheck("OK", "go.env.%s: %s", key, value)
 	}
 	return true
 }
 
+// printResolvedProfiles 输出每个 profile 展开 inherits 后的最终参数
+func printResolvedProfiles(cfg *config.GocarConfig) {
+	for _, name := range cfg.ListProfiles() {
+		profile, err := cfg.ResolveProfile(name)
+		if err != nil {
+			continue
+		}
+		label := name
+		if profile.Inherits != "" {
+			label += " (inherits " + profile.Inherits + ")"
+		}
+		cgo := "default"
+		if profile.CgoEnabled != nil {
+			cgo = fmt.Sprint(*profile.CgoEnabled)
+		}
+		fields := []string{
+			fmt.Sprintf("ldflags=%q", profile.Ldflags),
+			fmt.Sprintf("gcflags=%q", profile.Gcflags),
+			fmt.Sprintf("trimpath=%v", config.Enabled(profile.Trimpath)),
+			"cgo_enabled=" + cgo,
+			fmt.Sprintf("race=%v", config.Enabled(profile.Race)),
+		}
+		// 其余字段仅在设置时输出
+		optional := []struct {
+			name  string
+			value string
+		}{
+			{"asmflags", profile.Asmflags},
+			{"tags", strings.Join(profile.Tags, ",")},
+			{"coverpkg", strings.Join(profile.Coverpkg, ",")},
+			{"extra_env", strings.Join(profile.ExtraEnv, " ")},
+			{"buildmode", profile.Buildmode},
+			{"mod", profile.Mod},
+			{"goexperiment", profile.Goexperiment},
+			{"pgo", profile.Pgo},
+		}
+		for _, field := range optional {
+			if field.value != "" {
+				fields = append(fields, fmt.Sprintf("%s=%q", field.name, field.value))
+			}
+		}
+		if config.Enabled(profile.Msan) {
+			fields = append(fields, "msan=true")
+		}
+		if config.Enabled(profile.Asan) {
+			fields = append(fields, "asan=true")
+		}
+		if config.Enabled(profile.Cover) {
+			fields = append(fields, "cover=true")
+		}
+		fmt.Printf("  profile.%s: %s\n", label, strings.Join(fields, " "))
+	}
+}
+
 func printCommandOverrideWarnings(cfg *config.GocarConfig) bool {
 	for name := range cfg.Commands {
 		if isBuiltInCommandName(name) && !isProtectedCommand(name) {
-			fmt.Printf("WARN command %q overrides built-in command\n", name)
+			printCheck("WARN", "command %q overrides built-in command", name)
 		}
 	}
 	return true
 }
 
+// printCheck 输出一项检查结果，状态 (OK/ERR/WARN) 占固定宽度，使各行对齐
+func printCheck(status, format string, args ...any) {
+	fmt.Printf("%-4s %s\n", status, fmt.Sprintf(format, args...))
+}
+
+// printPGOCheck 检查各 profile 使用的 PGO profile 文件，并报告其更新时间与之后的提交数
+func printPGOCheck(projectRoot, appName string, cfg *config.GocarConfig) bool {
+	var entries []string
+	for _, bin := range cfg.GetBins(projectRoot) {
+		entries = append(entries, bin.Entry)
+	}
+	if len(entries) == 0 {
+		entries = []string{cfg.GetBuildEntryForApp(appName)}
+	}
+
+	ok := true
+	seen := map[string]bool{}
+	var paths []string
+	for _, name := range cfg.ListProfiles() {
+		profile, err := cfg.ResolveProfile(name)
+		if err != nil {
+			continue
+		}
+		switch profile.Pgo {
+		case build.PgoOff:
+		case "", build.PgoAuto:
+			// auto 模式下 default.pgo 是可选的
+			for _, entry := range entries {
+				path := build.DefaultPGOPath(projectRoot, entry)
+				if _, err := os.Stat(filepath.Join(projectRoot, path)); err == nil && !seen[path] {
+					seen[path] = true
+					paths = append(paths, path)
+				}
+			}
+		default:
+			path := profile.Pgo
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			absPath := path
+			if !filepath.IsAbs(absPath) {
+				absPath = filepath.Join(projectRoot, absPath)
+			}
+			if _, err := os.Stat(absPath); err != nil {
+				printCheck("ERR", "profile.%s.pgo: %s does not exist", name, path)
+				ok = false
+				continue
+			}
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 && ok {
+		fmt.Println("  pgo: no profile (collect one with 'gocar pgo collect --bench .')")
+	}
+
+	for _, path := range paths {
+		updated, commits, err := pgoStaleness(projectRoot, path)
+		if err != nil {
+			printCheck("ERR", "pgo: %v", err)
+			ok = false
+			continue
+		}
+		age := time.Since(updated)
+		detail := fmt.Sprintf("updated %s, %s ago", updated.Format("2006-01-02"), formatAge(age))
+		if commits >= 0 {
+			detail += fmt.Sprintf(", %d commits since", commits)
+		}
+		status := "OK"
+		if age > pgoStaleAge {
+			status = "WARN"
+			detail += "; consider re-running 'gocar pgo collect'"
+		}
+		printCheck(status, "pgo: %s (%s)", path, detail)
+	}
+	return ok
+}
+
 func printEntryCheck(projectRoot, label, entry string, requireMain bool) bool {
 	path := entry
 	if path == "" {
-		fmt.Printf("ERR %s: empty\n", label)
+		printCheck("ERR", "%s: empty", label)
 		return false
 	}
 	if !filepath.IsAbs(path) {
 		path = filepath.Join(projectRoot, path)
 	}
 	if _, err := os.Stat(path); err != nil {
-		fmt.Printf("ERR %s: %s does not exist\n", label, entry)
+		printCheck("ERR", "%s: %s does not exist", label, entry)
 		return false
 	}
-	fmt.Printf("OK  %s: %s\n", label, entry)
+	printCheck("OK", "%s: %s", label, entry)
 	if requireMain {
 		if !entryHasMainPackage(path) {
-			fmt.Printf("ERR %s: %s is not a main package\n", label, entry)
+			printCheck("ERR", "%s: %s is not a main package", label, entry)
 			return false
 		}
-		fmt.Printf("OK  %s.package: main\n", label)
+		printCheck("OK", "%s.package: main", label)
 	}
 	return true
 }
@@ -166,13 +304,13 @@ func printToolCheck(name string, required bool) bool {
 	path, err := exec.LookPath(name)
 	if err != nil {
 		if required {
-			fmt.Printf("ERR %s: not found\n", name)
+			printCheck("ERR", "%s: not found", name)
 			return false
 		}
-		fmt.Printf("WARN %s: not found (optional)\n", name)
+		printCheck("WARN", "%s: not found (optional)", name)
 		return true
 	}
-	fmt.Printf("OK  %s: %s\n", name, path)
+	printCheck("OK", "%s: %s", name, path)
 	return true
 }
 
@@ -185,5 +323,9 @@ USAGE:
 
 DESCRIPTION:
     Checks Go, Git, project detection, and .gocar.toml validation.
+    Prints the resolved flags of every profile, with inherits chains applied
+    (a field set to "" or [] clears the value inherited from the parent).
+    Reports the PGO profiles in use and how stale they are (age and number
+    of commits since they were last updated).
 `
 }
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"debug/buildinfo"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -102,3 +103,56 @@ func runCmdOutput(dir, name string, args ...string) (string, error) {
 	out, err := cmd.CombinedOutput()
 	return string(out), err
 }
+
+func TestE2EInstallIgnoresConfiguredTargets(t *testing.T) {
+	root := findRepoRoot(t)
+	tmp := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "config"))
+	bin := filepath.Join(tmp, "gocar")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	runCmd(t, root, "go", "build", "-o", bin, "./cmd/gocar")
+	runCmd(t, tmp, bin, "new", "myapp")
+	appRoot := filepath.Join(tmp, "myapp")
+
+	// [build].targets 只配置非本机目标，install 仍应只构建并安装本机二进制
+	targets := []string{}
+	for _, target := range []string{"linux/arm64", "windows/amd64", "darwin/arm64"} {
+		if target != runtime.GOOS+"/"+runtime.GOARCH {
+			targets = append(targets, `"`+target+`"`)
+		}
+	}
+	config := "[build]\ntargets = [" + strings.Join(targets, ", ") + "]\n"
+	if err := os.WriteFile(filepath.Join(appRoot, ".gocar.toml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := filepath.Join(tmp, "prefix")
+	if out, err := runCmdOutput(appRoot, bin, "install", "--debug", "--root", prefix); err != nil {
+		t.Fatalf("gocar install failed: %v\n%s", err, out)
+	}
+	entries, err := os.ReadDir(filepath.Join(prefix, "bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "myapp"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if len(entries) != 1 || entries[0].Name() != name {
+		t.Fatalf("installed files = %v, want only %s", entries, name)
+	}
+	info, err := buildinfo.ReadFile(filepath.Join(prefix, "bin", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, setting := range info.Settings {
+		if (setting.Key == "GOOS" && setting.Value != runtime.GOOS) || (setting.Key == "GOARCH" && setting.Value != runtime.GOARCH) {
+			t.Fatalf("installed binary was built with %s=%s, want the host platform", setting.Key, setting.Value)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(appRoot, "bin", "debug", "linux-arm64")); err == nil && runtime.GOOS+"/"+runtime.GOARCH != "linux/arm64" {
+		t.Fatal("install should not build [build].targets")
+	}
+}
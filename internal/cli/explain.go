@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gocar/internal/build"
+)
+
+// explainMode --dry-run / --explain 的取值
+type explainMode int
+
+const (
+	explainOff    explainMode = iota
+	explainDryRun             // --dry-run: 输出执行计划后退出，不执行命令与钩子
+	explainRun                // --explain: 输出执行计划后照常执行
+)
+
+// parseExplainFlag 识别 --dry-run 与 --explain
+func parseExplainFlag(arg string) (explainMode, bool) {
+	switch arg {
+	case "--dry-run":
+		return explainDryRun, true
+	case "--explain":
+		return explainRun, true
+	}
+	return explainOff, false
+}
+
+// extractExplainMode 从参数中取出 --dry-run / --explain，返回模式与剩余参数。
+// "--" 之后的参数原样保留，不做解析。
+func extractExplainMode(args []string) (explainMode, []string) {
+	mode := explainOff
+	rest := []string{}
+	for i, arg := range args {
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if m, ok := parseExplainFlag(arg); ok {
+			mode = m
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return mode, rest
+}
+
+// printPlan 输出执行计划：完整命令行、工作目录、gocar 设置的环境变量以及各参数的来源
+func printPlan(w io.Writer, plan *build.Plan) {
+	fmt.Fprintf(w, "%s\n", plan.Title)
+	fmt.Fprintf(w, "  command: %s\n", shellJoin(plan.Args))
+	fmt.Fprintf(w, "  dir:     %s\n", plan.Dir)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(plan.Env) == 0 {
+		fmt.Fprintln(tw, "  env:     (inherited, nothing added)")
+	} else {
+		fmt.Fprintln(tw, "  env:")
+		for _, setting := range plan.Env {
+			fmt.Fprintf(tw, "    %s=%s\t%s\n", setting.Name, setting.Value, setting.Source)
+		}
+	}
+	if len(plan.Settings) > 0 {
+		fmt.Fprintln(tw, "  settings:")
+		for _, setting := range plan.Settings {
+			fmt.Fprintf(tw, "    %s\t%s\t%s\n", setting.Name, setting.Value, setting.Source)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
+
+// shellJoin 将命令行拼接为可直接粘贴到 shell 中执行的字符串
+func shellJoin(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote 仅在参数包含 shell 特殊字符时加单引号
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(arg, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
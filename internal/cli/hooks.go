@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gocar/internal/build"
+	"gocar/internal/config"
+)
+
+// runHook 执行 [hooks] 中的钩子，未配置时直接返回
+func (ctx *buildContext) runHook(name string, env map[string]string, stdout io.Writer) error {
+	cmd, ok := ctx.cfg.HookCommand(ctx.projectRoot, name, env)
+	if !ok {
+		return nil
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
+
+// withFailureHook 命令失败时执行 on_failure 钩子，返回原始错误。
+// on_failure 自身失败只输出警告，不覆盖原始错误。
+func (ctx *buildContext) withFailureHook(command string, env map[string]string, stdout io.Writer, err error) error {
+	if err == nil {
+		return nil
+	}
+	failureEnv := map[string]string{
+		"GOCAR_COMMAND": command,
+		"GOCAR_ERROR":   err.Error(),
+	}
+	for key, value := range env {
+		failureEnv[key] = value
+	}
+	if hookErr := ctx.runHook(config.HookOnFailure, failureEnv, stdout); hookErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", hookErr)
+	}
+	return err
+}
+
+// buildHookEnv 返回 pre_build 等构建级钩子的环境变量：
+// GOCAR_TARGET 为逗号分隔的全部目标，单个构建时还包含 GOCAR_ARTIFACT
+func buildHookEnv(builders []*build.Builder) map[string]string {
+	if len(builders) == 1 {
+		return builders[0].HookEnv()
+	}
+
+	targets := []string{}
+	for _, builder := range builders {
+		targets = append(targets, builder.Target())
+	}
+	env := builders[0].HookEnv()
+	delete(env, "GOCAR_ARTIFACT")
+	delete(env, "GOCAR_BIN")
+	env["GOCAR_TARGET"] = strings.Join(uniqueTargets(targets), ",")
+	return env
+}
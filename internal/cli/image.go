@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gocar/internal/build"
+	"gocar/internal/config"
+	"gocar/internal/image"
+	"gocar/internal/util"
+)
+
+// ImageCommand image 命令
+type ImageCommand struct{}
+
+// Run 执行 image 命令
+func (c *ImageCommand) Run(args []string) error {
+	opts := newBuildOptions()
+	opts.profile = "release"
+	base := ""
+	format := ""
+	output := ""
+	var tags []string
+
+	for i := 0; i < len(args); {
+		next, ok, err := opts.parse(args, i)
+		if err != nil {
+			return err
+		}
+		if ok {
+			i = next
+			continue
+		}
+
+		arg := args[i]
+		switch arg {
+		case "help", "--help", "-h":
+			fmt.Print(c.Help())
+			return nil
+		case "--base", "--format", "--tag", "-o", "--output":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			switch arg {
+			case "--base":
+				base = args[i+1]
+			case "--format":
+				format = args[i+1]
+			case "--tag":
+				if err := config.ValidateImageTag(args[i+1]); err != nil {
+					return err
+				}
+				tags = append(tags, args[i+1])
+			default:
+				output = args[i+1]
+			}
+			i++
+		default:
+			return fmt.Errorf("unknown option '%s' (run 'gocar image --help' for usage)", arg)
+		}
+		i++
+	}
+
+	ctx, err := loadBuildContext()
+	if err != nil {
+		return err
+	}
+	imageCfg := ctx.cfg.Image
+
+	if format == "" {
+		format = imageCfg.Format
+	}
+	if format == "" {
+		format = image.FormatOCI
+	}
+	if !slices.Contains(image.Formats, format) {
+		return fmt.Errorf("invalid --format %q (expected: %s)", format, strings.Join(image.Formats, ", "))
+	}
+	if base == "" && imageCfg.Base != "" {
+		base = filepath.Join(ctx.projectRoot, imageCfg.Base)
+	}
+	if len(tags) == 0 {
+		tags = ctx.cfg.GetImageTags(ctx.projectRoot)
+	}
+
+	// 未指定目标时使用 [image].platforms，默认为当前架构的 linux
+	if len(opts.targets) == 0 {
+		opts.targets = imageCfg.Platforms
+		if len(opts.targets) == 0 {
+			opts.targets = []string{"linux/" + runtime.GOARCH}
+		}
+	}
+	for _, target := range opts.targets {
+		if !strings.HasPrefix(target, "linux/") {
+			return fmt.Errorf("cannot build an image for %s: only linux targets are supported", target)
+		}
+	}
+	// 在交叉编译之前拒绝多平台的 docker 格式，避免构建完所有目标后才失败
+	if format == image.FormatDocker && len(opts.targets) > 1 {
+		return image.ErrDockerMultiPlatform
+	}
+	if opts.binName == "" {
+		opts.binName = imageCfg.Bin
+	}
+
+	builders, err := opts.builders(ctx)
+	if err != nil {
+		return err
+	}
+	binName := builders[0].Name()
+	for _, builder := range builders {
+		if builder.Name() != binName {
+			return fmt.Errorf("an image holds a single binary; select one with --bin or [image].bin (found %s and %s)", binName, builder.Name())
+		}
+		if !builder.Executable() {
+			return fmt.Errorf("cannot put %s in an image: the profile builds a library, not an executable", builder.Name())
+		}
+	}
+
+	name := ctx.cfg.GetImageName(ctx.appName)
+	if output == "" {
+		output = imageCfg.Output
+	}
+	if output == "" {
+		output = filepath.Join(ctx.cfg.GetPackageOutputRoot(), "image", path.Base(name))
+		if format == image.FormatDocker {
+			output += ".tar"
+		}
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(ctx.projectRoot, output)
+	}
+
+	hookEnv := buildHookEnv(builders)
+	if err := ctx.runHook(config.HookPreBuild, hookEnv, os.Stdout); err != nil {
+		return ctx.withFailureHook("image", hookEnv, os.Stdout, err)
+	}
+
+	imageOpts := &image.Options{
+		Name:       name,
+		Tags:       tags,
+		BinName:    binName,
+		Dir:        imageCfg.Dir,
+		Base:       base,
+		Entrypoint: imageCfg.Entrypoint,
+		Cmd:        imageCfg.Cmd,
+		Env:        imageCfg.Env,
+		Labels:     c.labels(ctx),
+		WorkingDir: imageCfg.Workdir,
+		User:       imageCfg.User,
+		Ports:      imageCfg.Ports,
+		Created:    build.SourceDate(ctx.projectRoot),
+	}
+	return ctx.withFailureHook("image", hookEnv, os.Stdout, c.build(ctx, builders, imageOpts, format, output, opts.jobs))
+}
+
+// build 构建二进制并写入镜像
+func (c *ImageCommand) build(ctx *buildContext, builders []*build.Builder, opts *image.Options, format, output string, jobs int) error {
+	results, err := runBuilds(builders, jobs)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+
+	for i, result := range results {
+		artifact := builders[i].GetOutputPath()
+		targetOS, targetArch, variant, _ := build.ParseTarget(result.Target)
+		opts.Binaries = append(opts.Binaries, image.Binary{
+			Path:     artifact,
+			Platform: image.PlatformFor(targetOS, targetArch, variant),
+		})
+		// scratch 中没有动态链接器与 libc，动态链接的二进制无法启动
+		if opts.Base == "" && dynamicallyLinked(artifact) {
+			fmt.Printf("Warning: %s is dynamically linked and will not start on a scratch base; disable cgo or set [image].base\n", result.Artifact)
+		}
+	}
+
+	imageResult, err := image.Write(opts, format, output)
+	if err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+
+	fmt.Printf("Image %s\n", strings.Join(opts.Refs(), ", "))
+	for _, binary := range opts.Binaries {
+		platform := binary.Platform.String()
+		fmt.Printf("  %-16s %s\n", platform, imageResult.Manifests[platform])
+	}
+	if len(opts.Binaries) > 1 {
+		fmt.Printf("  %-16s %s\n", "index", imageResult.Digest)
+	}
+	if format == image.FormatDocker {
+		fmt.Printf("Wrote %s (load with: docker load -i %s)\n", relPath(ctx.projectRoot, output), relPath(ctx.projectRoot, output))
+	} else {
+		fmt.Printf("Wrote %s (OCI image layout)\n", relPath(ctx.projectRoot, output))
+	}
+	return nil
+}
+
+// labels 返回镜像标签：[image].labels 优先，未设置时补充版本号与提交
+func (c *ImageCommand) labels(ctx *buildContext) map[string]string {
+	labels := map[string]string{}
+	if version := ctx.cfg.GetVersion(ctx.projectRoot); version != "" {
+		labels["org.opencontainers.image.version"] = version
+	}
+	if commit, err := util.GitOutput(ctx.projectRoot, "rev-parse", "HEAD"); err == nil {
+		labels["org.opencontainers.image.revision"] = commit
+	}
+	for key, value := range ctx.cfg.Image.Labels {
+		labels[key] = value
+	}
+	return labels
+}
+
+// dynamicallyLinked 检查 ELF 二进制是否需要动态链接器
+func dynamicallyLinked(path string) bool {
+	file, err := elf.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	for _, prog := range file.Progs {
+		if prog.Type == elf.PT_INTERP {
+			return true
+		}
+	}
+	return false
+}
+
+// Help 返回帮助信息
+func (c *ImageCommand) Help() string {
+	return `gocar image - Build a container image without Docker
+
+USAGE:
+    gocar image [OPTIONS]
+
+OPTIONS:
+    --target <os>/<arch>[/<variant>]
+                           Build the image for a linux platform
+    --targets <list>       Build a multi-arch image for comma-separated platforms
+    --base <dir>           Base image as an OCI image layout directory
+                           (default: [image].base, or scratch)
+    --format <fmt>         oci (OCI image layout directory, default) or
+                           docker (docker save tarball, single platform)
+    --tag <tag>            Image tag, repeatable (default: [image].tags, the
+                           project version or latest)
+    -o, --output <path>    Output path (default: dist/image/<name>, .tar for docker)
+    --profile <name>       Build profile (default: release)
+    --bin <name>           Binary to put in the image (default: [image].bin)
+    --with-cgo             Force enable CGO (sets CGO_ENABLED=1)
+    --force                Rebuild even if the build fingerprint is unchanged
+    --help                 Show this help message
+
+DESCRIPTION:
+    Builds the binary for each platform and writes an image with one layer
+    holding the binary (at /usr/local/bin/<name> unless [image].dir is set)
+    on top of the base image layers. Entrypoint, cmd, env, labels, user,
+    workdir and ports come from the [image] section of .gocar.toml. Several
+    platforms produce a multi-arch image index. No container runtime or
+    network access is needed; layers use the commit time (or
+    SOURCE_DATE_EPOCH) as timestamp, so unchanged binaries give the same
+    digests.
+
+    A base image layout can be exported once, for example with
+    'skopeo copy docker://gcr.io/distroless/static oci:images/distroless'.
+
+    The OCI layout can be pushed with 'skopeo copy oci:dist/image/app
+    docker://...' or 'crane push'; the docker tarball can be loaded with
+    'docker load -i'.
+
+EXAMPLES:
+    gocar image
+    gocar image --targets linux/amd64,linux/arm64
+    gocar image --base images/distroless --tag dev
+    gocar image --format docker -o app.tar
+`
+}
@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gocar/internal/build"
+	"gocar/internal/config"
+	"gocar/internal/install"
+)
+
+// InstallCommand install 命令
+type InstallCommand struct{}
+
+// UninstallCommand uninstall 命令
+type UninstallCommand struct{}
+
+// Run 执行 install 命令
+func (c *InstallCommand) Run(args []string) error {
+	opts := newBuildOptions()
+	opts.profile = "release"
+	// 安装的二进制在本机运行，不使用 [build].targets
+	opts.hostOnly = true
+	root := ""
+	force := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "help", "--help", "-h":
+			fmt.Print(c.Help())
+			return nil
+		case "--profile", "--bin", "--root":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			switch arg {
+			case "--profile":
+				opts.profile = args[i+1]
+			case "--bin":
+				opts.binName = args[i+1]
+			default:
+				root = args[i+1]
+			}
+			i++
+		case "--debug":
+			opts.profile = "debug"
+		case "--force", "-f":
+			force = true
+		default:
+			return fmt.Errorf("unknown option '%s' (run 'gocar install --help' for usage)", arg)
+		}
+	}
+
+	ctx, err := loadBuildContext()
+	if err != nil {
+		return err
+	}
+	destDir, err := installDir(ctx.projectRoot, root)
+	if err != nil {
+		return err
+	}
+	opts.config.Force = force
+	builders, err := opts.builders(ctx)
+	if err != nil {
+		return err
+	}
+	for _, builder := range builders {
+		if !builder.Executable() {
+			return fmt.Errorf("cannot install %s: the profile builds a library, not an executable", builder.Name())
+		}
+	}
+
+	hookEnv := buildHookEnv(builders)
+	if err := ctx.runHook(config.HookPreBuild, hookEnv, os.Stdout); err != nil {
+		return ctx.withFailureHook("install", hookEnv, os.Stdout, err)
+	}
+	return ctx.withFailureHook("install", hookEnv, os.Stdout, c.install(ctx, builders, destDir, force))
+}
+
+// install 构建并将产物复制到安装目录，构建信息与已安装的二进制相同时跳过
+func (c *InstallCommand) install(ctx *buildContext, builders []*build.Builder, destDir string, force bool) error {
+	results, err := runBuilds(builders, 0)
+	if err != nil {
+		return err
+	}
+
+	manifestPath, err := install.ManifestPath()
+	if err != nil {
+		return err
+	}
+	manifest, err := install.ReadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	// 不覆盖其他工具安装的同名文件，除非指定 --force
+	dests := make([]string, len(builders))
+	for i, builder := range builders {
+		name := builder.Name()
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		dests[i] = filepath.Join(destDir, name)
+		if !force {
+			if err := manifest.CheckOwned(dests[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Println()
+	for i, builder := range builders {
+		src := builder.GetOutputPath()
+		dest := dests[i]
+
+		if !force && install.SameBuild(src, dest) {
+			fmt.Printf("Unchanged %s (same build already installed, use --force to reinstall)\n", dest)
+		} else {
+			if err := install.CopyAtomic(src, dest); err != nil {
+				return fmt.Errorf("failed to install %s: %w", dest, err)
+			}
+			fmt.Printf("Installed %s\n", dest)
+		}
+
+		digest, err := install.FileDigest(dest)
+		if err != nil {
+			return err
+		}
+		manifest.Add(install.NewRecord(builder.Name(), dest, ctx.projectRoot, results[i].Profile, digest))
+	}
+	if err := manifest.Save(manifestPath); err != nil {
+		return fmt.Errorf("failed to update %s: %w", manifestPath, err)
+	}
+
+	if !pathContains(os.Getenv("PATH"), destDir) {
+		fmt.Printf("Warning: %s is not in your PATH\n", destDir)
+	}
+	return nil
+}
+
+// installDir 返回安装目录：<root>/bin > $GOBIN > $GOPATH/bin (取 GOPATH 中的第一个目录)
+func installDir(projectRoot, root string) (string, error) {
+	if root != "" {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(abs, "bin"), nil
+	}
+
+	cmd := exec.Command("go", "env", "GOBIN", "GOPATH")
+	cmd.Dir = projectRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env failed: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if gobin := strings.TrimSpace(lines[0]); gobin != "" {
+		return gobin, nil
+	}
+	if len(lines) > 1 {
+		if gopath := filepath.SplitList(strings.TrimSpace(lines[1])); len(gopath) > 0 && gopath[0] != "" {
+			return filepath.Join(gopath[0], "bin"), nil
+		}
+	}
+	return "", fmt.Errorf("cannot determine install directory: GOBIN and GOPATH are not set (use --root)")
+}
+
+// pathContains 检查 PATH 中是否包含 dir
+func pathContains(pathEnv, dir string) bool {
+	for _, entry := range filepath.SplitList(pathEnv) {
+		if entry != "" && filepath.Clean(entry) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// Help 返回帮助信息
+func (c *InstallCommand) Help() string {
+	return `gocar install - Build and install binaries
+
+USAGE:
+    gocar install [OPTIONS]
+
+OPTIONS:
+    --profile <name>    Build profile (default: release)
+    --debug             Shorthand for --profile debug
+    --bin <name>        Install only the named binary
+    --root <prefix>     Install into <prefix>/bin instead of $GOBIN or
+                        $GOPATH/bin
+    -f, --force         Rebuild and reinstall even if the same build is
+                        already installed, and replace files that were not
+                        installed by gocar
+    --help              Show this help message
+
+DESCRIPTION:
+    Builds the project for the host platform ([build].targets is ignored)
+    and copies each binary into $GOBIN, $GOPATH/bin or <prefix>/bin. The
+    copy is written to a temporary file and renamed into place, so an
+    interrupted install never leaves a half-written binary behind.
+
+    When the destination already holds a binary with the same embedded
+    build info (module versions, build settings and VCS revision), the
+    copy is skipped. Builds from a modified work tree are compared by
+    content instead.
+
+    Installed binaries are recorded in installed.json under the user
+    config directory, so 'gocar uninstall' can remove them later. An
+    existing file that gocar did not install (e.g. from 'go install') is
+    left alone unless --force is given.
+
+EXAMPLES:
+    gocar install
+    gocar install --bin api
+    gocar install --root ~/.local
+`
+}
+
+// Run 执行 uninstall 命令
+func (c *UninstallCommand) Run(args []string) error {
+	root := ""
+	var names []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "help", "--help", "-h":
+			fmt.Print(c.Help())
+			return nil
+		case "--root":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--root requires a value")
+			}
+			abs, err := filepath.Abs(args[i+1])
+			if err != nil {
+				return err
+			}
+			root = filepath.Join(abs, "bin")
+			i++
+		case "--bin":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--bin requires a value")
+			}
+			names = append(names, args[i+1])
+			i++
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return fmt.Errorf("unknown option '%s' (run 'gocar uninstall --help' for usage)", arg)
+			}
+			names = append(names, arg)
+		}
+	}
+
+	manifestPath, err := install.ManifestPath()
+	if err != nil {
+		return err
+	}
+	manifest, err := install.ReadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	// 未指定名称时卸载当前项目安装的所有二进制
+	projectRoot := ""
+	if len(names) == 0 {
+		ctx, err := loadBuildContext()
+		if err != nil {
+			return fmt.Errorf("%w (or name the binaries to uninstall)", err)
+		}
+		projectRoot = ctx.projectRoot
+	}
+
+	var selected []install.Record
+	for _, record := range manifest.Installs {
+		if root != "" && filepath.Dir(record.Path) != root {
+			continue
+		}
+		if len(names) > 0 && !slices.Contains(names, record.Name) {
+			continue
+		}
+		if projectRoot != "" && record.Project != projectRoot {
+			continue
+		}
+		selected = append(selected, record)
+	}
+	if len(selected) == 0 {
+		if len(names) > 0 {
+			return fmt.Errorf("no installed binaries named %s", strings.Join(names, ", "))
+		}
+		return fmt.Errorf("nothing installed from %s", projectRoot)
+	}
+
+	for _, record := range selected {
+		// 安装后被其他程序替换的文件不属于 gocar，只移除记录
+		if digest, err := install.FileDigest(record.Path); err == nil && digest != record.SHA256 {
+			fmt.Printf("Warning: %s changed since it was installed; leaving it in place\n", record.Path)
+			manifest.Remove(record.Path)
+			continue
+		}
+		if err := os.Remove(record.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", record.Path, err)
+		}
+		manifest.Remove(record.Path)
+		fmt.Printf("Removed %s\n", record.Path)
+	}
+	return manifest.Save(manifestPath)
+}
+
+// Help 返回帮助信息
+func (c *UninstallCommand) Help() string {
+	return `gocar uninstall - Remove binaries installed by gocar install
+
+USAGE:
+    gocar uninstall [OPTIONS] [names...]
+
+OPTIONS:
+    --bin <name>        Remove the named binary (same as passing the name)
+    --root <prefix>     Only remove binaries installed into <prefix>/bin
+    --help              Show this help message
+
+DESCRIPTION:
+    Without names, removes every binary installed from the current project.
+    With names, removes the matching binaries regardless of the project
+    they were installed from. Only files recorded by 'gocar install' are
+    removed.
+
+EXAMPLES:
+    gocar uninstall
+    gocar uninstall api
+    gocar uninstall --root ~/.local
+`
+}
@@ -17,17 +17,26 @@ var builtInCommands = []CommandInfo{
 	{Name: "new", Usage: "new <name>", Description: "Create a standard Go application", Example: "gocar new myapp"},
 	{Name: "init", Usage: "init", Description: "Initialize .gocar.toml in current project", Example: "gocar init"},
 	{Name: "build", Usage: "build [OPTIONS]", Description: "Build the project", Example: "gocar build --release"},
-	{Name: "run", Usage: "run [args...]", Description: "Run the project", Example: "gocar run"},
+	{Name: "run", Usage: "run [--bin <name>] [args...]", Description: "Run the project", Example: "gocar run"},
 	{Name: "clean", Usage: "clean", Description: "Clean build artifacts", Example: "gocar clean"},
 	{Name: "fmt", Usage: "fmt [packages...]", Description: "Format Go code", Example: "gocar fmt"},
-	{Name: "vet", Usage: "vet [packages...]", Description: "Run go vet", Example: "gocar vet"},
+	{Name: "vet", Usage: "vet [OPTIONS] [packages...]", Description: "Run go vet", Example: "gocar vet"},
 	{Name: "test", Usage: "test [OPTIONS] [packages...]", Description: "Run tests", Example: "gocar test --coverage"},
 	{Name: "check", Usage: "check [OPTIONS]", Description: "Run vet and tests", Example: "gocar check"},
+	{Name: "package", Usage: "package [OPTIONS]", Description: "Build and package distributable archives", Example: "gocar package --release --all-common"},
+	{Name: "image", Usage: "image [OPTIONS]", Description: "Build an OCI container image without Docker", Example: "gocar image --targets linux/amd64,linux/arm64"},
+	{Name: "bloat", Usage: "bloat [OPTIONS]", Description: "Show binary size by package, module or symbol", Example: "gocar bloat --release"},
+	{Name: "pgo", Usage: "pgo collect --bench <pattern>", Description: "Collect a PGO profile from benchmarks", Example: "gocar pgo collect --bench ."},
+	{Name: "cov", Usage: "cov <subcommand> <input>...", Description: "Merge and report coverage data", Example: "gocar cov report coverage/unit coverage/e2e"},
+	{Name: "install", Usage: "install [OPTIONS]", Description: "Build and install binaries into GOBIN", Example: "gocar install --bin api"},
+	{Name: "uninstall", Usage: "uninstall [names...]", Description: "Remove binaries installed by gocar install", Example: "gocar uninstall"},
+	{Name: "targets", Usage: "targets [OPTIONS]", Description: "List supported build targets", Example: "gocar targets --first-class"},
 	{Name: "add", Usage: "add <package>...", Description: "Add dependencies to go.mod", Example: "gocar add github.com/gin-gonic/gin"},
 	{Name: "update", Usage: "update [package]...", Description: "Update dependencies", Example: "gocar update"},
 	{Name: "tidy", Usage: "tidy", Description: "Tidy up go.mod and go.sum", Example: "gocar tidy"},
 	{Name: "commands", Usage: "commands", Description: "List built-in and custom commands", Example: "gocar commands"},
 	{Name: "doctor", Usage: "doctor", Description: "Check project and toolchain setup", Example: "gocar doctor"},
+	{Name: "completion", Usage: "completion <shell>", Description: "Generate shell completion scripts", Example: "gocar completion bash"},
 	{Name: "help", Usage: "help", Description: "Print this help message", Example: "gocar help"},
 	{Name: "version", Usage: "version", Description: "Print version info", Example: "gocar version"},
 }
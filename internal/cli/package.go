@@ -0,0 +1,363 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gocar/internal/build"
+	"gocar/internal/config"
+	"gocar/internal/dist"
+)
+
+// PackageCommand package 命令
+type PackageCommand struct{}
+
+// Run 执行 package 命令
+func (c *PackageCommand) Run(args []string) error {
+	opts := newBuildOptions()
+	formats := []string{}
+
+	for i := 0; i < len(args); {
+		next, ok, err := opts.parse(args, i)
+		if err != nil {
+			return err
+		}
+		if ok {
+			i = next
+			continue
+		}
+
+		switch args[i] {
+		case "help", "--help", "-h":
+			fmt.Print(c.Help())
+			return nil
+		case "--format":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--format requires a value")
+			}
+			for _, format := range strings.Split(args[i+1], ",") {
+				format = strings.TrimSpace(format)
+				if !slices.Contains(dist.LinuxFormats, format) {
+					return fmt.Errorf("invalid --format %q (expected: %s)", format, strings.Join(dist.LinuxFormats, ", "))
+				}
+				if !slices.Contains(formats, format) {
+					formats = append(formats, format)
+				}
+			}
+			i += 2
+		default:
+			return fmt.Errorf("unknown option '%s' (run 'gocar package --help' for usage)", args[i])
+		}
+	}
+
+	ctx, err := loadBuildContext()
+	if err != nil {
+		return err
+	}
+
+	builders, err := opts.builders(ctx)
+	if err != nil {
+		return err
+	}
+	if len(formats) > 0 {
+		if builders, err = c.linuxBuilders(ctx, builders); err != nil {
+			return err
+		}
+	}
+
+	hookEnv := buildHookEnv(builders)
+	if err := ctx.runHook(config.HookPreBuild, hookEnv, os.Stdout); err != nil {
+		return ctx.withFailureHook("package", hookEnv, os.Stdout, err)
+	}
+	if len(formats) > 0 {
+		return ctx.withFailureHook("package", hookEnv, os.Stdout, c.packLinux(ctx, builders, formats, opts.jobs))
+	}
+	return ctx.withFailureHook("package", hookEnv, os.Stdout, c.pack(ctx, builders, opts.jobs))
+}
+
+// pack 构建产物并为每个目标平台写入归档
+func (c *PackageCommand) pack(ctx *buildContext, builders []*build.Builder, jobs int) error {
+	// 构建产物（指纹未变化时跳过）
+	results, err := runBuilds(builders, jobs)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+
+	outputRoot := ctx.cfg.GetPackageOutputRoot()
+	if !filepath.IsAbs(outputRoot) {
+		outputRoot = filepath.Join(ctx.projectRoot, outputRoot)
+	}
+
+	archives := []string{}
+	for _, group := range groupResultsByTarget(results) {
+		targetOS, targetArch, variant, _ := build.ParseTarget(group[0].Target)
+		baseName := dist.ArchiveBaseName(ctx.appName, ctx.cfg.GetVersion(ctx.projectRoot), targetOS, targetArch, variant)
+		archivePath := filepath.Join(outputRoot, baseName+dist.ArchiveExt(targetOS))
+
+		files := []dist.File{}
+		for _, result := range group {
+			files = append(files, dist.File{
+				Source: filepath.Join(ctx.projectRoot, result.Artifact),
+				Name:   path.Join(baseName, filepath.Base(result.Artifact)),
+				Mode:   0755,
+			})
+			if result.Header != "" {
+				files = append(files, dist.File{
+					Source: filepath.Join(ctx.projectRoot, result.Header),
+					Name:   path.Join(baseName, filepath.Base(result.Header)),
+					Mode:   0644,
+				})
+			}
+		}
+		includes, err := dist.CollectIncludes(ctx.projectRoot, baseName, ctx.cfg.Package.Include)
+		if err != nil {
+			return fmt.Errorf("invalid [package].include: %w", err)
+		}
+		files = append(files, includes...)
+
+		if err := dist.WriteArchive(archivePath, files); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filepath.Base(archivePath), err)
+		}
+		archives = append(archives, archivePath)
+		fmt.Printf("Packaged %s (%d files)\n", relPath(ctx.projectRoot, archivePath), len(files))
+	}
+
+	if err := dist.WriteChecksums(outputRoot, archives); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dist.ChecksumsFileName, err)
+	}
+	fmt.Printf("Wrote %s\n", relPath(ctx.projectRoot, filepath.Join(outputRoot, dist.ChecksumsFileName)))
+	return nil
+}
+
+// linuxBuilders 检查 Linux 软件包的前提条件，只保留 linux 目标的构建器
+func (c *PackageCommand) linuxBuilders(ctx *buildContext, builders []*build.Builder) ([]*build.Builder, error) {
+	if ctx.cfg.GetVersion(ctx.projectRoot) == "" {
+		return nil, fmt.Errorf("linux packages require a version; set [project].version in .gocar.toml")
+	}
+	linux := []*build.Builder{}
+	for _, builder := range builders {
+		if !strings.HasPrefix(builder.Target(), "linux/") {
+			fmt.Printf("Skipping %s: deb, rpm and apk packages are linux-only\n", builder.Target())
+			continue
+		}
+		if !builder.Executable() {
+			return nil, fmt.Errorf("cannot package %s: the profile builds a library, not an executable", builder.Name())
+		}
+		linux = append(linux, builder)
+	}
+	if len(linux) == 0 {
+		return nil, fmt.Errorf("no linux targets to package (use --target linux/<arch>)")
+	}
+	return linux, nil
+}
+
+// checkLinuxPackageNames 检查各目标的软件包不会重名：发行版的架构名不区分部分子版本
+// (如 arm/v6 与 arm/v7 在 deb 中都是 armhf，amd64/v3 与 amd64 相同)，重名的包会相互覆盖
+func checkLinuxPackageNames(builders []*build.Builder, formats []string) error {
+	seen := map[string]string{}
+	for _, builder := range builders {
+		target := builder.Target()
+		_, targetArch, variant, _ := build.ParseTarget(target)
+		for _, format := range formats {
+			arch, err := dist.LinuxArch(format, targetArch, variant)
+			if err != nil {
+				return err
+			}
+			key := format + " " + arch
+			if other, ok := seen[key]; ok && other != target {
+				return fmt.Errorf("%s and %s would both be packaged as %s %s; package them in separate runs", other, target, format, arch)
+			}
+			seen[key] = target
+		}
+	}
+	return nil
+}
+
+// packLinux 构建产物并为每个 linux 目标写入指定格式的软件包
+func (c *PackageCommand) packLinux(ctx *buildContext, builders []*build.Builder, formats []string, jobs int) error {
+	if err := checkLinuxPackageNames(builders, formats); err != nil {
+		return err
+	}
+	results, err := runBuilds(builders, jobs)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+
+	linux := ctx.cfg.Package.Linux
+	outputRoot := ctx.cfg.GetPackageOutputRoot()
+	if !filepath.IsAbs(outputRoot) {
+		outputRoot = filepath.Join(ctx.projectRoot, outputRoot)
+	}
+	projectPath := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(ctx.projectRoot, p)
+	}
+
+	// 各目标共用的附加文件与脚本
+	extraFiles := []dist.PackageFile{}
+	for _, file := range linux.Files {
+		mode, err := config.ParseFileMode(file.Mode)
+		if err != nil {
+			return err
+		}
+		extraFiles = append(extraFiles, dist.PackageFile{Source: projectPath(file.Src), Dest: file.Dst, Mode: mode, Config: file.Config})
+	}
+	scripts := dist.PackageScripts{}
+	for _, script := range []struct {
+		path    string
+		content *string
+	}{
+		{linux.Scripts.PreInstall, &scripts.PreInstall},
+		{linux.Scripts.PostInstall, &scripts.PostInstall},
+		{linux.Scripts.PreRemove, &scripts.PreRemove},
+		{linux.Scripts.PostRemove, &scripts.PostRemove},
+	} {
+		if script.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(projectPath(script.path))
+		if err != nil {
+			return fmt.Errorf("invalid [package.linux.scripts]: %w", err)
+		}
+		*script.content = string(data)
+	}
+
+	// 构建结果与构建器按下标对应，按目标平台分组
+	targets := []string{}
+	binaries := map[string][]dist.PackageFile{}
+	for i, result := range results {
+		if _, ok := binaries[result.Target]; !ok {
+			targets = append(targets, result.Target)
+		}
+		binaries[result.Target] = append(binaries[result.Target], dist.PackageFile{
+			Source: filepath.Join(ctx.projectRoot, result.Artifact),
+			Dest:   path.Join(ctx.cfg.GetLinuxBinDir(), builders[i].Name()),
+			Mode:   0755,
+		})
+	}
+
+	packages := []string{}
+	for _, target := range targets {
+		_, targetArch, variant, _ := build.ParseTarget(target)
+		for _, format := range formats {
+			pkg := &dist.LinuxPackage{
+				Name:        ctx.cfg.GetLinuxPackageName(ctx.appName),
+				Version:     ctx.cfg.GetVersion(ctx.projectRoot),
+				Release:     ctx.cfg.GetLinuxPackageRelease(),
+				Arch:        targetArch,
+				Variant:     variant,
+				Maintainer:  linux.Maintainer,
+				Description: linux.Description,
+				Homepage:    linux.Homepage,
+				License:     linux.License,
+				Vendor:      linux.Vendor,
+				Section:     linux.Section,
+				Priority:    linux.Priority,
+				Depends:     ctx.cfg.GetLinuxDepends(format),
+				Conflicts:   linux.Conflicts,
+				Provides:    linux.Provides,
+				Replaces:    linux.Replaces,
+				Files:       append(slices.Clone(binaries[target]), extraFiles...),
+				Scripts:     scripts,
+				ModTime:     build.SourceDate(ctx.projectRoot),
+			}
+			name, err := pkg.FileName(format)
+			if err != nil {
+				return err
+			}
+			packagePath := filepath.Join(outputRoot, name)
+			if err := dist.WriteLinuxPackage(format, packagePath, pkg); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+			packages = append(packages, packagePath)
+			fmt.Printf("Packaged %s (%d files)\n", relPath(ctx.projectRoot, packagePath), len(pkg.Files))
+		}
+	}
+
+	if err := dist.WriteChecksums(outputRoot, packages); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dist.ChecksumsFileName, err)
+	}
+	fmt.Printf("Wrote %s\n", relPath(ctx.projectRoot, filepath.Join(outputRoot, dist.ChecksumsFileName)))
+	return nil
+}
+
+// groupResultsByTarget 按目标平台分组构建结果，保持首次出现的顺序
+func groupResultsByTarget(results []*build.Result) [][]*build.Result {
+	index := map[string]int{}
+	groups := [][]*build.Result{}
+	for _, result := range results {
+		i, ok := index[result.Target]
+		if !ok {
+			i = len(groups)
+			index[result.Target] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], result)
+	}
+	return groups
+}
+
+// relPath 返回相对项目根目录的路径，失败时返回原路径
+func relPath(projectRoot, path string) string {
+	rel, err := filepath.Rel(projectRoot, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// Help 返回帮助信息
+func (c *PackageCommand) Help() string {
+	return `gocar package - Build and package distributable archives
+
+USAGE:
+    gocar package [OPTIONS]
+
+OPTIONS:
+    --release              Package release builds
+    --profile <name>       Package builds of a named profile
+    --bin <name>           Package only the named binary
+    --all-bins             Package every binary
+    --target <os>/<arch>[/<variant>]
+                           Package for a target platform
+    --targets <list>       Package several comma-separated targets
+    --all-common           Package all common targets
+    -j, --jobs <n>         Maximum number of parallel target builds
+    --with-cgo             Force enable CGO (sets CGO_ENABLED=1)
+    --buildmode <mode>     Build mode: exe, pie, c-shared, c-archive, plugin
+    --format <list>        Write linux packages instead of archives: comma-separated
+                           deb, rpm and apk
+    --force                Rebuild even if the build fingerprint is unchanged
+    --help                 Show this help message
+
+DESCRIPTION:
+    Builds the artifacts (skipping fresh ones) and writes one archive per target:
+    dist/<name>-<version>-<os>-<arch>[-<variant>].tar.gz, or .zip for windows targets.
+    Each archive contains the binaries and the files listed in [package].include.
+    A SHA256SUMS file is written next to the archives.
+
+    With --format, writes a native package per linux target and format instead,
+    generated without dpkg, rpmbuild or abuild. The binaries are installed to
+    [package.linux].bin_dir (default /usr/bin), together with the files, config
+    files and maintainer scripts configured in [package.linux]. The version comes
+    from [project].version and is mapped to each format's rules (1.2.0-rc.1
+    becomes 1.2.0~rc.1-1 for deb and rpm, 1.2.0_rc1-r0 for apk); architectures
+    use the distribution names (amd64 is x86_64 for rpm and apk). Non-linux
+    targets are skipped. The apk packages are unsigned (install with
+    apk add --allow-untrusted).
+
+EXAMPLES:
+    gocar package --release
+    gocar package --release --targets linux/amd64,windows/amd64
+    gocar package --release --all-common
+    gocar package --release --format deb,rpm --targets linux/amd64,linux/arm64
+`
+}
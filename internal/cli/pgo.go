@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gocar/internal/util"
+)
+
+// PgoCommand pgo 命令
+type PgoCommand struct{}
+
+// benchmarkResultPattern 匹配 go test -bench 的结果行，如 "BenchmarkParse-8   1000   1234 ns/op"
+var benchmarkResultPattern = regexp.MustCompile(`(?m)^Benchmark\S*\s+\d+\s`)
+
+// pgoStaleAge profile 超过该时长未更新时 doctor 给出警告
+const pgoStaleAge = 30 * 24 * time.Hour
+
+// Run 执行 pgo 命令
+func (c *PgoCommand) Run(args []string) error {
+	if len(args) == 0 {
+		fmt.Print(c.Help())
+		return nil
+	}
+	switch args[0] {
+	case "help", "--help", "-h":
+		fmt.Print(c.Help())
+		return nil
+	case "collect":
+		return c.collect(args[1:])
+	default:
+		return fmt.Errorf("unknown pgo subcommand '%s' (run 'gocar pgo --help' for usage)", args[0])
+	}
+}
+
+// collect 运行基准测试采集 CPU profile，合并后写入入口目录的 default.pgo
+func (c *PgoCommand) collect(args []string) error {
+	bench := ""
+	benchtime := ""
+	count := 0
+	output := ""
+	binName := ""
+	var pkgs []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch arg {
+		case "help", "--help", "-h":
+			fmt.Print(c.Help())
+			return nil
+		case "--bench":
+			bench, err = value()
+		case "--pkg":
+			var pkg string
+			pkg, err = value()
+			pkgs = append(pkgs, pkg)
+		case "--benchtime":
+			benchtime, err = value()
+		case "--count":
+			var raw string
+			if raw, err = value(); err == nil {
+				count, err = strconv.Atoi(raw)
+				if err != nil || count <= 0 {
+					err = fmt.Errorf("invalid --count value %q: expected a positive integer", raw)
+				}
+			}
+		case "--output", "-o":
+			output, err = value()
+		case "--bin":
+			binName, err = value()
+		default:
+			return fmt.Errorf("unknown option '%s' (run 'gocar pgo --help' for usage)", arg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if bench == "" {
+		return fmt.Errorf("--bench is required (e.g. --bench .)")
+	}
+	if len(pkgs) == 0 {
+		pkgs = []string{"./..."}
+	}
+
+	ctx, err := loadBuildContext()
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		// default.pgo 的位置只取决于入口，与 [build].targets 无关
+		opts := newBuildOptions()
+		opts.binName = binName
+		opts.hostOnly = true
+		builders, err := opts.builders(ctx)
+		if err != nil {
+			return err
+		}
+		if len(builders) != 1 {
+			return fmt.Errorf("multiple binaries found; select the one to optimize with --bin or pass --output")
+		}
+		output = builders[0].DefaultPGOPath()
+	} else if binName != "" {
+		return fmt.Errorf("--bin and --output cannot be used together")
+	}
+
+	packages, err := listTestPackages(ctx.projectRoot, pkgs)
+	if err != nil {
+		return err
+	}
+	if len(packages) == 0 {
+		return fmt.Errorf("no packages with tests match %s", strings.Join(pkgs, " "))
+	}
+
+	tempDir, err := os.MkdirTemp("", "gocar-pgo-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	// -cpuprofile 只能用于单个包，因此逐个包运行基准测试
+	var profiles []string
+	for i, pkg := range packages {
+		profile := filepath.Join(tempDir, fmt.Sprintf("%d.pprof", i))
+		testArgs := []string{"test", "-run", "^$", "-bench", bench, "-cpuprofile", profile,
+			"-o", filepath.Join(tempDir, fmt.Sprintf("%d.test", i))}
+		if benchtime != "" {
+			testArgs = append(testArgs, "-benchtime", benchtime)
+		}
+		if count > 0 {
+			testArgs = append(testArgs, "-count", strconv.Itoa(count))
+		}
+		testArgs = append(testArgs, pkg)
+
+		fmt.Printf("Benchmarking %s\n", pkg)
+		var captured bytes.Buffer
+		cmd := exec.Command("go", testArgs...)
+		cmd.Dir = ctx.projectRoot
+		cmd.Stdout = io.MultiWriter(os.Stdout, &captured)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("benchmarks failed in %s: %w", pkg, err)
+		}
+		// 没有匹配的基准测试时 profile 只包含测试框架本身，不参与合并
+		if !benchmarkResultPattern.Match(captured.Bytes()) {
+			continue
+		}
+		if _, err := os.Stat(profile); err == nil {
+			profiles = append(profiles, profile)
+		}
+	}
+	if len(profiles) == 0 {
+		return fmt.Errorf("no benchmarks match %q", bench)
+	}
+
+	if err := mergeProfiles(ctx.projectRoot, profiles, output); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s (merged %d profile(s))\n", output, len(profiles))
+	return nil
+}
+
+// listTestPackages 列出包含测试文件的包
+func listTestPackages(projectRoot string, patterns []string) ([]string, error) {
+	args := append([]string{"list", "-f", "{{if or .TestGoFiles .XTestGoFiles}}{{.ImportPath}}{{end}}"}, patterns...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = projectRoot
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w", err)
+	}
+	var packages []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			packages = append(packages, line)
+		}
+	}
+	return packages, nil
+}
+
+// mergeProfiles 使用 go tool pprof -proto 合并 profile，写入临时文件后重命名以避免留下半写入的 default.pgo
+func mergeProfiles(projectRoot string, profiles []string, output string) error {
+	path := output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectRoot, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".default-*.pgo")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", append([]string{"tool", "pprof", "-proto"}, profiles...)...)
+	cmd.Dir = projectRoot
+	cmd.Stdout = file
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to merge profiles: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// pgoStaleness 返回 profile 的更新时间与此后项目的提交数。
+// 已提交且未修改的 profile 以最后一次提交时间为准，否则使用文件修改时间；不在 git 仓库中时提交数为 -1。
+func pgoStaleness(projectRoot, path string) (time.Time, int, error) {
+	absPath := path
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(projectRoot, absPath)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	updated := info.ModTime()
+
+	commits := -1
+	if status, err := util.GitOutput(projectRoot, "status", "--porcelain", "--", absPath); err == nil && status == "" {
+		if last, err := util.GitOutput(projectRoot, "log", "-1", "--format=%H %ct", "--", absPath); err == nil && last != "" {
+			hash, timestamp, _ := strings.Cut(last, " ")
+			if seconds, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+				updated = time.Unix(seconds, 0)
+				if count, err := util.GitOutput(projectRoot, "rev-list", "--count", hash+"..HEAD"); err == nil {
+					commits, _ = strconv.Atoi(count)
+				}
+				return updated, commits, nil
+			}
+		}
+	}
+	if count, err := util.GitOutput(projectRoot, "rev-list", "--count", "--since="+updated.Format(time.RFC3339), "HEAD"); err == nil {
+		commits, _ = strconv.Atoi(count)
+	}
+	return updated, commits, nil
+}
+
+// formatAge 将时长格式化为 "3 days" 这样的近似值
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return "less than an hour"
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	}
+}
+
+// Help 返回帮助信息
+func (c *PgoCommand) Help() string {
+	return `gocar pgo - Profile-guided optimization workflow
+
+USAGE:
+    gocar pgo collect --bench <pattern> [OPTIONS]
+
+SUBCOMMANDS:
+    collect               Run benchmarks with -cpuprofile and merge the
+                          profiles into the entry's default.pgo
+
+OPTIONS:
+    --bench <pattern>     Benchmarks to run (go test -bench), required
+    --pkg <pattern>       Packages to benchmark, repeatable (default: ./...)
+    --benchtime <d>       Passed to go test -benchtime
+    --count <n>           Passed to go test -count
+    --bin <name>          Binary whose entry receives default.pgo
+    -o, --output <path>   Write the merged profile to <path> instead
+
+DESCRIPTION:
+    Benchmarks run one package at a time because -cpuprofile only supports
+    a single package. Packages without matching benchmarks are skipped and
+    the remaining profiles are merged with 'go tool pprof -proto'.
+
+    go build picks up <entry>/default.pgo automatically (pgo = "auto").
+    Set pgo = "off" or pgo = "<path>" in a [profile.*] section to disable
+    PGO or use another profile. 'gocar doctor' reports how stale it is.
+
+EXAMPLES:
+    gocar pgo collect --bench .
+    gocar pgo collect --bench 'BenchmarkHandler' --pkg ./internal/server
+    gocar pgo collect --bench . --bin api --benchtime 5s
+`
+}
@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 
+	"gocar/internal/build"
 	"gocar/internal/config"
 	"gocar/internal/project"
 )
@@ -15,6 +17,11 @@ type RunCommand struct{}
 
 // Run 执行 run 命令
 func (c *RunCommand) Run(args []string) error {
+	binName, explain, args, err := c.parseArgs(args)
+	if err != nil {
+		return err
+	}
+
 	// Get project info
 	projectRoot, appName, _, err := project.DetectProject()
 	if err != nil {
@@ -31,13 +38,14 @@ func (c *RunCommand) Run(args []string) error {
 	appName = cfg.GetProjectName(appName)
 
 	// Get entry from config
-	sourcePath := cfg.GetRunEntryForApp(appName)
+	sourcePath, appName, entryOrigin, err := resolveRunEntry(cfg, projectRoot, appName, binName)
+	if err != nil {
+		return err
+	}
 	if sourcePath != "." && !filepath.IsAbs(sourcePath) && len(sourcePath) > 0 && sourcePath[0] != '.' {
 		sourcePath = "./" + sourcePath
 	}
 
-	fmt.Printf("Running %s...\n\n", appName)
-
 	runArgs := []string{"run", sourcePath}
 
 	// Add default args from config
@@ -48,6 +56,36 @@ func (c *RunCommand) Run(args []string) error {
 	// Add command line args
 	runArgs = append(runArgs, args...)
 
+	if explain != explainOff {
+		settings := []build.Setting{{Name: "entry", Value: sourcePath, Source: entryOrigin}}
+		if len(cfg.Run.Args) > 0 {
+			settings = append(settings, build.Setting{Name: "args", Value: shellJoin(cfg.Run.Args), Source: "[run].args"})
+		}
+		if len(args) > 0 {
+			settings = append(settings, build.Setting{Name: "args", Value: shellJoin(args), Source: "CLI args"})
+		}
+		printPlan(os.Stdout, &build.Plan{
+			Title:    "run " + appName,
+			Args:     append([]string{"go"}, runArgs...),
+			Dir:      projectRoot,
+			Settings: settings,
+		})
+		if explain == explainDryRun {
+			return nil
+		}
+	}
+
+	ctx := &buildContext{projectRoot: projectRoot, appName: appName, cfg: cfg}
+	hookEnv := map[string]string{
+		"GOCAR_BIN":    appName,
+		"GOCAR_TARGET": runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if err := ctx.runHook(config.HookPreRun, hookEnv, os.Stdout); err != nil {
+		return ctx.withFailureHook("run", hookEnv, os.Stdout, err)
+	}
+
+	fmt.Printf("Running %s...\n\n", appName)
+
 	cmd := exec.Command("go", runArgs...)
 	cmd.Dir = projectRoot
 	cmd.Stdout = os.Stdout
@@ -57,23 +95,91 @@ func (c *RunCommand) Run(args []string) error {
 	if err := cmd.Run(); err != nil {
 		// Preserve subprocess exit code so main can exit consistently.
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			return WithExitCode(fmt.Errorf("application exited with code %d", exitErr.ExitCode()), exitErr.ExitCode())
+			err = WithExitCode(fmt.Errorf("application exited with code %d", exitErr.ExitCode()), exitErr.ExitCode())
+		} else {
+			err = fmt.Errorf("run failed: %w", err)
 		}
-		return fmt.Errorf("run failed: %w", err)
+		return ctx.withFailureHook("run", hookEnv, os.Stdout, err)
 	}
 
 	return nil
 }
 
+// parseArgs 解析 gocar 自身的前置参数，其余参数原样传给应用
+func (c *RunCommand) parseArgs(args []string) (binName string, explain explainMode, rest []string, err error) {
+	for len(args) > 0 {
+		if mode, ok := parseExplainFlag(args[0]); ok {
+			explain = mode
+			args = args[1:]
+			continue
+		}
+		switch args[0] {
+		case "--bin":
+			if len(args) < 2 {
+				return "", explainOff, nil, fmt.Errorf("--bin requires a value")
+			}
+			binName = args[1]
+			args = args[2:]
+		case "--":
+			return binName, explain, args[1:], nil
+		default:
+			return binName, explain, args, nil
+		}
+	}
+	return binName, explain, args, nil
+}
+
+// resolveRunEntry 确定要运行的入口与应用名称，并返回入口的来源 (用于 --dry-run 标注)
+func resolveRunEntry(cfg *config.GocarConfig, projectRoot, appName, binName string) (string, string, string, error) {
+	if binName != "" {
+		bin, ok := cfg.GetBin(projectRoot, binName)
+		if !ok {
+			return "", "", "", fmt.Errorf("unknown binary %q (available: %v)", binName, cfg.ListBins(projectRoot))
+		}
+		return bin.Entry, bin.Name, build.CLIOrigin("--bin"), nil
+	}
+	if cfg.Run.Entry == "" && len(cfg.Bins) > 0 {
+		bins := cfg.GetBins(projectRoot)
+		if len(bins) > 1 {
+			return "", "", "", fmt.Errorf("could not determine which binary to run; use --bin <name> (available: %v)", cfg.ListBins(projectRoot))
+		}
+		return bins[0].Entry, bins[0].Name, "[[bin]] " + bins[0].Name, nil
+	}
+	origin := build.OriginDefault
+	if cfg.Run.Entry != "" {
+		origin = "[run].entry"
+	} else if cfg.Build.Entry != "" {
+		origin = "[build].entry"
+	}
+	return cfg.GetRunEntryForApp(appName), appName, origin, nil
+}
+
 // Help 返回帮助信息
 func (c *RunCommand) Help() string {
 	return `gocar run - Run the project
 
 USAGE:
-    gocar run [args...]
+    gocar run [--bin <name>] [--dry-run | --explain] [--] [args...]
+
+OPTIONS:
+    --bin <name>    Run the named binary ([[bin]] or cmd/<name>)
+    --dry-run       Print the resolved go run command and where the entry
+                    and arguments come from, without running anything
+    --explain       Like --dry-run, then run as usual
+    --              Stop parsing gocar options; pass the rest to the application
+
+    All other arguments are passed to the application unchanged.
+    Leading --bin, --dry-run and --explain are read by gocar; to pass one of
+    them to the application, put it after --:
+        gocar run -- --dry-run
+    With several [[bin]] entries declared, --bin is required.
+    [hooks].pre_run runs first and aborts the run when it fails.
 
 EXAMPLES:
     gocar run                Run the project
+    gocar run --bin worker   Run cmd/worker
+    gocar run --dry-run      Show the go run command without running it
     gocar run --help         Pass --help to the application
+    gocar run -- --dry-run   Pass --dry-run to the application
 `
 }
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"gocar/internal/build"
+)
+
+// TargetsCommand targets 命令
+type TargetsCommand struct{}
+
+// Run 执行 targets 命令
+func (c *TargetsCommand) Run(args []string) error {
+	firstClassOnly := false
+	commonOnly := false
+	for _, arg := range args {
+		switch arg {
+		case "help", "--help", "-h":
+			fmt.Print(c.Help())
+			return nil
+		case "--first-class":
+			firstClassOnly = true
+		case "--common":
+			commonOnly = true
+		default:
+			return fmt.Errorf("unknown option '%s' (run 'gocar targets --help' for usage)", arg)
+		}
+	}
+
+	platforms, err := build.LoadPlatforms("")
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tFIRST-CLASS\tCGO\tCOMMON")
+	for _, p := range platforms {
+		if (firstClassOnly && !p.FirstClass) || (commonOnly && !p.IsCommon()) {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p, yesNo(p.FirstClass), yesNo(p.CgoSupported), yesNo(p.IsCommon()))
+	}
+	return tw.Flush()
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "-"
+}
+
+// Help 返回帮助信息
+func (c *TargetsCommand) Help() string {
+	return `gocar targets - List supported build targets
+
+USAGE:
+    gocar targets [OPTIONS]
+
+OPTIONS:
+    --first-class   Only list first-class ports
+    --common        Only list common targets (used by --all-common)
+    --help          Show this help message
+
+DESCRIPTION:
+    Lists the <os>/<arch> pairs supported by the current Go toolchain
+    ('go tool dist list -json'), whether they are first-class ports, whether
+    they support cgo, and whether they belong to the common target set.
+    The list is cached per Go version in the user cache directory.
+
+EXAMPLES:
+    gocar targets
+    gocar targets --first-class
+`
+}
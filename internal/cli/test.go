@@ -2,9 +2,16 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 
-	"gocar/internal/project"
-	"gocar/internal/util"
+	"gocar/internal/build"
+	"gocar/internal/config"
+	"gocar/internal/diag"
 )
 
 // TestCommand test 命令
@@ -12,12 +19,12 @@ type TestCommand struct{}
 
 // Run 执行 test 命令
 func (c *TestCommand) Run(args []string) error {
-	projectRoot, appName, _, err := project.DetectProject()
+	messageFormat, args, err := extractMessageFormat(args)
 	if err != nil {
-		return fmt.Errorf("%w", err)
+		return err
 	}
-
-	testArgs, err := c.parseArgs(args)
+	explain, args := extractExplainMode(args)
+	testArgs, settings, err := c.parseArgs(args)
 	if err != nil {
 		return err
 	}
@@ -26,20 +33,87 @@ func (c *TestCommand) Run(args []string) error {
 		return nil
 	}
 
-	fmt.Printf("Testing '%s'...\n", appName)
-	if err := util.RunCommand(projectRoot, "go", testArgs...); err != nil {
-		return fmt.Errorf("tests failed: %w", err)
+	ctx, err := loadBuildContext()
+	if err != nil {
+		return err
+	}
+
+	if explain != explainOff {
+		planOutput := io.Writer(os.Stdout)
+		if explain == explainRun && messageFormat != build.MessageFormatHuman {
+			planOutput = os.Stderr
+		}
+		printPlan(planOutput, &build.Plan{
+			Title:    "test " + ctx.appName,
+			Args:     append([]string{"go"}, testArgs...),
+			Dir:      ctx.projectRoot,
+			Settings: settings,
+		})
+		if explain == explainDryRun {
+			return nil
+		}
+	}
+
+	// json/sarif 模式下 stdout 只输出诊断，钩子输出写入 stderr
+	human := messageFormat == build.MessageFormatHuman
+	hookOutput := io.Writer(os.Stdout)
+	if !human {
+		hookOutput = os.Stderr
+	}
+
+	hookEnv := map[string]string{"GOCAR_TARGET": runtime.GOOS + "/" + runtime.GOARCH}
+	if err := ctx.runHook(config.HookPreTest, hookEnv, hookOutput); err != nil {
+		return ctx.withFailureHook("test", hookEnv, hookOutput, err)
+	}
+
+	for _, arg := range testArgs {
+		if dir, ok := strings.CutPrefix(arg, "-test.gocoverdir="); ok {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create coverage directory: %w", err)
+			}
+		}
+	}
+
+	if human {
+		fmt.Printf("Testing '%s'...\n", ctx.appName)
+	}
+	output, diagnostics, testErr := runGoDiagnosed(ctx.projectRoot, diag.ToolTest, testArgs)
+	if human {
+		fmt.Print(string(output))
+		if testErr != nil {
+			printTestFailures(os.Stdout, ctx.projectRoot, diagnostics)
+		}
+	} else if err := writeDiagnostics(os.Stdout, messageFormat, ctx.projectRoot, diagnostics); err != nil {
+		return err
+	}
+	if testErr != nil {
+		return ctx.withFailureHook("test", hookEnv, hookOutput, fmt.Errorf("tests failed: %w", testErr))
 	}
 
-	fmt.Println("Tests passed")
+	if human {
+		fmt.Println("Tests passed")
+	}
+	if err := ctx.runHook(config.HookPostTest, hookEnv, hookOutput); err != nil {
+		return ctx.withFailureHook("test", hookEnv, hookOutput, err)
+	}
 	return nil
 }
 
-func (c *TestCommand) parseArgs(args []string) ([]string, error) {
+// parseArgs 解析 gocar test 参数，返回 go test 参数以及各参数的来源 (用于 --dry-run 标注)。
+// 请求帮助时返回 nil。
+func (c *TestCommand) parseArgs(args []string) ([]string, []build.Setting, error) {
 	testArgs := []string{"test"}
+	settings := []build.Setting{}
 	packages := []string{}
 	passThrough := []string{}
 	forwardingGoTestArgs := false
+	coverDir := ""
+	cover := func(flag string) {
+		if !slices.Contains(testArgs, "-cover") {
+			testArgs = append(testArgs, "-cover")
+			settings = append(settings, build.Setting{Name: "-cover", Source: build.CLIOrigin(flag)})
+		}
+	}
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
@@ -49,16 +123,29 @@ func (c *TestCommand) parseArgs(args []string) ([]string, error) {
 		}
 		switch arg {
 		case "help", "--help", "-h":
-			return nil, nil
+			return nil, nil, nil
 		case "--coverage":
-			testArgs = append(testArgs, "-cover")
+			cover(arg)
+		case "--coverdir":
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("--coverdir requires a value")
+			}
+			dir, err := filepath.Abs(args[i+1])
+			if err != nil {
+				return nil, nil, err
+			}
+			coverDir = dir
+			cover(arg)
+			i++
 		case "--race":
 			testArgs = append(testArgs, "-race")
+			settings = append(settings, build.Setting{Name: "-race", Source: build.CLIOrigin(arg)})
 		case "--bench":
 			if i+1 >= len(args) {
-				return nil, fmt.Errorf("--bench requires a value")
+				return nil, nil, fmt.Errorf("--bench requires a value")
 			}
 			testArgs = append(testArgs, "-bench", args[i+1])
+			settings = append(settings, build.Setting{Name: "-bench", Value: args[i+1], Source: build.CLIOrigin(arg)})
 			i++
 		case "--":
 			passThrough = append(passThrough, args[i+1:]...)
@@ -75,11 +162,26 @@ func (c *TestCommand) parseArgs(args []string) ([]string, error) {
 
 	if len(packages) == 0 {
 		packages = append(packages, "./...")
+		settings = append(settings, build.Setting{Name: "packages", Value: "./...", Source: build.OriginDefault})
+	} else {
+		settings = append(settings, build.Setting{Name: "packages", Value: strings.Join(packages, " "), Source: "CLI args"})
+	}
+	if len(passThrough) > 0 {
+		settings = append(settings, build.Setting{Name: "go test args", Value: shellJoin(passThrough), Source: "CLI args"})
 	}
 
 	testArgs = append(testArgs, packages...)
 	testArgs = append(testArgs, passThrough...)
-	return testArgs, nil
+
+	// 测试二进制在包目录中运行，GOCOVERDIR 需要绝对路径；通过 -args 传给每个测试二进制
+	if coverDir != "" {
+		if !slices.Contains(passThrough, "-args") {
+			testArgs = append(testArgs, "-args")
+		}
+		testArgs = append(testArgs, "-test.gocoverdir="+coverDir)
+		settings = append(settings, build.Setting{Name: "-test.gocoverdir", Value: coverDir, Source: build.CLIOrigin("--coverdir")})
+	}
+	return testArgs, settings, nil
 }
 
 // Help 返回帮助信息
@@ -91,16 +193,37 @@ USAGE:
 
 OPTIONS:
     --coverage          Run tests with coverage (-cover)
+    --coverdir <dir>    Write coverage data to <dir> in GOCOVERDIR format
+                        (implies --coverage); combine it with integration
+                        coverage using 'gocar cov'
     --race              Enable the race detector
     --bench <pattern>   Run benchmarks matching pattern
+    --message-format <fmt>
+                        Output format: human (default), json (one
+                        compiler-diagnostic event per line) or sarif
+    --dry-run           Print the resolved go test command and where each
+                        argument comes from, without running tests or hooks
+    --explain           Like --dry-run, then run the tests as usual
     --help              Show this help message
 
+DIAGNOSTICS:
+    When tests fail, failing assertions and compile errors are listed after
+    the go test output with the source line they point at. With json or
+    sarif only the diagnostics are printed to stdout.
+
+HOOKS:
+    [hooks].pre_test runs before the tests and aborts them when it fails.
+    [hooks].post_test runs after the tests pass; on_failure runs when they fail.
+
 EXAMPLES:
     gocar test                      Run all tests
     gocar test ./internal/...       Run tests for selected packages
     gocar test --coverage           Run all tests with coverage
+    gocar test --coverdir coverage/unit
+                                    Keep coverage data for 'gocar cov'
     gocar test --bench .            Run all benchmarks
     gocar test -run TestConfig      Pass extra arguments to go test
     gocar test -- -run TestConfig   Explicitly separate gocar and go test args
+    gocar test --message-format sarif > test.sarif
 `
 }
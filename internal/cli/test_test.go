@@ -3,6 +3,8 @@ package cli
 import (
 	"reflect"
 	"testing"
+
+	"gocar/internal/build"
 )
 
 func TestTestCommandParseArgs(t *testing.T) {
@@ -32,6 +34,16 @@ func TestTestCommandParseArgs(t *testing.T) {
 			args: []string{"-run", "TestConfig", "./internal/..."},
 			want: []string{"test", "./...", "-run", "TestConfig", "./internal/..."},
 		},
+		{
+			name: "coverdir is passed to every test binary",
+			args: []string{"--coverage", "--coverdir", "/tmp/cov", "./internal/...", "-run", "TestConfig"},
+			want: []string{"test", "-cover", "./internal/...", "-run", "TestConfig", "-args", "-test.gocoverdir=/tmp/cov"},
+		},
+		{
+			name: "coverdir after explicit -args",
+			args: []string{"--coverdir", "/tmp/cov", "--", "-args", "-v"},
+			want: []string{"test", "-cover", "./...", "-args", "-v", "-test.gocoverdir=/tmp/cov"},
+		},
 		{
 			name: "race and bench",
 			args: []string{"--race", "--bench", "."},
@@ -41,7 +53,7 @@ func TestTestCommandParseArgs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := cmd.parseArgs(tt.args)
+			got, _, err := cmd.parseArgs(tt.args)
 			if err != nil {
 				t.Fatalf("parseArgs() unexpected error: %v", err)
 			}
@@ -53,7 +65,7 @@ func TestTestCommandParseArgs(t *testing.T) {
 }
 
 func TestTestCommandParseArgsHelp(t *testing.T) {
-	got, err := (&TestCommand{}).parseArgs([]string{"--help"})
+	got, _, err := (&TestCommand{}).parseArgs([]string{"--help"})
 	if err != nil {
 		t.Fatalf("parseArgs() unexpected error: %v", err)
 	}
@@ -63,7 +75,26 @@ func TestTestCommandParseArgsHelp(t *testing.T) {
 }
 
 func TestTestCommandParseArgsBenchRequiresValue(t *testing.T) {
-	if _, err := (&TestCommand{}).parseArgs([]string{"--bench"}); err == nil {
+	if _, _, err := (&TestCommand{}).parseArgs([]string{"--bench"}); err == nil {
 		t.Fatal("expected --bench without value to fail")
 	}
 }
+
+func TestTestCommandParseArgsSources(t *testing.T) {
+	_, settings, err := (&TestCommand{}).parseArgs([]string{"--coverage", "-run", "TestConfig"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []build.Setting{
+		{Name: "-cover", Source: "CLI flag (--coverage)"},
+		{Name: "packages", Value: "./...", Source: build.OriginDefault},
+		{Name: "go test args", Value: "-run TestConfig", Source: "CLI args"},
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Fatalf("parseArgs() settings = %+v, want %+v", settings, want)
+	}
+
+	if mode, rest := extractExplainMode([]string{"--dry-run", "./...", "--", "--explain"}); mode != explainDryRun || !reflect.DeepEqual(rest, []string{"./...", "--", "--explain"}) {
+		t.Fatalf("extractExplainMode() = %v, %v", mode, rest)
+	}
+}
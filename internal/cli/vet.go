@@ -2,9 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
+	"gocar/internal/build"
+	"gocar/internal/diag"
 	"gocar/internal/project"
-	"gocar/internal/util"
 )
 
 // VetCommand vet 命令
@@ -19,22 +21,37 @@ func (c *VetCommand) Run(args []string) error {
 			return nil
 		}
 	}
+	messageFormat, packages, err := extractMessageFormat(args)
+	if err != nil {
+		return err
+	}
 
 	projectRoot, appName, _, err := project.DetectProject()
 	if err != nil {
 		return fmt.Errorf("%w", err)
 	}
 
-	packages := args
 	if len(packages) == 0 {
 		packages = []string{"./..."}
 	}
 
-	fmt.Printf("Vetting '%s'...\n", appName)
-	if err := util.RunCommand(projectRoot, "go", append([]string{"vet"}, packages...)...); err != nil {
-		return fmt.Errorf("go vet failed: %w", err)
+	// json/sarif 模式下 stdout 只输出诊断
+	human := messageFormat == build.MessageFormatHuman
+	if human {
+		fmt.Printf("Vetting '%s'...\n", appName)
+	}
+	_, diagnostics, vetErr := runGoDiagnosed(projectRoot, diag.ToolVet, append([]string{"vet"}, packages...))
+	if human {
+		diag.Render(os.Stdout, projectRoot, diagnostics)
+	} else if err := writeDiagnostics(os.Stdout, messageFormat, projectRoot, diagnostics); err != nil {
+		return err
+	}
+	if vetErr != nil {
+		return fmt.Errorf("go vet failed: %w", vetErr)
+	}
+	if human {
+		fmt.Println("Vet passed")
 	}
-	fmt.Println("Vet passed")
 	return nil
 }
 
@@ -43,10 +60,20 @@ func (c *VetCommand) Help() string {
 	return `gocar vet - Run go vet
 
 USAGE:
-    gocar vet [packages...]
+    gocar vet [OPTIONS] [packages...]
+
+OPTIONS:
+    --message-format <fmt>  Output format: human (default), json (one
+                            compiler-diagnostic event per line) or sarif
+    --help                  Show this help message
+
+DESCRIPTION:
+    Findings are shown with the offending source line and a caret under
+    the reported column. json and sarif print only the diagnostics to stdout.
 
 EXAMPLES:
     gocar vet
     gocar vet ./internal/...
+    gocar vet --message-format sarif > vet.sarif
 `
 }
@@ -2,31 +2,44 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+
+	"gocar/internal/util"
 )
 
 // ConfigFileName 配置文件名
 const ConfigFileName = ".gocar.toml"
 
+// VersionFromGit project.version 取此值时从 git describe --tags 推导版本号
+const VersionFromGit = "git"
+
 // GocarConfig gocar 配置结构
 type GocarConfig struct {
 	Project  ProjectConfig     `toml:"project"`
 	Build    BuildConfig       `toml:"build"`
 	Run      RunConfig         `toml:"run"`
 	Profile  ProfilesConfig    `toml:"profile"`
+	Bins     []BinConfig       `toml:"bin"`
+	Package  PackageConfig     `toml:"package"`
+	Image    ImageConfig       `toml:"image"`
+	Windows  WindowsConfig     `toml:"windows"`
+	Hooks    HooksConfig       `toml:"hooks"`
 	Commands map[string]string `toml:"commands"`
 }
 
 // ProjectConfig 项目配置
 type ProjectConfig struct {
 	Name    string `toml:"name"`    // 项目名称，为空时使用目录名
-	Version string `toml:"version"` // 项目版本号，构建时自动注入到 main.version
+	Version string `toml:"version"` // 项目版本号，构建时自动注入到 main.version；"git" 表示从 git describe --tags 推导
 }
 
 // ProfilesConfig 构建配置档案
@@ -36,20 +49,70 @@ type ProfilesConfig struct {
 
 // ProfileConfig 单个构建档案配置
 type ProfileConfig struct {
-	Ldflags    string `toml:"ldflags"`     // ldflags 参数
-	Gcflags    string `toml:"gcflags"`     // 编译器参数
-	Trimpath   *bool  `toml:"trimpath"`    // 是否移除路径信息
-	CgoEnabled *bool  `toml:"cgo_enabled"` // 是否启用 CGO
-	Race       bool   `toml:"race"`        // 是否启用竞态检测
+	Inherits     string   `toml:"inherits"`     // 继承的 profile，本 profile 中设置的字段覆盖父 profile
+	Ldflags      string   `toml:"ldflags"`      // ldflags 参数
+	Gcflags      string   `toml:"gcflags"`      // 编译器参数
+	Asmflags     string   `toml:"asmflags"`     // 汇编器参数
+	Trimpath     *bool    `toml:"trimpath"`     // 是否移除路径信息
+	CgoEnabled   *bool    `toml:"cgo_enabled"`  // 是否启用 CGO
+	Race         *bool    `toml:"race"`         // 是否启用竞态检测
+	Msan         *bool    `toml:"msan"`         // 是否启用 MemorySanitizer
+	Asan         *bool    `toml:"asan"`         // 是否启用 AddressSanitizer
+	Cover        *bool    `toml:"cover"`        // 是否构建覆盖率插桩的二进制
+	Coverpkg     []string `toml:"coverpkg"`     // -coverpkg 包模式，设置时隐含 cover
+	Tags         []string `toml:"tags"`         // 追加到 [build].tags 之后的构建标签
+	ExtraEnv     []string `toml:"extra_env"`    // 追加到 [build].extra_env 之后的环境变量
+	Buildmode    string   `toml:"buildmode"`    // -buildmode 参数，留空使用 go build 的默认模式
+	Mod          string   `toml:"mod"`          // -mod 参数: readonly、vendor 或 mod
+	Goexperiment string   `toml:"goexperiment"` // GOEXPERIMENT 环境变量
+	Pgo          string   `toml:"pgo"`          // -pgo 参数: auto、off 或 profile 文件路径
+
+	defined map[string]bool // 配置文件中出现的键，用于区分未设置与显式设为空值 (如 ldflags = "")
+}
+
+// set 报告字段是否需要覆盖父 profile：取值非空，或在配置文件中显式设置为空值
+func (p ProfileConfig) set(key string, nonEmpty bool) bool {
+	return nonEmpty || p.defined[key]
+}
+
+// Enabled 返回可选布尔字段的值，未设置时为 false
+func Enabled(value *bool) bool {
+	return value != nil && *value
 }
 
+// 可用的 -mod 取值
+var validModFlags = []string{"readonly", "vendor", "mod"}
+
+// 可用的 -buildmode 取值
+var validBuildmodes = []string{"exe", "pie", "c-shared", "c-archive", "plugin"}
+
 // BuildConfig 构建配置
 type BuildConfig struct {
-	Entry    string   `toml:"entry"`     // 构建入口路径
-	Output   string   `toml:"output"`    // 输出目录
-	Ldflags  string   `toml:"ldflags"`   // 额外的 ldflags
-	Tags     []string `toml:"tags"`      // 构建标签
-	ExtraEnv []string `toml:"extra_env"` // 额外的环境变量
+	Entry    string            `toml:"entry"`     // 构建入口路径
+	Output   string            `toml:"output"`    // 输出目录
+	Ldflags  string            `toml:"ldflags"`   // 额外的 ldflags
+	Tags     []string          `toml:"tags"`      // 构建标签
+	ExtraEnv []string          `toml:"extra_env"` // 额外的环境变量
+	Targets  []string          `toml:"targets"`   // 默认构建目标列表 (<os>/<arch>)
+	Vars     map[string]string `toml:"vars"`      // -X 注入变量：Go 符号路径 -> 模板
+
+	ArtifactName string `toml:"artifact_name"` // 产物文件名模板，如 "{name}_{version}_{os}_{arch}{ext}"
+	ArtifactDir  string `toml:"artifact_dir"`  // 产物目录模板 (相对 output)，默认 "{profile}/{os}-{arch}[-{variant}]"
+}
+
+// BinConfig 单个二进制配置 ([[bin]])
+type BinConfig struct {
+	Name    string   `toml:"name"`    // 二进制名称，同时作为输出文件名
+	Entry   string   `toml:"entry"`   // 构建入口路径，默认为 "cmd/<name>"
+	Ldflags string   `toml:"ldflags"` // 额外的 ldflags，追加到 [build].ldflags 之后
+	Tags    []string `toml:"tags"`    // 构建标签，设置后覆盖 [build].tags
+}
+
+// PackageConfig 打包配置
+type PackageConfig struct {
+	Output  string             `toml:"output"`  // 归档输出目录，默认为 "dist"
+	Include []string           `toml:"include"` // 额外打包的文件或目录 (支持 glob，相对于项目根目录)
+	Linux   LinuxPackageConfig `toml:"linux"`   // deb/rpm/apk 软件包配置
 }
 
 // RunConfig 运行配置
@@ -73,6 +136,8 @@ func DefaultConfig() *GocarConfig {
 			Ldflags:  "",
 			Tags:     []string{},
 			ExtraEnv: []string{},
+			Targets:  []string{},
+			Vars:     map[string]string{},
 		},
 		Run: RunConfig{
 			Entry: "",
@@ -85,17 +150,22 @@ func DefaultConfig() *GocarConfig {
 					Gcflags:    "",
 					Trimpath:   &falseVal,
 					CgoEnabled: nil, // nil 表示跟随系统默认
-					Race:       false,
+					Race:       &falseVal,
 				},
 				"release": {
 					Ldflags:    "-s -w",
 					Gcflags:    "",
 					Trimpath:   &trueVal,
 					CgoEnabled: &falseVal,
-					Race:       false,
+					Race:       &falseVal,
 				},
 			},
 		},
+		Bins: []BinConfig{},
+		Package: PackageConfig{
+			Output:  "dist",
+			Include: []string{},
+		},
 		Commands: map[string]string{},
 	}
 }
@@ -115,7 +185,7 @@ func DefaultConfigTemplate(projectName string) string {
 # 项目名称，留空则使用目录名
 name = "%s"
 
-# 项目版本号
+# 项目版本号，"git" 表示从 git describe --tags 推导
 # version = "1.0.0"
 
 # 构建配置
@@ -127,6 +197,12 @@ entry = "%s"
 # 输出目录
 output = "bin"
 
+# 产物路径模板 (相对于 output)，默认为 <profile>/<os>-<arch>/<name>
+# 占位符: {name} {version} {os} {arch} {variant} {profile} {commit} {ext}
+# artifact_name 不含 {ext} 时自动追加扩展名 (如 Windows 下的 .exe)
+# artifact_dir = "."
+# artifact_name = "{name}_{version}_{os}_{arch}"
+
 # 额外的 ldflags，会追加到 profile 的 ldflags 之后
 # 例如: "-X main.version=1.0.0"
 ldflags = ""
@@ -137,6 +213,16 @@ ldflags = ""
 # 额外的环境变量
 # extra_env = ["GOPROXY=https://goproxy.cn"]
 
+# 默认构建目标，未指定 --target/--targets 时并行构建这些目标
+# targets = ["linux/amd64", "darwin/arm64", "windows/amd64"]
+
+# 通过 -X 注入的变量: Go 符号路径 = 模板
+# 可用字段: {{.Name}} {{.Version}} {{.Commit}} {{.ShortCommit}} {{.Date}} {{.Dirty}} {{.Target}} {{.OS}} {{.Arch}} {{.Variant}} {{.Profile}}
+# [build.vars]
+# "main.version" = "{{.Version}}"
+# "myapp/internal/buildinfo.Commit" = "{{.ShortCommit}}"
+# "myapp/internal/buildinfo.Date" = "{{.Date}}"
+
 # 运行配置
 [run]
 # 运行入口路径，留空则使用 build.entry
@@ -165,16 +251,113 @@ cgo_enabled = false         # 禁用 CGO 以生成静态二进制
 
 # 自定义构建配置
 # 使用: gocar build --profile ci
+# inherits 继承另一个 profile，仅覆盖此处设置的字段 (ldflags = "" 或 tags = [] 清空继承的值)
 # [profile.ci]
-# trimpath = true
+# inherits = "release"
 # race = true
 
+# profile 还支持: tags、extra_env (追加到 [build] 之后)、asmflags、buildmode、
+# mod (readonly/vendor/mod)、goexperiment、msan、asan、cover、coverpkg、pgo
+# [profile.sqlite]
+# inherits = "release"
+# tags = ["sqlite_omit_load_extension"]
+# cgo_enabled = true
+
+# PGO: 在 profile 中设置 pgo = "auto" (默认，使用入口目录下的 default.pgo)、"off" 或 profile 文件路径
+# 使用 gocar pgo collect --bench . 从基准测试生成 default.pgo
+
+# 多个二进制
+# 未声明 [[bin]] 时自动发现 cmd/*/main.go，可通过 --bin <name> / --all-bins 选择
+# 声明后 gocar build 默认构建所有声明的二进制
+# [[bin]]
+# name = "api"
+# entry = "cmd/api"
+# ldflags = "-X main.component=api"   # 追加到 [build].ldflags 之后
+# tags = ["netgo"]                    # 覆盖 [build].tags
+#
+# [[bin]]
+# name = "worker"
+
+# 打包配置
+# 使用: gocar package --release --targets linux/amd64,windows/amd64
+# [package]
+# output = "dist"                                  # 归档输出目录
+# include = ["README.md", "LICENSE", "configs/*"]  # 额外打包的文件
+#
+# Linux 软件包 (纯 Go 生成，无需 dpkg/rpmbuild/abuild)
+# 使用: gocar package --release --format deb,rpm --targets linux/amd64,linux/arm64
+# [package.linux]
+# maintainer = "Jane Doe <jane@example.com>"
+# description = """一行摘要
+# 详细描述"""
+# license = "MIT"
+# homepage = "https://example.com"
+# depends = ["ca-certificates"]       # 也可写版本约束: "libc6 >= 2.17"
+# rpm_depends = ["ca-certificates"]   # deb_depends/rpm_depends/apk_depends 替换对应格式的 depends
+# bin_dir = "/usr/bin"                # 二进制的安装目录
+#
+# [[package.linux.files]]
+# src = "configs/app.toml"
+# dst = "/etc/app/app.toml"
+# mode = "0640"
+# config = true                       # 升级时保留用户的修改
+#
+# [[package.linux.files]]
+# src = "deploy/app.service"
+# dst = "/usr/lib/systemd/system/app.service"
+#
+# [package.linux.scripts]
+# postinstall = "deploy/postinstall.sh"
+# preremove = "deploy/preremove.sh"
+
+# 容器镜像 (无需 Docker，直接写入 OCI image layout 或 docker save 格式的 tar 包)
+# 使用: gocar image --targets linux/amd64,linux/arm64
+# [image]
+# name = "ghcr.io/acme/app"        # 镜像名称，默认为项目名
+# tags = ["1.0.0", "latest"]       # 默认为项目版本号或 latest
+# base = "images/distroless"       # 基础镜像的 OCI layout 目录，默认为 scratch
+# platforms = ["linux/amd64", "linux/arm64"]
+# dir = "/usr/local/bin"           # 二进制在镜像中的目录
+# entrypoint = ["/usr/local/bin/app"]
+# cmd = ["serve"]
+# env = ["TZ=UTC"]
+# user = "65532:65532"
+# ports = ["8080"]
+# labels = { "org.opencontainers.image.source" = "https://github.com/acme/app" }
+# format = "oci"                   # oci 或 docker
+
+# Windows 资源 (图标、manifest、版本信息)，构建 windows 目标时在入口包目录生成
+# rsrc_windows_<arch>.syso 并在构建后删除
+# [windows]
+# icon = "assets/app.ico"
+# execution_level = "asInvoker"    # asInvoker / highestAvailable / requireAdministrator
+# dpi_awareness = "permonitorv2"   # unaware / system / permonitor / permonitorv2
+# long_path_aware = true
+# manifest = "assets/app.manifest" # 自定义 manifest，设置后忽略上面三项
+# file_version = "1.2.3"           # 默认为 [project].version
+# product_name = "My App"          # 默认为二进制名称
+# company_name = "Acme Inc."
+# file_description = "My App server"
+# copyright = "Copyright (c) 2026 Acme Inc."
+
+# 生命周期钩子，在项目根目录通过 sh -c 执行
+# 可用环境变量: GOCAR_HOOK, GOCAR_PROJECT_ROOT, GOCAR_PROFILE, GOCAR_TARGET,
+#               GOCAR_ARTIFACT (post_build), GOCAR_COMMAND/GOCAR_ERROR (on_failure)
+# pre_* 钩子失败会中止命令
+# [hooks]
+# pre_build = "go generate ./..."
+# post_build = "cp \"$GOCAR_ARTIFACT\" /srv/releases/"
+# pre_test = "docker compose up -d db"
+# post_test = "docker compose down"
+# pre_run = "go generate ./..."
+# on_failure = "echo \"$GOCAR_COMMAND failed: $GOCAR_ERROR\" >&2"
+
 # 自定义命令
 # 格式: 命令名 = "要执行的 shell 命令"
 # 使用: gocar <命令名>
 # 命令会在项目根目录下执行
 #
-# 自定义命令可以覆盖以下内置命令: build, run, clean, fmt, vet, add, update, tidy, test, check, commands, doctor
+# 自定义命令可以覆盖以下内置命令: build, run, clean, fmt, vet, add, update, tidy, test, check, package, image, targets, bloat, pgo, cov, install, uninstall, commands, doctor, completion
 # 保护命令 (new, init) 不可被覆盖
 [commands]
 # lint = "golangci-lint run"
@@ -218,18 +401,43 @@ func decodeProjectConfig(configPath string) (*GocarConfig, error) {
 		Build    BuildConfig              `toml:"build"`
 		Run      RunConfig                `toml:"run"`
 		Profile  map[string]ProfileConfig `toml:"profile"`
+		Bins     []BinConfig              `toml:"bin"`
+		Package  PackageConfig            `toml:"package"`
+		Image    ImageConfig              `toml:"image"`
+		Windows  WindowsConfig            `toml:"windows"`
+		Hooks    HooksConfig              `toml:"hooks"`
 		Commands map[string]string        `toml:"commands"`
 	}
 
-	if _, err := toml.DecodeFile(configPath, &raw); err != nil {
+	md, err := toml.DecodeFile(configPath, &raw)
+	if err != nil {
 		return nil, err
 	}
+	for _, key := range md.Keys() {
+		if len(key) != 3 || key[0] != "profile" {
+			continue
+		}
+		profile, ok := raw.Profile[key[1]]
+		if !ok {
+			continue
+		}
+		if profile.defined == nil {
+			profile.defined = map[string]bool{}
+		}
+		profile.defined[key[2]] = true
+		raw.Profile[key[1]] = profile
+	}
 
 	return &GocarConfig{
 		Project:  raw.Project,
 		Build:    raw.Build,
 		Run:      raw.Run,
 		Profile:  ProfilesConfig{Profiles: raw.Profile},
+		Bins:     raw.Bins,
+		Package:  raw.Package,
+		Image:    raw.Image,
+		Windows:  raw.Windows,
+		Hooks:    raw.Hooks,
 		Commands: raw.Commands,
 	}, nil
 }
@@ -259,6 +467,18 @@ func mergeProjectConfig(base *GocarConfig, project *GocarConfig) *GocarConfig {
 	if len(project.Build.ExtraEnv) > 0 {
 		base.Build.ExtraEnv = project.Build.ExtraEnv
 	}
+	if len(project.Build.Targets) > 0 {
+		base.Build.Targets = project.Build.Targets
+	}
+	for symbol, tmpl := range project.Build.Vars {
+		base.Build.Vars[symbol] = tmpl
+	}
+	if project.Build.ArtifactName != "" {
+		base.Build.ArtifactName = project.Build.ArtifactName
+	}
+	if project.Build.ArtifactDir != "" {
+		base.Build.ArtifactDir = project.Build.ArtifactDir
+	}
 
 	// Run 配置
 	if project.Run.Entry != "" {
@@ -276,6 +496,29 @@ func mergeProjectConfig(base *GocarConfig, project *GocarConfig) *GocarConfig {
 		base.Profile.Profiles[name] = mergeProfile(base.Profile.Profiles[name], profile)
 	}
 
+	// Bins 配置
+	if len(project.Bins) > 0 {
+		base.Bins = project.Bins
+	}
+
+	// Package 配置
+	if project.Package.Output != "" {
+		base.Package.Output = project.Package.Output
+	}
+	if len(project.Package.Include) > 0 {
+		base.Package.Include = project.Package.Include
+	}
+	base.Package.Linux = mergeLinuxPackage(base.Package.Linux, project.Package.Linux)
+
+	// Image 配置
+	base.Image = mergeImage(base.Image, project.Image)
+
+	// Windows 资源配置
+	base.Windows = mergeWindows(base.Windows, project.Windows)
+
+	// Hooks
+	base.Hooks = mergeHooks(base.Hooks, project.Hooks)
+
 	// Commands - 项目命令覆盖全局命令
 	for name, cmd := range project.Commands {
 		base.Commands[name] = cmd
@@ -284,22 +527,68 @@ func mergeProjectConfig(base *GocarConfig, project *GocarConfig) *GocarConfig {
 	return base
 }
 
+// mergeProfile 用 project 中设置的字段覆盖 base；显式设为空值的字符串与列表同样覆盖，
+// 以便子 profile 清空父 profile 的 ldflags、tags 等
 func mergeProfile(base ProfileConfig, project ProfileConfig) ProfileConfig {
-	if project.Ldflags != "" {
+	if project.set("inherits", project.Inherits != "") {
+		base.Inherits = project.Inherits
+	}
+	if project.set("ldflags", project.Ldflags != "") {
 		base.Ldflags = project.Ldflags
 	}
-	if project.Gcflags != "" {
+	if project.set("gcflags", project.Gcflags != "") {
 		base.Gcflags = project.Gcflags
 	}
+	if project.set("asmflags", project.Asmflags != "") {
+		base.Asmflags = project.Asmflags
+	}
 	if project.Trimpath != nil {
 		base.Trimpath = project.Trimpath
 	}
 	if project.CgoEnabled != nil {
 		base.CgoEnabled = project.CgoEnabled
 	}
-	if project.Race {
+	if project.Race != nil {
 		base.Race = project.Race
 	}
+	if project.Msan != nil {
+		base.Msan = project.Msan
+	}
+	if project.Asan != nil {
+		base.Asan = project.Asan
+	}
+	if project.Cover != nil {
+		base.Cover = project.Cover
+	}
+	if project.set("coverpkg", len(project.Coverpkg) > 0) {
+		base.Coverpkg = project.Coverpkg
+	}
+	if project.set("tags", len(project.Tags) > 0) {
+		base.Tags = project.Tags
+	}
+	if project.set("extra_env", len(project.ExtraEnv) > 0) {
+		base.ExtraEnv = project.ExtraEnv
+	}
+	if project.set("buildmode", project.Buildmode != "") {
+		base.Buildmode = project.Buildmode
+	}
+	if project.set("mod", project.Mod != "") {
+		base.Mod = project.Mod
+	}
+	if project.set("goexperiment", project.Goexperiment != "") {
+		base.Goexperiment = project.Goexperiment
+	}
+	if project.set("pgo", project.Pgo != "") {
+		base.Pgo = project.Pgo
+	}
+	if len(project.defined) > 0 {
+		defined := maps.Clone(base.defined)
+		if defined == nil {
+			defined = map[string]bool{}
+		}
+		maps.Copy(defined, project.defined)
+		base.defined = defined
+	}
 	return base
 }
 
@@ -337,6 +626,53 @@ func (c *GocarConfig) GetBuildEntryForApp(defaultName string) string {
 	return "cmd/app"
 }
 
+// GetBins 获取所有二进制配置。
+// 未声明 [[bin]] 时，自动发现 cmd/*/main.go 对应的 main 包。
+func (c *GocarConfig) GetBins(projectRoot string) []BinConfig {
+	if len(c.Bins) > 0 {
+		bins := make([]BinConfig, 0, len(c.Bins))
+		for _, bin := range c.Bins {
+			if bin.Entry == "" {
+				bin.Entry = "cmd/" + bin.Name
+			}
+			bins = append(bins, bin)
+		}
+		return bins
+	}
+
+	matches, err := filepath.Glob(filepath.Join(projectRoot, "cmd", "*", "main.go"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(matches)
+	bins := make([]BinConfig, 0, len(matches))
+	for _, match := range matches {
+		name := filepath.Base(filepath.Dir(match))
+		bins = append(bins, BinConfig{Name: name, Entry: "cmd/" + name})
+	}
+	return bins
+}
+
+// GetBin 按名称获取二进制配置
+func (c *GocarConfig) GetBin(projectRoot, name string) (BinConfig, bool) {
+	for _, bin := range c.GetBins(projectRoot) {
+		if bin.Name == name {
+			return bin, true
+		}
+	}
+	return BinConfig{}, false
+}
+
+// ListBins 列出所有二进制名称
+func (c *GocarConfig) ListBins(projectRoot string) []string {
+	bins := c.GetBins(projectRoot)
+	names := make([]string, 0, len(bins))
+	for _, bin := range bins {
+		names = append(names, bin.Name)
+	}
+	return names
+}
+
 // GetRunEntry 获取运行入口路径
 func (c *GocarConfig) GetRunEntry() string {
 	return c.GetRunEntryForApp("")
@@ -359,6 +695,15 @@ func (c *GocarConfig) GetBuildOutputRoot() string {
 	return filepath.Clean(output)
 }
 
+// GetPackageOutputRoot 获取打包输出目录
+func (c *GocarConfig) GetPackageOutputRoot() string {
+	output := strings.TrimSpace(c.Package.Output)
+	if output == "" {
+		return "dist"
+	}
+	return filepath.Clean(output)
+}
+
 // ResolveBuildOutputDir 解析并校验构建输出目录（用于清理等破坏性操作）
 func (c *GocarConfig) ResolveBuildOutputDir(projectRoot string) (string, error) {
 	outputRoot := c.GetBuildOutputRoot()
@@ -390,6 +735,96 @@ func (c *GocarConfig) ResolveBuildOutputDir(projectRoot string) (string, error)
 	return absOutputDir, nil
 }
 
+// ArtifactPlaceholders [build].artifact_name 与 artifact_dir 支持的占位符
+var ArtifactPlaceholders = []string{"name", "version", "os", "arch", "variant", "profile", "commit", "ext"}
+
+// artifactPlaceholderPattern 匹配 {placeholder}
+var artifactPlaceholderPattern = regexp.MustCompile(`\{([A-Za-z_]*)\}`)
+
+// ExpandArtifactTemplate 展开产物命名模板中的占位符。
+// 占位符的值中的路径分隔符替换为 "-"，因此目录层级只由模板本身决定；未知占位符返回错误。
+func ExpandArtifactTemplate(tmpl string, lookup func(name string) string) (string, error) {
+	var err error
+	expanded := artifactPlaceholderPattern.ReplaceAllStringFunc(tmpl, func(match string) string {
+		name := match[1 : len(match)-1]
+		if !slices.Contains(ArtifactPlaceholders, name) {
+			if err == nil {
+				err = fmt.Errorf("unknown placeholder %s (available: {%s})", match, strings.Join(ArtifactPlaceholders, "}, {"))
+			}
+			return match
+		}
+		return strings.NewReplacer("/", "-", `\`, "-").Replace(lookup(name))
+	})
+	return expanded, err
+}
+
+// ResolveArtifactPath 按 [build].artifact_dir / artifact_name 模板计算产物相对输出根目录的路径。
+// 未设置的模板使用 defaultDir / defaultName；文件名模板不含 {ext} 时自动追加扩展名。
+// 展开后的路径不能是绝对路径或跳出输出根目录。
+func (c *GocarConfig) ResolveArtifactPath(lookup func(name string) string, defaultDir, defaultName string) (string, error) {
+	dir := defaultDir
+	if c.Build.ArtifactDir != "" {
+		expanded, err := ExpandArtifactTemplate(c.Build.ArtifactDir, lookup)
+		if err != nil {
+			return "", fmt.Errorf("invalid [build].artifact_dir: %w", err)
+		}
+		dir = filepath.Clean(filepath.FromSlash(expanded))
+		if filepath.IsAbs(dir) || filepath.VolumeName(dir) != "" || strings.HasPrefix(dir, string(filepath.Separator)) {
+			return "", fmt.Errorf("invalid [build].artifact_dir %q: must be relative to [build].output", c.Build.ArtifactDir)
+		}
+		if dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("invalid [build].artifact_dir %q: escapes the build output directory", c.Build.ArtifactDir)
+		}
+	}
+
+	name := defaultName
+	if c.Build.ArtifactName != "" {
+		tmpl := c.Build.ArtifactName
+		if !strings.Contains(tmpl, "{ext}") {
+			tmpl += "{ext}"
+		}
+		expanded, err := ExpandArtifactTemplate(tmpl, lookup)
+		if err != nil {
+			return "", fmt.Errorf("invalid [build].artifact_name: %w", err)
+		}
+		name = strings.TrimSpace(expanded)
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return "", fmt.Errorf("invalid [build].artifact_name %q: must expand to a file name without path separators", c.Build.ArtifactName)
+		}
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// validateArtifactTemplates 使用示例值展开产物命名模板，提前发现未知占位符与越界路径
+func (c *GocarConfig) validateArtifactTemplates() error {
+	if c.Build.ArtifactName == "" && c.Build.ArtifactDir == "" {
+		return nil
+	}
+	sample := map[string]string{
+		"name":    "app",
+		"version": "1.0.0",
+		"os":      "linux",
+		"arch":    "amd64",
+		"profile": "release",
+		"commit":  "0000000",
+	}
+	_, err := c.ResolveArtifactPath(func(name string) string { return sample[name] }, ".", "app")
+	return err
+}
+
+// GetVersion 获取项目版本号。
+// version = "git" 时使用 git describe --tags 推导（去掉前缀 v），无法推导时返回空字符串。
+func (c *GocarConfig) GetVersion(projectRoot string) string {
+	if c.Project.Version != VersionFromGit {
+		return c.Project.Version
+	}
+	describe, err := util.GitDescribe(projectRoot)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(describe, "v")
+}
+
 // GetProjectName 获取项目名称
 func (c *GocarConfig) GetProjectName(defaultName string) string {
 	if c.Project.Name != "" {
@@ -406,11 +841,25 @@ func (c *GocarConfig) GetCommand(name string) (string, bool) {
 
 // RunCustomCommand 执行自定义命令
 func (c *GocarConfig) RunCustomCommand(projectRoot, name string, extraArgs []string) error {
-	cmdStr, ok := c.Commands[name]
-	if !ok {
+	if _, ok := c.Commands[name]; !ok {
 		return fmt.Errorf("command '%s' not defined in %s", name, ConfigFileName)
 	}
 
+	// 使用 shell 执行命令
+	args := c.CustomCommandArgs(name, extraArgs)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = projectRoot
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+
+	return cmd.Run()
+}
+
+// CustomCommandArgs 返回执行自定义命令的完整命令行 (sh -c <command> 加上引用后的额外参数)
+func (c *GocarConfig) CustomCommandArgs(name string, extraArgs []string) []string {
+	cmdStr := c.Commands[name]
+
 	// 如果有额外参数，追加到命令后面
 	if len(extraArgs) > 0 {
 		quotedArgs := make([]string, 0, len(extraArgs))
@@ -419,15 +868,7 @@ func (c *GocarConfig) RunCustomCommand(projectRoot, name string, extraArgs []str
 		}
 		cmdStr = cmdStr + " " + strings.Join(quotedArgs, " ")
 	}
-
-	// 使用 shell 执行命令
-	cmd := exec.Command("sh", "-c", cmdStr)
-	cmd.Dir = projectRoot
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
+	return []string{"sh", "-c", cmdStr}
 }
 
 func shellQuoteArg(arg string) string {
@@ -442,16 +883,47 @@ func (c *GocarConfig) ListCommands() map[string]string {
 	return c.Commands
 }
 
-// GetProfile 获取指定名称的构建配置
+// GetProfile 获取指定名称的构建配置，inherits 链已展开。
+// profile 不存在或继承链无效时返回 false，具体错误由 ResolveProfile 给出。
 func (c *GocarConfig) GetProfile(name string) (*ProfileConfig, bool) {
+	profile, err := c.ResolveProfile(name)
+	if err != nil {
+		return nil, false
+	}
+	return profile, true
+}
+
+// ResolveProfile 沿 inherits 链合并 profile，子 profile 中设置的字段覆盖父 profile。
+func (c *GocarConfig) ResolveProfile(name string) (*ProfileConfig, error) {
 	if name == "" {
 		name = "debug"
 	}
+
+	chain := []string{name}
 	profile, ok := c.Profile.Profiles[name]
 	if !ok {
-		return nil, false
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	layers := []ProfileConfig{profile}
+	for parent := profile.Inherits; parent != ""; parent = profile.Inherits {
+		chain = append(chain, parent)
+		if slices.Contains(chain[:len(chain)-1], parent) {
+			return nil, fmt.Errorf("profile inheritance cycle: %s", strings.Join(chain, " -> "))
+		}
+		profile, ok = c.Profile.Profiles[parent]
+		if !ok {
+			return nil, fmt.Errorf("profile %q inherits unknown profile %q", chain[len(chain)-2], parent)
+		}
+		layers = append(layers, profile)
+	}
+
+	// 从最顶层的父 profile 开始逐层覆盖
+	resolved := ProfileConfig{}
+	for i := len(layers) - 1; i >= 0; i-- {
+		resolved = mergeProfile(resolved, layers[i])
 	}
-	return &profile, true
+	resolved.Inherits = layers[0].Inherits
+	return &resolved, nil
 }
 
 // GetProfileForBuild 获取当前构建使用的 profile。
@@ -482,13 +954,52 @@ func (c *GocarConfig) Validate(projectRoot string) error {
 	if _, err := c.ResolveBuildOutputDir(projectRoot); err != nil {
 		return err
 	}
+	if err := c.validateArtifactTemplates(); err != nil {
+		return err
+	}
+	if err := c.validateImage(); err != nil {
+		return err
+	}
+	if err := c.validateWindows(projectRoot); err != nil {
+		return err
+	}
+	if err := c.validateLinuxPackage(projectRoot); err != nil {
+		return err
+	}
 	if len(c.Profile.Profiles) == 0 {
 		return fmt.Errorf("at least one build profile is required")
 	}
-	for name := range c.Profile.Profiles {
+	for _, name := range c.ListProfiles() {
 		if strings.TrimSpace(name) == "" {
 			return fmt.Errorf("profile name cannot be empty")
 		}
+		profile, err := c.ResolveProfile(name)
+		if err != nil {
+			return err
+		}
+		if profile.Mod != "" && !slices.Contains(validModFlags, profile.Mod) {
+			return fmt.Errorf("profile %q: invalid mod %q (expected one of: %s)", name, profile.Mod, strings.Join(validModFlags, ", "))
+		}
+		if profile.Buildmode != "" && !slices.Contains(validBuildmodes, profile.Buildmode) {
+			return fmt.Errorf("profile %q: invalid buildmode %q (expected one of: %s)", name, profile.Buildmode, strings.Join(validBuildmodes, ", "))
+		}
+		race, msan, asan := Enabled(profile.Race), Enabled(profile.Msan), Enabled(profile.Asan)
+		if (race && msan) || (race && asan) || (msan && asan) {
+			return fmt.Errorf("profile %q: race, msan and asan are mutually exclusive", name)
+		}
+	}
+	seenBins := map[string]bool{}
+	for _, bin := range c.Bins {
+		if strings.TrimSpace(bin.Name) == "" {
+			return fmt.Errorf("[[bin]] name cannot be empty")
+		}
+		if strings.ContainsAny(bin.Name, `/\`) || bin.Name == "." || bin.Name == ".." {
+			return fmt.Errorf("invalid [[bin]] name %q", bin.Name)
+		}
+		if seenBins[bin.Name] {
+			return fmt.Errorf("duplicate [[bin]] name %q", bin.Name)
+		}
+		seenBins[bin.Name] = true
 	}
 	for name, cmd := range c.Commands {
 		if strings.TrimSpace(name) == "" {
@@ -2,7 +2,10 @@ package config
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -70,7 +73,7 @@ lint = "golangci-lint run"
 		t.Fatal("release cgo_enabled should be overridden to true")
 	}
 	ci, ok := cfg.GetProfile("ci")
-	if !ok || !ci.Race {
+	if !ok || !Enabled(ci.Race) {
 		t.Fatalf("custom ci profile not merged: %#v", cfg.Profile.Profiles)
 	}
 	if cfg.Commands["lint"] == "" {
@@ -103,3 +106,351 @@ func TestResolveBuildOutputDirRejectsDangerousPaths(t *testing.T) {
 		t.Fatal("expected error for absolute project root output")
 	}
 }
+
+func TestResolveArtifactPath(t *testing.T) {
+	values := map[string]string{"name": "myapp", "version": "1.4.0", "os": "linux", "arch": "amd64", "ext": "", "commit": "feat/x"}
+	lookup := func(name string) string { return values[name] }
+
+	cfg := DefaultConfig()
+	got, err := cfg.ResolveArtifactPath(lookup, filepath.Join("release", "linux-amd64"), "myapp")
+	if err != nil || got != filepath.Join("release", "linux-amd64", "myapp") {
+		t.Fatalf("default layout = %q, %v", got, err)
+	}
+
+	cfg.Build.ArtifactDir = "."
+	cfg.Build.ArtifactName = "{name}_{version}_{os}_{arch}"
+	if got, err := cfg.ResolveArtifactPath(lookup, "ignored", "ignored"); err != nil || got != "myapp_1.4.0_linux_amd64" {
+		t.Fatalf("ResolveArtifactPath() = %q, %v", got, err)
+	}
+	values["ext"] = ".exe"
+	if got, _ := cfg.ResolveArtifactPath(lookup, "", ""); got != "myapp_1.4.0_linux_amd64.exe" {
+		t.Fatalf("extension should be appended when {ext} is missing, got %q", got)
+	}
+
+	// 占位符中的路径分隔符不会产生新的目录层级
+	cfg.Build.ArtifactDir = "{version}/{commit}"
+	cfg.Build.ArtifactName = "{name}{ext}"
+	if got, _ := cfg.ResolveArtifactPath(lookup, "", ""); got != filepath.Join("1.4.0", "feat-x", "myapp.exe") {
+		t.Fatalf("ResolveArtifactPath() = %q", got)
+	}
+
+	root := t.TempDir()
+	for _, tc := range []struct{ dir, name string }{
+		{"../{os}", "{name}"},
+		{"/srv/{os}", "{name}"},
+		{"{profile}/../..", "{name}"},
+		{"", "{name}/{os}"},
+		{"", "{nmae}"},
+	} {
+		cfg.Build.ArtifactDir = tc.dir
+		cfg.Build.ArtifactName = tc.name
+		if err := cfg.Validate(root); err == nil {
+			t.Fatalf("Validate() should reject artifact_dir=%q artifact_name=%q", tc.dir, tc.name)
+		}
+	}
+	cfg.Build.ArtifactDir = "{profile}/.."
+	cfg.Build.ArtifactName = "{name}-{variant}{ext}"
+	if err := cfg.Validate(root); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestGetBinsDeclaredAndDiscovered(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"worker", "api"} {
+		dir := filepath.Join(root, "cmd", name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	if got := cfg.ListBins(root); len(got) != 2 || got[0] != "api" || got[1] != "worker" {
+		t.Fatalf("discovered bins = %#v", got)
+	}
+
+	content := `
+[[bin]]
+name = "migrate"
+ldflags = "-X main.component=migrate"
+tags = ["netgo"]
+
+[[bin]]
+name = "api"
+entry = "services/api"
+`
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if err := cfg.Validate(root); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	migrate, ok := cfg.GetBin(root, "migrate")
+	if !ok || migrate.Entry != "cmd/migrate" || migrate.Tags[0] != "netgo" {
+		t.Fatalf("migrate bin = %#v", migrate)
+	}
+	if api, _ := cfg.GetBin(root, "api"); api.Entry != "services/api" {
+		t.Fatalf("api bin entry = %q", api.Entry)
+	}
+	if _, ok := cfg.GetBin(root, "worker"); ok {
+		t.Fatal("declared [[bin]] entries should disable discovery")
+	}
+
+	cfg.Bins = append(cfg.Bins, BinConfig{Name: "api"})
+	if err := cfg.Validate(root); err == nil {
+		t.Fatal("expected duplicate [[bin]] name to fail validation")
+	}
+}
+
+func TestGetVersionFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	git("tag", "v1.2.3")
+
+	cfg := DefaultConfig()
+	cfg.Project.Version = VersionFromGit
+	if got := cfg.GetVersion(root); got != "1.2.3" {
+		t.Fatalf("GetVersion() = %q, want 1.2.3", got)
+	}
+
+	cfg.Project.Version = "2.0.0"
+	if got := cfg.GetVersion(root); got != "2.0.0" {
+		t.Fatalf("GetVersion() = %q, want 2.0.0", got)
+	}
+}
+
+func TestLoadMergesHooks(t *testing.T) {
+	root := t.TempDir()
+	content := `
+[hooks]
+pre_build = "go generate ./..."
+on_failure = "echo failed"
+`
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if got := cfg.Hooks.Get(HookPreBuild); got != "go generate ./..." {
+		t.Fatalf("pre_build = %q", got)
+	}
+
+	if _, ok := cfg.HookCommand(root, HookPostBuild, nil); ok {
+		t.Fatal("expected unset post_build hook to be skipped")
+	}
+	cmd, ok := cfg.HookCommand(root, HookOnFailure, map[string]string{"GOCAR_COMMAND": "build"})
+	if !ok {
+		t.Fatal("expected on_failure hook command")
+	}
+	if cmd.Dir != root {
+		t.Fatalf("hook dir = %q, want %q", cmd.Dir, root)
+	}
+	for _, want := range []string{"GOCAR_HOOK=on_failure", "GOCAR_PROJECT_ROOT=" + root, "GOCAR_COMMAND=build"} {
+		if !slices.Contains(cmd.Env, want) {
+			t.Fatalf("hook env missing %q", want)
+		}
+	}
+}
+
+func TestProfileInherits(t *testing.T) {
+	root := t.TempDir()
+	content := `
+[profile.ci]
+inherits = "release"
+race = true
+
+[profile.nightly]
+inherits = "ci"
+ldflags = "-s"
+
+[profile.bench]
+inherits = "ci"
+race = false
+`
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if err := cfg.Validate(root); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	nightly, ok := cfg.GetProfile("nightly")
+	if !ok {
+		t.Fatal("expected nightly profile")
+	}
+	if nightly.Ldflags != "-s" || !Enabled(nightly.Race) || nightly.Trimpath == nil || !*nightly.Trimpath || nightly.CgoEnabled == nil || *nightly.CgoEnabled {
+		t.Fatalf("nightly profile not resolved through ci and release: %+v", nightly)
+	}
+	// 子 profile 可以关闭父 profile 打开的开关
+	if bench, ok := cfg.GetProfile("bench"); !ok || bench.Race == nil || *bench.Race {
+		t.Fatalf("bench profile should turn race off: %+v", bench)
+	}
+	if nightly.Inherits != "ci" {
+		t.Fatalf("nightly.Inherits = %q", nightly.Inherits)
+	}
+
+	cfg.Profile.Profiles["release"] = ProfileConfig{Inherits: "nightly"}
+	if err := cfg.Validate(root); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("Validate() = %v, want inheritance cycle error", err)
+	}
+
+	cfg.Profile.Profiles["release"] = ProfileConfig{Inherits: "relase"}
+	if _, err := cfg.ResolveProfile("ci"); err == nil || !strings.Contains(err.Error(), `unknown profile "relase"`) {
+		t.Fatalf("ResolveProfile() = %v, want unknown parent error", err)
+	}
+}
+
+func TestProfileInheritsClearsEmptyValues(t *testing.T) {
+	root := t.TempDir()
+	content := `
+[profile.release]
+gcflags = "all=-B"
+tags = ["netgo"]
+
+[profile.profiling]
+inherits = "release"
+ldflags = ""
+gcflags = ""
+tags = []
+
+[profile.ci]
+inherits = "release"
+race = true
+`
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	// 显式设为空值覆盖父 profile，未出现的字段仍然继承
+	profiling, err := cfg.ResolveProfile("profiling")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profiling.Ldflags != "" || profiling.Gcflags != "" || len(profiling.Tags) != 0 {
+		t.Fatalf("profiling profile should clear ldflags, gcflags and tags: %+v", profiling)
+	}
+	if profiling.Trimpath == nil || !*profiling.Trimpath {
+		t.Fatalf("profiling profile should still inherit trimpath: %+v", profiling)
+	}
+	ci, err := cfg.ResolveProfile("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ci.Ldflags != "-s -w" || ci.Gcflags != "all=-B" || !slices.Equal(ci.Tags, []string{"netgo"}) {
+		t.Fatalf("ci profile should inherit release flags: %+v", ci)
+	}
+}
+
+func TestValidateProfileSettings(t *testing.T) {
+	root := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.Profile.Profiles["ci"] = ProfileConfig{Mod: "vendor", Buildmode: "pie", Tags: []string{"netgo"}}
+	if err := cfg.Validate(root); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	// buildmode 留空即为 go build 的默认模式，不接受 "default"
+	cfg.Profile.Profiles["ci"] = ProfileConfig{Buildmode: "default"}
+	if err := cfg.Validate(root); err == nil || !strings.Contains(err.Error(), "invalid buildmode") {
+		t.Fatalf("Validate() = %v, want invalid buildmode error", err)
+	}
+
+	cfg.Profile.Profiles["ci"] = ProfileConfig{Mod: "vendored"}
+	if err := cfg.Validate(root); err == nil || !strings.Contains(err.Error(), "invalid mod") {
+		t.Fatalf("Validate() = %v, want invalid mod error", err)
+	}
+	on := true
+	cfg.Profile.Profiles["ci"] = ProfileConfig{Race: &on, Asan: &on}
+	if err := cfg.Validate(root); err == nil {
+		t.Fatal("expected race + asan to be rejected")
+	}
+}
+
+func TestLoadLinuxPackage(t *testing.T) {
+	root := t.TempDir()
+	content := `
+[package.linux]
+maintainer = "Jane Doe <jane@example.com>"
+depends = ["ca-certificates"]
+rpm_depends = ["ca-certificates", "glibc >= 2.17"]
+
+[[package.linux.files]]
+src = "app.toml"
+dst = "/etc/app/app.toml"
+mode = "0640"
+config = true
+`
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "app.toml"), []byte("port = 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if err := cfg.Validate(root); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if got := cfg.GetLinuxPackageName("MyApp"); got != "myapp" {
+		t.Fatalf("GetLinuxPackageName() = %q, want myapp", got)
+	}
+	if got := cfg.GetLinuxPackageRelease(); got != 1 {
+		t.Fatalf("GetLinuxPackageRelease() = %d, want 1", got)
+	}
+	if got := cfg.GetLinuxDepends("rpm"); len(got) != 2 {
+		t.Fatalf("GetLinuxDepends(rpm) = %v", got)
+	}
+	if got := cfg.GetLinuxDepends("deb"); !slices.Equal(got, []string{"ca-certificates"}) {
+		t.Fatalf("GetLinuxDepends(deb) = %v", got)
+	}
+	if mode, err := ParseFileMode(cfg.Package.Linux.Files[0].Mode); err != nil || mode != 0640 {
+		t.Fatalf("ParseFileMode() = %o, %v", mode, err)
+	}
+
+	cfg.Package.Linux.Files[0].Dst = "etc/app.toml"
+	if err := cfg.Validate(root); err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Fatalf("Validate() = %v, want relative dst rejected", err)
+	}
+	cfg.Package.Linux.Files[0].Dst = "/etc/app/app.toml"
+	cfg.Package.Linux.Scripts.PostInstall = "missing.sh"
+	if err := cfg.Validate(root); err == nil || !strings.Contains(err.Error(), "postinstall") {
+		t.Fatalf("Validate() = %v, want missing script rejected", err)
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"sort"
+)
+
+// 生命周期钩子名称
+const (
+	HookPreBuild  = "pre_build"
+	HookPostBuild = "post_build"
+	HookPreTest   = "pre_test"
+	HookPostTest  = "post_test"
+	HookPreRun    = "pre_run"
+	HookOnFailure = "on_failure"
+)
+
+// HooksConfig 生命周期钩子配置，每个钩子是在项目根目录通过 sh -c 执行的脚本
+type HooksConfig struct {
+	PreBuild  string `toml:"pre_build"`  // 构建开始前执行一次
+	PostBuild string `toml:"post_build"` // 每个产物每次构建成功后执行 (包括指纹未变化的产物)
+	PreTest   string `toml:"pre_test"`   // 测试开始前执行
+	PostTest  string `toml:"post_test"`  // 测试通过后执行
+	PreRun    string `toml:"pre_run"`    // 运行前执行
+	OnFailure string `toml:"on_failure"` // build/test/run/package 失败时执行
+}
+
+// Get 返回指定钩子的脚本，未配置时返回空字符串
+func (h HooksConfig) Get(name string) string {
+	switch name {
+	case HookPreBuild:
+		return h.PreBuild
+	case HookPostBuild:
+		return h.PostBuild
+	case HookPreTest:
+		return h.PreTest
+	case HookPostTest:
+		return h.PostTest
+	case HookPreRun:
+		return h.PreRun
+	case HookOnFailure:
+		return h.OnFailure
+	}
+	return ""
+}
+
+func mergeHooks(base HooksConfig, project HooksConfig) HooksConfig {
+	if project.PreBuild != "" {
+		base.PreBuild = project.PreBuild
+	}
+	if project.PostBuild != "" {
+		base.PostBuild = project.PostBuild
+	}
+	if project.PreTest != "" {
+		base.PreTest = project.PreTest
+	}
+	if project.PostTest != "" {
+		base.PostTest = project.PostTest
+	}
+	if project.PreRun != "" {
+		base.PreRun = project.PreRun
+	}
+	if project.OnFailure != "" {
+		base.OnFailure = project.OnFailure
+	}
+	return base
+}
+
+// HookCommand 创建在项目根目录执行钩子的命令，钩子未配置时返回 false。
+// 钩子继承当前环境，并额外获得 GOCAR_HOOK、GOCAR_PROJECT_ROOT 与 env 中的变量。
+func (c *GocarConfig) HookCommand(projectRoot, name string, env map[string]string) (*exec.Cmd, bool) {
+	if c == nil {
+		return nil, false
+	}
+	script := c.Hooks.Get(name)
+	if script == "" {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = projectRoot
+	cmd.Env = append(os.Environ(), "GOCAR_HOOK="+name, "GOCAR_PROJECT_ROOT="+projectRoot)
+	for _, key := range keys {
+		cmd.Env = append(cmd.Env, key+"="+env[key])
+	}
+	return cmd, true
+}
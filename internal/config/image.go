@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ImageConfig 容器镜像配置 ([image])，供 gocar image 使用
+type ImageConfig struct {
+	Name       string            `toml:"name"`       // 镜像名称 (如 ghcr.io/acme/app)，默认为项目名
+	Tags       []string          `toml:"tags"`       // 镜像标签，默认为项目版本号，未设置版本时为 latest
+	Base       string            `toml:"base"`       // 基础镜像的 OCI layout 目录 (相对项目根目录)，为空时基于 scratch
+	Platforms  []string          `toml:"platforms"`  // 默认目标平台，默认为 linux/<当前架构>
+	Bin        string            `toml:"bin"`        // 放入镜像的二进制，声明多个 [[bin]] 时必须指定
+	Dir        string            `toml:"dir"`        // 二进制在镜像中的目录，默认为 /usr/local/bin
+	Entrypoint []string          `toml:"entrypoint"` // 默认为二进制路径
+	Cmd        []string          `toml:"cmd"`        // 默认参数
+	Env        []string          `toml:"env"`        // KEY=VALUE 形式的环境变量
+	Labels     map[string]string `toml:"labels"`     // 镜像标签 (OCI labels)
+	Workdir    string            `toml:"workdir"`    // 工作目录
+	User       string            `toml:"user"`       // 运行用户，如 "65532:65532"
+	Ports      []string          `toml:"ports"`      // 暴露的端口，如 "8080" 或 "53/udp"
+	Format     string            `toml:"format"`     // 输出格式: oci (默认) 或 docker
+	Output     string            `toml:"output"`     // 输出路径，默认为 <package.output>/image/<name>[.tar]
+}
+
+// imageTagPattern 镜像标签的合法格式
+var imageTagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// imagePortPattern 端口格式: <port>[/tcp|udp|sctp]
+var imagePortPattern = regexp.MustCompile(`^[0-9]{1,5}(/(tcp|udp|sctp))?$`)
+
+// GetImageName 返回镜像名称，未配置时使用项目名
+func (c *GocarConfig) GetImageName(appName string) string {
+	if c.Image.Name != "" {
+		return c.Image.Name
+	}
+	return strings.ToLower(appName)
+}
+
+// GetImageTags 返回镜像标签，未配置时使用项目版本号，没有版本号时为 latest
+func (c *GocarConfig) GetImageTags(projectRoot string) []string {
+	if len(c.Image.Tags) > 0 {
+		return c.Image.Tags
+	}
+	if version := c.GetVersion(projectRoot); version != "" && imageTagPattern.MatchString(version) {
+		return []string{version}
+	}
+	return []string{"latest"}
+}
+
+// ValidateImageTag 检查镜像标签格式
+func ValidateImageTag(tag string) error {
+	if !imageTagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid image tag %q", tag)
+	}
+	return nil
+}
+
+func mergeImage(base ImageConfig, project ImageConfig) ImageConfig {
+	if project.Name != "" {
+		base.Name = project.Name
+	}
+	if len(project.Tags) > 0 {
+		base.Tags = project.Tags
+	}
+	if project.Base != "" {
+		base.Base = project.Base
+	}
+	if len(project.Platforms) > 0 {
+		base.Platforms = project.Platforms
+	}
+	if project.Bin != "" {
+		base.Bin = project.Bin
+	}
+	if project.Dir != "" {
+		base.Dir = project.Dir
+	}
+	if len(project.Entrypoint) > 0 {
+		base.Entrypoint = project.Entrypoint
+	}
+	if len(project.Cmd) > 0 {
+		base.Cmd = project.Cmd
+	}
+	if len(project.Env) > 0 {
+		base.Env = project.Env
+	}
+	if len(project.Labels) > 0 {
+		base.Labels = project.Labels
+	}
+	if project.Workdir != "" {
+		base.Workdir = project.Workdir
+	}
+	if project.User != "" {
+		base.User = project.User
+	}
+	if len(project.Ports) > 0 {
+		base.Ports = project.Ports
+	}
+	if project.Format != "" {
+		base.Format = project.Format
+	}
+	if project.Output != "" {
+		base.Output = project.Output
+	}
+	return base
+}
+
+// validateImage 校验 [image] 配置
+func (c *GocarConfig) validateImage() error {
+	image := c.Image
+	if image.Name != "" && (strings.ToLower(image.Name) != image.Name || strings.ContainsAny(image.Name, ": @")) {
+		return fmt.Errorf("[image].name %q must be a lowercase repository name without tag or digest", image.Name)
+	}
+	for _, tag := range image.Tags {
+		if err := ValidateImageTag(tag); err != nil {
+			return fmt.Errorf("[image].tags: %w", err)
+		}
+	}
+	for _, platform := range image.Platforms {
+		if !strings.HasPrefix(platform, "linux/") {
+			return fmt.Errorf("[image].platforms: %q is not a linux platform", platform)
+		}
+	}
+	if image.Dir != "" && !path.IsAbs(image.Dir) {
+		return fmt.Errorf("[image].dir %q must be an absolute path", image.Dir)
+	}
+	for _, env := range image.Env {
+		if name, _, ok := strings.Cut(env, "="); !ok || name == "" {
+			return fmt.Errorf("[image].env entry %q must be KEY=VALUE", env)
+		}
+	}
+	for _, port := range image.Ports {
+		if !imagePortPattern.MatchString(port) {
+			return fmt.Errorf("[image].ports entry %q must be <port>[/tcp|udp|sctp]", port)
+		}
+	}
+	if image.Format != "" && image.Format != "oci" && image.Format != "docker" {
+		return fmt.Errorf("[image].format %q must be oci or docker", image.Format)
+	}
+	return nil
+}
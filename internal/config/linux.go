@@ -0,0 +1,212 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LinuxPackageConfig Linux 软件包配置 ([package.linux])，供 gocar package --format deb|rpm|apk 使用
+type LinuxPackageConfig struct {
+	Name        string             `toml:"name"`        // 包名，默认为小写的项目名
+	Release     int                `toml:"release"`     // 打包修订号，默认为 1
+	Maintainer  string             `toml:"maintainer"`  // 维护者，如 "Jane Doe <jane@example.com>"
+	Description string             `toml:"description"` // 描述，第一行为摘要
+	Homepage    string             `toml:"homepage"`    // 项目主页
+	License     string             `toml:"license"`     // 许可证，如 MIT
+	Vendor      string             `toml:"vendor"`      // 厂商 (rpm)
+	Section     string             `toml:"section"`     // 分类 (deb 的 Section，rpm 的 Group)
+	Priority    string             `toml:"priority"`    // 优先级 (deb)，如 optional
+	BinDir      string             `toml:"bin_dir"`     // 二进制的安装目录，默认为 /usr/bin
+	Depends     []string           `toml:"depends"`     // 依赖，如 "ca-certificates" 或 "libc6 >= 2.17"
+	Conflicts   []string           `toml:"conflicts"`   // 冲突的包
+	Provides    []string           `toml:"provides"`    // 提供的虚拟包
+	Replaces    []string           `toml:"replaces"`    // 替代的包 (rpm 中为 Obsoletes)
+	DebDepends  []string           `toml:"deb_depends"` // 仅用于 deb 的依赖，设置后替换 depends
+	RPMDepends  []string           `toml:"rpm_depends"` // 仅用于 rpm 的依赖，设置后替换 depends
+	APKDepends  []string           `toml:"apk_depends"` // 仅用于 apk 的依赖，设置后替换 depends
+	Files       []LinuxFileConfig  `toml:"files"`       // 额外安装的文件 ([[package.linux.files]])
+	Scripts     LinuxScriptsConfig `toml:"scripts"`     // 安装与卸载脚本 ([package.linux.scripts])
+}
+
+// LinuxFileConfig 安装到系统中的文件，如配置文件或 systemd unit
+type LinuxFileConfig struct {
+	Src    string `toml:"src"`    // 本地文件 (相对项目根目录)
+	Dst    string `toml:"dst"`    // 安装路径 (绝对路径)
+	Mode   string `toml:"mode"`   // 八进制权限，如 "0644"，默认使用源文件权限
+	Config bool   `toml:"config"` // 配置文件：升级时保留用户的修改 (deb conffiles / rpm %config(noreplace))
+}
+
+// LinuxScriptsConfig 安装与卸载脚本 (相对项目根目录的文件路径)
+type LinuxScriptsConfig struct {
+	PreInstall  string `toml:"preinstall"`
+	PostInstall string `toml:"postinstall"`
+	PreRemove   string `toml:"preremove"`
+	PostRemove  string `toml:"postremove"`
+}
+
+// linuxPackageNamePattern deb、rpm 与 apk 共同接受的包名
+var linuxPackageNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]*$`)
+
+// GetLinuxPackageName 返回包名，未配置时使用小写的项目名
+func (c *GocarConfig) GetLinuxPackageName(appName string) string {
+	if c.Package.Linux.Name != "" {
+		return c.Package.Linux.Name
+	}
+	return strings.ToLower(appName)
+}
+
+// GetLinuxPackageRelease 返回打包修订号，默认为 1
+func (c *GocarConfig) GetLinuxPackageRelease() int {
+	if c.Package.Linux.Release > 0 {
+		return c.Package.Linux.Release
+	}
+	return 1
+}
+
+// GetLinuxBinDir 返回二进制的安装目录，默认为 /usr/bin
+func (c *GocarConfig) GetLinuxBinDir() string {
+	if c.Package.Linux.BinDir != "" {
+		return c.Package.Linux.BinDir
+	}
+	return "/usr/bin"
+}
+
+// GetLinuxDepends 返回指定格式 (deb/rpm/apk) 的依赖，格式专用的列表优先
+func (c *GocarConfig) GetLinuxDepends(format string) []string {
+	linux := c.Package.Linux
+	override := map[string][]string{"deb": linux.DebDepends, "rpm": linux.RPMDepends, "apk": linux.APKDepends}[format]
+	if len(override) > 0 {
+		return override
+	}
+	return linux.Depends
+}
+
+// ParseFileMode 解析八进制权限字符串，空字符串返回 0
+func ParseFileMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(mode, "0o"), 8, 32)
+	if err != nil || n > 0777 {
+		return 0, fmt.Errorf("invalid file mode %q (expected octal such as \"0644\")", mode)
+	}
+	return os.FileMode(n), nil
+}
+
+func mergeLinuxPackage(base LinuxPackageConfig, project LinuxPackageConfig) LinuxPackageConfig {
+	if project.Name != "" {
+		base.Name = project.Name
+	}
+	if project.Release > 0 {
+		base.Release = project.Release
+	}
+	if project.Maintainer != "" {
+		base.Maintainer = project.Maintainer
+	}
+	if project.Description != "" {
+		base.Description = project.Description
+	}
+	if project.Homepage != "" {
+		base.Homepage = project.Homepage
+	}
+	if project.License != "" {
+		base.License = project.License
+	}
+	if project.Vendor != "" {
+		base.Vendor = project.Vendor
+	}
+	if project.Section != "" {
+		base.Section = project.Section
+	}
+	if project.Priority != "" {
+		base.Priority = project.Priority
+	}
+	if project.BinDir != "" {
+		base.BinDir = project.BinDir
+	}
+	if len(project.Depends) > 0 {
+		base.Depends = project.Depends
+	}
+	if len(project.Conflicts) > 0 {
+		base.Conflicts = project.Conflicts
+	}
+	if len(project.Provides) > 0 {
+		base.Provides = project.Provides
+	}
+	if len(project.Replaces) > 0 {
+		base.Replaces = project.Replaces
+	}
+	if len(project.DebDepends) > 0 {
+		base.DebDepends = project.DebDepends
+	}
+	if len(project.RPMDepends) > 0 {
+		base.RPMDepends = project.RPMDepends
+	}
+	if len(project.APKDepends) > 0 {
+		base.APKDepends = project.APKDepends
+	}
+	if len(project.Files) > 0 {
+		base.Files = project.Files
+	}
+	if project.Scripts != (LinuxScriptsConfig{}) {
+		base.Scripts = project.Scripts
+	}
+	return base
+}
+
+// validateLinuxPackage 校验 [package.linux] 配置
+func (c *GocarConfig) validateLinuxPackage(projectRoot string) error {
+	linux := c.Package.Linux
+	if linux.Name != "" && !linuxPackageNamePattern.MatchString(linux.Name) {
+		return fmt.Errorf("[package.linux].name %q must contain only lowercase letters, digits, '+', '-' and '.'", linux.Name)
+	}
+	if linux.Release < 0 {
+		return fmt.Errorf("[package.linux].release must be positive")
+	}
+	if linux.BinDir != "" && !path.IsAbs(linux.BinDir) {
+		return fmt.Errorf("[package.linux].bin_dir %q must be an absolute path", linux.BinDir)
+	}
+
+	sources := []struct{ key, path string }{}
+	for i, file := range linux.Files {
+		key := fmt.Sprintf("[[package.linux.files]] #%d", i+1)
+		if file.Src == "" || file.Dst == "" {
+			return fmt.Errorf("%s: src and dst are required", key)
+		}
+		if !path.IsAbs(file.Dst) || path.Clean(file.Dst) != file.Dst {
+			return fmt.Errorf("%s: dst %q must be a clean absolute path", key, file.Dst)
+		}
+		if _, err := ParseFileMode(file.Mode); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		sources = append(sources, struct{ key, path string }{key, file.Src})
+	}
+	scripts := linux.Scripts
+	for _, script := range []struct{ key, path string }{
+		{"[package.linux.scripts].preinstall", scripts.PreInstall},
+		{"[package.linux.scripts].postinstall", scripts.PostInstall},
+		{"[package.linux.scripts].preremove", scripts.PreRemove},
+		{"[package.linux.scripts].postremove", scripts.PostRemove},
+	} {
+		if script.path != "" {
+			sources = append(sources, script)
+		}
+	}
+	for _, source := range sources {
+		p := source.path
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(projectRoot, p)
+		}
+		if info, err := os.Stat(p); err != nil {
+			return fmt.Errorf("%s: %w", source.key, err)
+		} else if info.IsDir() {
+			return fmt.Errorf("%s: %s is a directory", source.key, source.path)
+		}
+	}
+	return nil
+}
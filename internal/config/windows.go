@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// WindowsConfig Windows 资源配置 ([windows])，构建 windows 目标时嵌入图标、manifest 与版本信息
+type WindowsConfig struct {
+	Icon            string `toml:"icon"`             // .ico 文件路径 (相对项目根目录)
+	Manifest        string `toml:"manifest"`         // 自定义 manifest 文件，设置后忽略下面三个 manifest 选项
+	ExecutionLevel  string `toml:"execution_level"`  // asInvoker (默认) / highestAvailable / requireAdministrator
+	DPIAwareness    string `toml:"dpi_awareness"`    // unaware / system / permonitor / permonitorv2
+	LongPathAware   bool   `toml:"long_path_aware"`  // 允许超过 MAX_PATH 的路径
+	FileVersion     string `toml:"file_version"`     // 文件版本，默认为 [project].version
+	ProductVersion  string `toml:"product_version"`  // 产品版本，默认同 file_version
+	ProductName     string `toml:"product_name"`     // 产品名称，默认为二进制名称
+	CompanyName     string `toml:"company_name"`     // 公司名称
+	FileDescription string `toml:"file_description"` // 文件说明，默认同 product_name
+	Copyright       string `toml:"copyright"`        // 版权信息 (LegalCopyright)
+}
+
+// windowsExecutionLevels 与 windowsDPIAwareness 为 [windows] 中可选的取值
+var (
+	windowsExecutionLevels = []string{"asInvoker", "highestAvailable", "requireAdministrator"}
+	windowsDPIAwareness    = []string{"unaware", "system", "permonitor", "permonitorv2"}
+)
+
+// Enabled 检查是否配置了 [windows]，未配置时不生成资源文件
+func (w WindowsConfig) Enabled() bool {
+	return w != WindowsConfig{}
+}
+
+// HasManifest 检查是否需要嵌入 manifest
+func (w WindowsConfig) HasManifest() bool {
+	return w.Manifest != "" || w.ExecutionLevel != "" || w.DPIAwareness != "" || w.LongPathAware
+}
+
+func mergeWindows(base WindowsConfig, project WindowsConfig) WindowsConfig {
+	if project.Icon != "" {
+		base.Icon = project.Icon
+	}
+	if project.Manifest != "" {
+		base.Manifest = project.Manifest
+	}
+	if project.ExecutionLevel != "" {
+		base.ExecutionLevel = project.ExecutionLevel
+	}
+	if project.DPIAwareness != "" {
+		base.DPIAwareness = project.DPIAwareness
+	}
+	if project.LongPathAware {
+		base.LongPathAware = true
+	}
+	if project.FileVersion != "" {
+		base.FileVersion = project.FileVersion
+	}
+	if project.ProductVersion != "" {
+		base.ProductVersion = project.ProductVersion
+	}
+	if project.ProductName != "" {
+		base.ProductName = project.ProductName
+	}
+	if project.CompanyName != "" {
+		base.CompanyName = project.CompanyName
+	}
+	if project.FileDescription != "" {
+		base.FileDescription = project.FileDescription
+	}
+	if project.Copyright != "" {
+		base.Copyright = project.Copyright
+	}
+	return base
+}
+
+// validateWindows 校验 [windows] 配置
+func (c *GocarConfig) validateWindows(projectRoot string) error {
+	windows := c.Windows
+	for _, file := range []struct{ key, path string }{{"icon", windows.Icon}, {"manifest", windows.Manifest}} {
+		key, path := file.key, file.path
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectRoot, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("[windows].%s: %w", key, err)
+		}
+	}
+	if windows.ExecutionLevel != "" && !slices.Contains(windowsExecutionLevels, windows.ExecutionLevel) {
+		return fmt.Errorf("[windows].execution_level %q must be one of: %v", windows.ExecutionLevel, windowsExecutionLevels)
+	}
+	if windows.DPIAwareness != "" && !slices.Contains(windowsDPIAwareness, windows.DPIAwareness) {
+		return fmt.Errorf("[windows].dpi_awareness %q must be one of: %v", windows.DPIAwareness, windowsDPIAwareness)
+	}
+	return nil
+}
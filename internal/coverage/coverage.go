@@ -0,0 +1,205 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Block 文本覆盖率 profile (go test -coverprofile / go tool covdata textfmt) 中的一个语句块
+type Block struct {
+	File       string
+	StartLine  int
+	StartCol   int
+	EndLine    int
+	EndCol     int
+	Statements int
+	Count      int
+}
+
+// Profile 文本覆盖率 profile
+type Profile struct {
+	Mode   string // set、count 或 atomic
+	Blocks []Block
+}
+
+// Package 单个包的语句覆盖率
+type Package struct {
+	Package    string  `json:"package"`
+	Statements int     `json:"statements"`
+	Covered    int     `json:"covered"`
+	Percent    float64 `json:"percent"`
+}
+
+// Parse 解析文本覆盖率 profile
+func Parse(r io.Reader) (*Profile, error) {
+	profile := &Profile{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if mode, ok := strings.CutPrefix(text, "mode: "); ok {
+			if profile.Mode != "" && profile.Mode != mode {
+				return nil, fmt.Errorf("line %d: mode %q conflicts with %q", line, mode, profile.Mode)
+			}
+			profile.Mode = mode
+			continue
+		}
+		block, err := parseBlock(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		profile.Blocks = append(profile.Blocks, block)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if profile.Mode == "" {
+		return nil, fmt.Errorf("missing mode line")
+	}
+	return profile, nil
+}
+
+// parseBlock 解析 "file.go:startLine.startCol,endLine.endCol statements count"
+func parseBlock(text string) (Block, error) {
+	colon := strings.LastIndex(text, ":")
+	if colon < 0 {
+		return Block{}, fmt.Errorf("invalid block %q", text)
+	}
+	fields := strings.Fields(text[colon+1:])
+	if len(fields) != 3 {
+		return Block{}, fmt.Errorf("invalid block %q", text)
+	}
+
+	block := Block{File: text[:colon]}
+	start, end, ok := strings.Cut(fields[0], ",")
+	if !ok {
+		return Block{}, fmt.Errorf("invalid block range %q", fields[0])
+	}
+	var err error
+	if block.StartLine, block.StartCol, err = parsePosition(start); err != nil {
+		return Block{}, err
+	}
+	if block.EndLine, block.EndCol, err = parsePosition(end); err != nil {
+		return Block{}, err
+	}
+	if block.Statements, err = strconv.Atoi(fields[1]); err != nil {
+		return Block{}, fmt.Errorf("invalid statement count %q", fields[1])
+	}
+	if block.Count, err = strconv.Atoi(fields[2]); err != nil {
+		return Block{}, fmt.Errorf("invalid hit count %q", fields[2])
+	}
+	return block, nil
+}
+
+func parsePosition(s string) (int, int, error) {
+	line, col, ok := strings.Cut(s, ".")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid position %q", s)
+	}
+	l, err := strconv.Atoi(line)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid position %q", s)
+	}
+	c, err := strconv.Atoi(col)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid position %q", s)
+	}
+	return l, c, nil
+}
+
+// Merge 合并多个 profile，同一语句块的计数相加 (set 模式下取并集)
+func Merge(profiles ...*Profile) (*Profile, error) {
+	merged := &Profile{}
+	index := map[Block]int{}
+	for _, profile := range profiles {
+		if merged.Mode == "" {
+			merged.Mode = profile.Mode
+		} else if profile.Mode != merged.Mode {
+			return nil, fmt.Errorf("cannot merge coverage mode %q with %q", profile.Mode, merged.Mode)
+		}
+		for _, block := range profile.Blocks {
+			key := block
+			key.Count = 0
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(merged.Blocks)
+				merged.Blocks = append(merged.Blocks, block)
+				continue
+			}
+			if merged.Mode == "set" {
+				merged.Blocks[i].Count = max(merged.Blocks[i].Count, block.Count)
+			} else {
+				merged.Blocks[i].Count += block.Count
+			}
+		}
+	}
+	return merged, nil
+}
+
+// Write 以文本格式输出 profile，可交给 go tool cover 使用
+func (p *Profile) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "mode: %s\n", p.Mode); err != nil {
+		return err
+	}
+	for _, b := range p.Blocks {
+		if _, err := fmt.Fprintf(w, "%s:%d.%d,%d.%d %d %d\n", b.File, b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.Statements, b.Count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Packages 按包 (文件所在的导入路径目录) 汇总语句覆盖率，按包名排序
+func (p *Profile) Packages() []Package {
+	byName := map[string]*Package{}
+	for _, block := range p.Blocks {
+		name := path.Dir(block.File)
+		pkg, ok := byName[name]
+		if !ok {
+			pkg = &Package{Package: name}
+			byName[name] = pkg
+		}
+		pkg.Statements += block.Statements
+		if block.Count > 0 {
+			pkg.Covered += block.Statements
+		}
+	}
+
+	packages := make([]Package, 0, len(byName))
+	for _, pkg := range byName {
+		pkg.Percent = percent(pkg.Covered, pkg.Statements)
+		packages = append(packages, *pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Package < packages[j].Package
+	})
+	return packages
+}
+
+// Total 汇总所有包的语句覆盖率
+func Total(packages []Package) Package {
+	total := Package{Package: "total"}
+	for _, pkg := range packages {
+		total.Statements += pkg.Statements
+		total.Covered += pkg.Covered
+	}
+	total.Percent = percent(total.Covered, total.Statements)
+	return total
+}
+
+func percent(covered, statements int) float64 {
+	if statements == 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(statements)
+}
@@ -0,0 +1,76 @@
+package coverage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseAndPackages(t *testing.T) {
+	profile, err := Parse(strings.NewReader(`mode: set
+example.com/app/internal/calc/calc.go:3.20,5.2 2 1
+example.com/app/internal/calc/calc.go:7.20,9.2 2 0
+example.com/app/cmd/app/main.go:5.13,7.2 1 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Mode != "set" || len(profile.Blocks) != 3 {
+		t.Fatalf("Parse() = %+v", profile)
+	}
+	if block := profile.Blocks[0]; block.StartLine != 3 || block.StartCol != 20 || block.EndLine != 5 || block.EndCol != 2 || block.Statements != 2 || block.Count != 1 {
+		t.Fatalf("first block = %+v", block)
+	}
+
+	packages := profile.Packages()
+	want := []Package{
+		{Package: "example.com/app/cmd/app", Statements: 1, Covered: 1, Percent: 100},
+		{Package: "example.com/app/internal/calc", Statements: 4, Covered: 2, Percent: 50},
+	}
+	if len(packages) != len(want) {
+		t.Fatalf("Packages() = %+v", packages)
+	}
+	for i := range want {
+		if packages[i] != want[i] {
+			t.Fatalf("Packages()[%d] = %+v, want %+v", i, packages[i], want[i])
+		}
+	}
+	if total := Total(packages); total.Statements != 5 || total.Covered != 3 || total.Percent != 60 {
+		t.Fatalf("Total() = %+v", total)
+	}
+}
+
+func TestParseRejectsInvalidProfiles(t *testing.T) {
+	for _, input := range []string{
+		"a.go:1.1,2.2 1 1\n",
+		"mode: set\na.go:1.1 1 1\n",
+		"mode: set\nmode: count\n",
+	} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Fatalf("Parse(%q) should fail", input)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	unit, _ := Parse(strings.NewReader("mode: count\npkg/a.go:1.1,2.2 1 3\npkg/a.go:3.1,4.2 1 0\n"))
+	e2e, _ := Parse(strings.NewReader("mode: count\npkg/a.go:3.1,4.2 1 2\npkg/b.go:1.1,2.2 1 0\n"))
+	merged, err := Merge(unit, e2e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := merged.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := "mode: count\npkg/a.go:1.1,2.2 1 3\npkg/a.go:3.1,4.2 1 2\npkg/b.go:1.1,2.2 1 0\n"
+	if out.String() != want {
+		t.Fatalf("merged profile =\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	set, _ := Parse(strings.NewReader("mode: set\n"))
+	if _, err := Merge(unit, set); err == nil {
+		t.Fatal("merging different modes should fail")
+	}
+}
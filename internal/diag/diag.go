@@ -0,0 +1,227 @@
+package diag
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 诊断来源
+const (
+	ToolBuild = "build"
+	ToolVet   = "vet"
+	ToolTest  = "test"
+)
+
+// 诊断级别
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic go build / go vet / go test 输出中的单条诊断
+type Diagnostic struct {
+	Tool     string `json:"tool,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Package  string `json:"package,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// HasLocation 检查诊断是否带有文件位置
+func (d Diagnostic) HasLocation() bool {
+	return d.File != "" && d.Line > 0
+}
+
+// locationPattern 匹配 file.go:line[:col]: message
+var locationPattern = regexp.MustCompile(`^(.+?\.[A-Za-z]+):(\d+)(?::(\d+))?: (.*)$`)
+
+// testResultPattern 匹配 go test 的包结果行，如 "ok  \tpkg\t0.1s"、"FAIL\tpkg [build failed]"
+var testResultPattern = regexp.MustCompile(`^(ok|FAIL|\?)\s+(\S+)`)
+
+// testRunPattern 匹配 go test -v 中测试开始或切换输出的行，如 "=== RUN   TestAdd"
+var testRunPattern = regexp.MustCompile(`^=== (?:RUN|CONT|NAME|PAUSE)\s+(\S+)`)
+
+// testStatusPattern 匹配测试结果行，如 "--- FAIL: TestAdd (0.00s)"，子测试的结果行带缩进
+var testStatusPattern = regexp.MustCompile(`^--- (PASS|FAIL|SKIP): (\S+)`)
+
+// Parse 从工具输出中解析诊断。
+// "# package" 标题行用于记录所属包；build/vet 中无法识别位置的行作为仅包含 message 的诊断保留。
+// go test 的输出由 parseTest 解析。
+func Parse(tool string, output []byte) []Diagnostic {
+	if tool == ToolTest {
+		return parseTest(output)
+	}
+	severity := SeverityError
+	if tool == ToolVet {
+		severity = SeverityWarning
+	}
+
+	var diagnostics []Diagnostic
+	pkg := ""
+	for _, raw := range strings.Split(string(output), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			pkg, _, _ = strings.Cut(strings.TrimPrefix(line, "# "), " ")
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "vet: ")
+		d, ok := parseLocation(tool, severity, pkg, line)
+		if !ok {
+			d = Diagnostic{Tool: tool, Severity: severity, Package: pkg, Message: line}
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// parseTest 解析 go test 输出，只保留带位置的行：编译错误，以及失败测试中 t.Error 等输出。
+// 不带 -v 时测试的输出在其 "--- FAIL:" 行之后，带 -v 时在 "=== RUN" 行与结果行之间；
+// 通过的测试中 t.Log 输出的行被忽略。诊断的去留与所属包在读到包结果行时确定。
+func parseTest(output []byte) []Diagnostic {
+	var diagnostics []Diagnostic
+	var block []Diagnostic // 当前包中待定的诊断
+	var owners []string    // block 中每条诊断所属的测试，编译错误为空
+	status := map[string]string{}
+	current := ""
+
+	// finish 在包结果行处确定待定诊断的去留：编译错误与失败测试的输出保留，
+	// 没有结果行的测试 (panic、超时) 在包失败时保留
+	finish := func(result, pkg string) {
+		for i, d := range block {
+			if pkg != "" {
+				d.Package = pkg
+			}
+			owner := owners[i]
+			if owner == "" || status[owner] == "FAIL" || (status[owner] == "" && result == "FAIL") {
+				diagnostics = append(diagnostics, d)
+			}
+		}
+		block, owners = nil, nil
+		status = map[string]string{}
+		current = ""
+	}
+
+	pkg := ""
+	for _, raw := range strings.Split(string(output), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			pkg, _, _ = strings.Cut(strings.TrimPrefix(line, "# "), " ")
+			current = ""
+			continue
+		}
+		if match := testResultPattern.FindStringSubmatch(raw); match != nil {
+			finish(match[1], match[2])
+			pkg = ""
+			continue
+		}
+		if match := testRunPattern.FindStringSubmatch(line); match != nil {
+			current = match[1]
+			continue
+		}
+		if match := testStatusPattern.FindStringSubmatch(line); match != nil {
+			status[match[2]] = match[1]
+			current = match[2]
+			continue
+		}
+		if line == "FAIL" || line == "PASS" {
+			continue
+		}
+
+		d, ok := parseLocation(ToolTest, SeverityError, pkg, line)
+		if !ok {
+			// 缩进更深的行是上一条测试失败信息的续行
+			if len(block) > 0 && strings.HasPrefix(raw, "        ") {
+				last := &block[len(block)-1]
+				last.Message += "\n" + line
+			}
+			continue
+		}
+		block = append(block, d)
+		owners = append(owners, current)
+	}
+	finish("", "")
+	return diagnostics
+}
+
+// parseLocation 解析 file.go:line[:col]: message 形式的行
+func parseLocation(tool, severity, pkg, line string) (Diagnostic, bool) {
+	match := locationPattern.FindStringSubmatch(line)
+	if match == nil {
+		return Diagnostic{}, false
+	}
+	d := Diagnostic{Tool: tool, Severity: severity, Package: pkg, File: match[1], Message: match[4]}
+	d.Line, _ = strconv.Atoi(match[2])
+	if match[3] != "" {
+		d.Column, _ = strconv.Atoi(match[3])
+	}
+	return d, true
+}
+
+// Resolve 将诊断中的文件路径规范为相对 root 的路径。
+// go test 的失败信息只包含文件名，此时根据所属包的目录 (go list) 补全路径。
+func Resolve(root string, diagnostics []Diagnostic) {
+	dirs := map[string]string{}
+	for i := range diagnostics {
+		d := &diagnostics[i]
+		if d.File == "" {
+			continue
+		}
+		path := d.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		if _, err := os.Stat(path); err != nil && d.Package != "" {
+			dir, ok := dirs[d.Package]
+			if !ok {
+				dir = packageDir(root, d.Package)
+				dirs[d.Package] = dir
+			}
+			if dir != "" {
+				if _, err := os.Stat(filepath.Join(dir, d.File)); err == nil {
+					path = filepath.Join(dir, d.File)
+				}
+			}
+		}
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			d.File = rel
+		}
+	}
+}
+
+// packageDir 返回包所在目录，失败时返回空字符串
+func packageDir(root, pkg string) string {
+	cmd := exec.Command("go", "list", "-find", "-f", "{{.Dir}}", pkg)
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Dedupe 去除重复的诊断 (多目标构建会报告相同的错误)，保持原有顺序
+func Dedupe(diagnostics []Diagnostic) []Diagnostic {
+	seen := map[Diagnostic]bool{}
+	unique := []Diagnostic{}
+	for _, d := range diagnostics {
+		if !seen[d] {
+			seen[d] = true
+			unique = append(unique, d)
+		}
+	}
+	return unique
+}
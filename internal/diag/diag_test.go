@@ -0,0 +1,178 @@
+package diag
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseBuildOutput(t *testing.T) {
+	output := []byte("# example.com/api/cmd/api\ncmd/api/main.go:4:2: declared and not used: x\ncmd/api/util.go:10: something odd\ngo: unsupported GOOS/GOARCH pair linux/nope\n")
+	got := Parse(ToolBuild, output)
+	want := []Diagnostic{
+		{Tool: ToolBuild, Severity: SeverityError, Package: "example.com/api/cmd/api", File: "cmd/api/main.go", Line: 4, Column: 2, Message: "declared and not used: x"},
+		{Tool: ToolBuild, Severity: SeverityError, Package: "example.com/api/cmd/api", File: "cmd/api/util.go", Line: 10, Message: "something odd"},
+		{Tool: ToolBuild, Severity: SeverityError, Package: "example.com/api/cmd/api", Message: "go: unsupported GOOS/GOARCH pair linux/nope"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Parse() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseVetAndTestOutput(t *testing.T) {
+	vet := Parse(ToolVet, []byte("# example.com/api\nvet: ./a.go:3:2: unreachable code\n"))
+	if len(vet) != 1 || vet[0].File != "./a.go" || vet[0].Severity != SeverityWarning {
+		t.Fatalf("Parse(vet) = %#v", vet)
+	}
+
+	output := []byte(`--- FAIL: TestAdd (0.00s)
+    add_test.go:12: Add(1, 2) = 4
+        want 3
+FAIL
+FAIL	example.com/api/internal/calc	0.002s
+# example.com/api/internal/web [example.com/api/internal/web.test]
+internal/web/web_test.go:8:2: undefined: handler
+FAIL	example.com/api/internal/web [build failed]
+ok  	example.com/api/internal/util	0.001s
+`)
+	got := Parse(ToolTest, output)
+	want := []Diagnostic{
+		{Tool: ToolTest, Severity: SeverityError, Package: "example.com/api/internal/calc", File: "add_test.go", Line: 12, Message: "Add(1, 2) = 4\nwant 3"},
+		{Tool: ToolTest, Severity: SeverityError, Package: "example.com/api/internal/web", File: "internal/web/web_test.go", Line: 8, Column: 2, Message: "undefined: handler"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Parse(test) = %#v, want %#v", got, want)
+	}
+
+	// -v 时通过的测试 (包括失败测试中通过的子测试) 的 t.Log 输出不是诊断
+	verbose := []byte(`=== RUN   TestPass
+    a_test.go:5: just logging
+--- PASS: TestPass (0.00s)
+=== RUN   TestFail
+=== RUN   TestFail/ok
+    a_test.go:7: sub log
+=== RUN   TestFail/bad
+    a_test.go:8: got 1
+        want 2
+--- FAIL: TestFail (0.00s)
+    --- PASS: TestFail/ok (0.00s)
+    --- FAIL: TestFail/bad (0.00s)
+FAIL
+FAIL	example.com/dt/a	0.002s
+# example.com/dt/b
+# [example.com/dt/b]
+b/b_test.go:5:42: fmt.Printf format %d has arg "x" of wrong type string
+FAIL	example.com/dt/b [build failed]
+=== RUN   TestOk
+    c_test.go:5: fine
+--- PASS: TestOk (0.00s)
+PASS
+ok  	example.com/dt/c	0.002s
+FAIL
+`)
+	got = Parse(ToolTest, verbose)
+	want = []Diagnostic{
+		{Tool: ToolTest, Severity: SeverityError, Package: "example.com/dt/a", File: "a_test.go", Line: 8, Message: "got 1\nwant 2"},
+		{Tool: ToolTest, Severity: SeverityError, Package: "example.com/dt/b", File: "b/b_test.go", Line: 5, Column: 42, Message: `fmt.Printf format %d has arg "x" of wrong type string`},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Parse(test -v) = %#v, want %#v", got, want)
+	}
+}
+
+func TestRenderSnippet(t *testing.T) {
+	root := t.TempDir()
+	source := "package main\n\nfunc main() {\n\tx := 1\n}\n"
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	RenderOutput(&out, root, ToolBuild, []byte("# example.com/app\n./main.go:4:2: declared and not used: x\nnote: module requires Go 1.99\n"))
+	want := "error: declared and not used: x\n" +
+		" --> main.go:4:2\n" +
+		"  |\n" +
+		"4 |     x := 1\n" +
+		"  |     ^\n" +
+		"\n" +
+		"note: module requires Go 1.99\n"
+	if out.String() != want {
+		t.Fatalf("RenderOutput() =\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	diagnostics := []Diagnostic{
+		{Tool: ToolVet, Severity: SeverityWarning, File: filepath.Join("internal", "a.go"), Line: 3, Column: 2, Message: "unreachable code"},
+		{Tool: ToolBuild, Severity: SeverityError, Message: "go: no Go files"},
+	}
+	var out bytes.Buffer
+	if err := WriteSARIF(&out, "/src/app", "1.0.0", diagnostics); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			OriginalURIBaseIDs map[string]struct {
+				URI string `json:"uri"`
+			} `json:"originalUriBaseIds"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string `json:"uri"`
+							URIBaseID string `json:"uriBaseId"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v\n%s", err, out.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log: %s", out.String())
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "gocar" || len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("driver = %+v", run.Tool.Driver)
+	}
+	if uri := run.OriginalURIBaseIDs["SRCROOT"].URI; uri != "file:///src/app/" {
+		t.Fatalf("SRCROOT = %q", uri)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("results = %+v", run.Results)
+	}
+	first := run.Results[0]
+	location := first.Locations[0].PhysicalLocation
+	if first.RuleID != "go-vet" || first.Level != "warning" || location.ArtifactLocation.URI != "internal/a.go" ||
+		location.ArtifactLocation.URIBaseID != "SRCROOT" || location.Region.StartLine != 3 || location.Region.StartColumn != 2 {
+		t.Fatalf("first result = %+v", first)
+	}
+	if second := run.Results[1]; second.Level != "error" || len(second.Locations) != 0 {
+		t.Fatalf("second result = %+v", second)
+	}
+	if !strings.Contains(out.String(), `"$schema"`) {
+		t.Fatal("SARIF log should declare $schema")
+	}
+}
@@ -0,0 +1,88 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tabWidth 渲染源码片段时制表符展开的宽度
+const tabWidth = 4
+
+// Render 以终端友好的形式输出诊断：带位置的诊断附带源码片段和指向列的 ^，
+// 其余诊断按原文输出
+func Render(w io.Writer, root string, diagnostics []Diagnostic) {
+	sources := map[string][]string{}
+	for _, d := range diagnostics {
+		if !d.HasLocation() {
+			fmt.Fprintln(w, d.Message)
+			continue
+		}
+
+		message, notes, _ := strings.Cut(d.Message, "\n")
+		fmt.Fprintf(w, "%s: %s\n", d.Severity, message)
+		location := fmt.Sprintf("%s:%d", d.File, d.Line)
+		if d.Column > 0 {
+			location += ":" + strconv.Itoa(d.Column)
+		}
+
+		lines, ok := sources[d.File]
+		if !ok {
+			lines = readLines(root, d.File)
+			sources[d.File] = lines
+		}
+		gutter := strings.Repeat(" ", len(strconv.Itoa(d.Line)))
+		fmt.Fprintf(w, "%s--> %s\n", gutter, location)
+		if d.Line <= len(lines) {
+			source := lines[d.Line-1]
+			fmt.Fprintf(w, "%s |\n", gutter)
+			fmt.Fprintf(w, "%d | %s\n", d.Line, expandTabs(source))
+			if d.Column > 0 {
+				fmt.Fprintf(w, "%s | %s^\n", gutter, strings.Repeat(" ", displayWidth(source, d.Column)))
+			}
+		}
+		for _, note := range strings.Split(notes, "\n") {
+			if note != "" {
+				fmt.Fprintf(w, "%s = %s\n", gutter, note)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// readLines 读取源文件，失败时返回 nil (不输出片段)
+func readLines(root, file string) []string {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", strings.Repeat(" ", tabWidth))
+}
+
+// displayWidth 返回第 column 个字节 (从 1 开始) 之前的内容展开制表符后的显示宽度
+func displayWidth(line string, column int) int {
+	prefix := line
+	if column-1 < len(line) {
+		prefix = line[:column-1]
+	}
+	return utf8.RuneCountInString(expandTabs(prefix))
+}
+
+// RenderOutput 解析工具输出并渲染其中的诊断，无法识别位置的行按原文输出
+func RenderOutput(w io.Writer, root, tool string, output []byte) {
+	diagnostics := Parse(tool, output)
+	Resolve(root, diagnostics)
+	Render(w, root, diagnostics)
+}
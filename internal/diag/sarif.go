@@ -0,0 +1,144 @@
+package diag
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// SARIF 2.1.0 输出，供代码审查机器人与 IDE 标注使用
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifRootID  = "SRCROOT"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// ruleDescriptions 每个工具对应的规则说明，规则 ID 为 go-<tool>
+var ruleDescriptions = map[string]string{
+	ToolBuild: "go build error",
+	ToolVet:   "go vet finding",
+	ToolTest:  "go test failure",
+}
+
+// WriteSARIF 以 SARIF 2.1.0 格式输出诊断。文件路径相对 root，通过 SRCROOT 基准映射为绝对路径。
+func WriteSARIF(w io.Writer, root, version string, diagnostics []Diagnostic) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "gocar",
+			Version:        version,
+			InformationURI: "https://github.com/uselibrary/gocar",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	if root != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{
+			sarifRootID: {URI: fileURI(root) + "/"},
+		}
+	}
+
+	rules := map[string]bool{}
+	for _, d := range diagnostics {
+		ruleID := "go-" + d.Tool
+		if !rules[ruleID] {
+			rules[ruleID] = true
+			description := ruleDescriptions[d.Tool]
+			if description == "" {
+				description = d.Tool
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: ruleID, ShortDescription: sarifMessage{Text: description}})
+		}
+
+		level := "error"
+		if d.Severity == SeverityWarning {
+			level = "warning"
+		}
+		result := sarifResult{RuleID: ruleID, Level: level, Message: sarifMessage{Text: d.Message}}
+		if d.HasLocation() {
+			location := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(d.File)},
+				Region:           &sarifRegion{StartLine: d.Line, StartColumn: d.Column},
+			}
+			if !filepath.IsAbs(d.File) {
+				location.ArtifactLocation.URIBaseID = sarifRootID
+			} else {
+				location.ArtifactLocation.URI = fileURI(d.File)
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// fileURI 将绝对路径转换为 file:// URI
+func fileURI(path string) string {
+	uri := strings.TrimSuffix(filepath.ToSlash(path), "/")
+	if !strings.HasPrefix(uri, "/") {
+		uri = "/" + uri // Windows 盘符路径
+	}
+	return (&url.URL{Scheme: "file", Path: uri}).String()
+}
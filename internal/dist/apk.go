@@ -0,0 +1,120 @@
+package dist
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// apkScripts apk 安装脚本在控制段中的文件名
+var apkScripts = []struct {
+	name   string
+	script func(PackageScripts) string
+}{
+	{".pre-install", func(s PackageScripts) string { return s.PreInstall }},
+	{".post-install", func(s PackageScripts) string { return s.PostInstall }},
+	{".pre-deinstall", func(s PackageScripts) string { return s.PreRemove }},
+	{".post-deinstall", func(s PackageScripts) string { return s.PostRemove }},
+}
+
+// writeAPK 写入 apk (v2) 包：两段拼接的 gzip 流，控制段 (.PKGINFO 与脚本，不含 tar 结束块) 在前，数据段在后。
+// 包未签名，安装时需要 apk add --allow-untrusted。
+func (p *LinuxPackage) writeAPK(w io.Writer) error {
+	entries, err := p.entries()
+	if err != nil {
+		return err
+	}
+
+	// 数据段中每个文件带有 APK-TOOLS.checksum.SHA1，apk 用它校验安装的文件
+	dataFiles := []tarEntry{}
+	for _, dir := range parentDirs(entries) {
+		dataFiles = append(dataFiles, tarEntry{name: strings.TrimPrefix(dir, "/") + "/", mode: 0755, dir: true})
+	}
+	for _, entry := range entries {
+		dataFiles = append(dataFiles, tarEntry{
+			name:       strings.TrimPrefix(entry.Dest, "/"),
+			data:       entry.data,
+			mode:       int64(entry.mode),
+			paxRecords: map[string]string{"APK-TOOLS.checksum.SHA1": fmt.Sprintf("%x", sha1.Sum(entry.data))},
+		})
+	}
+	dataTar, err := tarGz(dataFiles, p.ModTime, true)
+	if err != nil {
+		return err
+	}
+
+	pkginfo, err := p.apkPkginfo(entries, sha256Hex(dataTar))
+	if err != nil {
+		return err
+	}
+	controlFiles := []tarEntry{{name: ".PKGINFO", data: []byte(pkginfo), mode: 0644}}
+	for _, script := range apkScripts {
+		if content := script.script(p.Scripts); content != "" {
+			controlFiles = append(controlFiles, tarEntry{name: script.name, data: []byte(content), mode: 0755})
+		}
+	}
+	controlTar, err := tarGz(controlFiles, p.ModTime, false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(controlTar); err != nil {
+		return err
+	}
+	_, err = w.Write(dataTar)
+	return err
+}
+
+// apkPkginfo 生成 .PKGINFO，datahash 为数据段的 sha256
+func (p *LinuxPackage) apkPkginfo(entries []packageEntry, datahash string) (string, error) {
+	version, err := APKVersion(p.Version, p.Release)
+	if err != nil {
+		return "", err
+	}
+	arch, err := LinuxArch(FormatAPK, p.Arch, p.Variant)
+	if err != nil {
+		return "", err
+	}
+	summary, _ := p.summaryAndDescription()
+
+	var b strings.Builder
+	b.WriteString("# Generated by gocar\n")
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s = %s\n", name, value)
+		}
+	}
+	field("pkgname", p.Name)
+	field("pkgver", version)
+	field("pkgdesc", summary)
+	field("url", p.Homepage)
+	field("builddate", fmt.Sprint(p.ModTime.Unix()))
+	field("packager", p.Maintainer)
+	field("maintainer", p.Maintainer)
+	field("size", fmt.Sprint(installedSize(entries)))
+	field("arch", arch)
+	field("origin", p.Name)
+	field("license", p.License)
+	for _, dep := range p.Depends {
+		field("depend", apkDependency(dep))
+	}
+	// apk 用 !name 表示冲突
+	for _, dep := range p.Conflicts {
+		field("depend", "!"+apkDependency(dep))
+	}
+	for _, dep := range p.Provides {
+		field("provides", apkDependency(dep))
+	}
+	for _, dep := range p.Replaces {
+		field("replaces", apkDependency(dep))
+	}
+	field("datahash", datahash)
+	return b.String(), nil
+}
+
+// apkDependency 将依赖格式化为 apk 的写法: name>=1.0
+func apkDependency(item string) string {
+	dep, _ := ParseDependency(item)
+	return dep.Name + dep.Op + dep.Version
+}
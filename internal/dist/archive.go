@@ -0,0 +1,202 @@
+package dist
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// File 归档中的单个文件
+type File struct {
+	Source string      // 本地文件路径
+	Name   string      // 归档内路径 (使用 / 分隔)
+	Mode   fs.FileMode // 文件权限，为 0 时使用源文件权限
+}
+
+// ArchiveExt 返回目标系统对应的归档扩展名：windows 使用 .zip，其余使用 .tar.gz
+func ArchiveExt(goos string) string {
+	if goos == "windows" {
+		return ".zip"
+	}
+	return ".tar.gz"
+}
+
+// ArchiveBaseName 返回归档基础名 <name>-<version>-<os>-<arch>[-<variant>]
+func ArchiveBaseName(name, version, goos, goarch, variant string) string {
+	if version == "" {
+		version = "dev"
+	}
+	baseName := fmt.Sprintf("%s-%s-%s-%s", name, version, goos, goarch)
+	if variant != "" {
+		baseName += "-" + variant
+	}
+	return baseName
+}
+
+// WriteArchive 根据扩展名写入 .tar.gz 或 .zip 归档
+func WriteArchive(archivePath string, files []File) error {
+	switch {
+	case strings.HasSuffix(archivePath, ".zip"):
+		return WriteZip(archivePath, files)
+	case strings.HasSuffix(archivePath, ".tar.gz"), strings.HasSuffix(archivePath, ".tgz"):
+		return WriteTarGz(archivePath, files)
+	default:
+		return fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+	}
+}
+
+// WriteTarGz 写入 gzip 压缩的 tar 归档
+func WriteTarGz(archivePath string, files []File) error {
+	return writeFileAtomic(archivePath, func(w io.Writer) error {
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		for _, file := range files {
+			info, err := os.Stat(file.Source)
+			if err != nil {
+				return err
+			}
+			header := &tar.Header{
+				Name:    file.Name,
+				Mode:    int64(fileMode(file, info).Perm()),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+				Format:  tar.FormatPAX,
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if err := copyFile(tw, file.Source); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gz.Close()
+	})
+}
+
+// WriteZip 写入 zip 归档
+func WriteZip(archivePath string, files []File) error {
+	return writeFileAtomic(archivePath, func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		for _, file := range files {
+			info, err := os.Stat(file.Source)
+			if err != nil {
+				return err
+			}
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = file.Name
+			header.Method = zip.Deflate
+			header.SetMode(fileMode(file, info))
+			entry, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			if err := copyFile(entry, file.Source); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+	})
+}
+
+// CollectIncludes 展开 [package].include 中的 glob，目录会被递归加入。
+// 返回的归档内路径相对于项目根目录并位于 prefix 之下。
+func CollectIncludes(projectRoot, prefix string, patterns []string) ([]File, error) {
+	var files []File
+	seen := map[string]bool{}
+	add := func(abs string) error {
+		rel, err := filepath.Rel(projectRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("include %s is outside the project root", abs)
+		}
+		if seen[rel] {
+			return nil
+		}
+		seen[rel] = true
+		files = append(files, File{Source: abs, Name: path.Join(prefix, filepath.ToSlash(rel))})
+		return nil
+	}
+
+	for _, pattern := range patterns {
+		if filepath.IsAbs(pattern) {
+			return nil, fmt.Errorf("include pattern %q must be relative to the project root", pattern)
+		}
+		matches, err := filepath.Glob(filepath.Join(projectRoot, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("include pattern %q matched no files", pattern)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+				return add(p)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+func fileMode(file File, info fs.FileInfo) fs.FileMode {
+	if file.Mode != 0 {
+		return file.Mode
+	}
+	return info.Mode()
+}
+
+func copyFile(w io.Writer, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// writeFileAtomic 先写入同目录临时文件，成功后再重命名为目标文件
+func writeFileAtomic(target string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
@@ -0,0 +1,143 @@
+package dist
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriteArchivesWithIncludes(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "bin", "api"), "binary")
+	writeFile(t, filepath.Join(root, "README.md"), "readme")
+	writeFile(t, filepath.Join(root, "configs", "app.yaml"), "config")
+
+	includes, err := CollectIncludes(root, "api-1.0.0-linux-amd64", []string{"README.md", "configs"})
+	if err != nil {
+		t.Fatalf("CollectIncludes() unexpected error: %v", err)
+	}
+	files := append([]File{{Source: filepath.Join(root, "bin", "api"), Name: "api-1.0.0-linux-amd64/api", Mode: 0755}}, includes...)
+	want := []string{"api-1.0.0-linux-amd64/api", "api-1.0.0-linux-amd64/README.md", "api-1.0.0-linux-amd64/configs/app.yaml"}
+
+	tarPath := filepath.Join(root, "dist", "api.tar.gz")
+	if err := WriteArchive(tarPath, files); err != nil {
+		t.Fatalf("WriteArchive(tar.gz) unexpected error: %v", err)
+	}
+	f, err := os.Open(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tarNames []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		tarNames = append(tarNames, header.Name)
+		if header.Name == want[0] && header.Mode != 0755 {
+			t.Fatalf("binary mode = %o, want 755", header.Mode)
+		}
+	}
+	if !slices.Equal(tarNames, want) {
+		t.Fatalf("tar entries = %#v, want %#v", tarNames, want)
+	}
+
+	zipPath := filepath.Join(root, "dist", "api.zip")
+	if err := WriteArchive(zipPath, files); err != nil {
+		t.Fatalf("WriteArchive(zip) unexpected error: %v", err)
+	}
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var zipNames []string
+	for _, file := range zr.File {
+		zipNames = append(zipNames, file.Name)
+	}
+	if !slices.Equal(zipNames, want) {
+		t.Fatalf("zip entries = %#v, want %#v", zipNames, want)
+	}
+
+	if _, err := CollectIncludes(root, "x", []string{"missing.txt"}); err == nil {
+		t.Fatal("expected unmatched include pattern to fail")
+	}
+	if _, err := CollectIncludes(root, "x", []string{"../outside"}); err == nil {
+		t.Fatal("expected include outside the project root to fail")
+	}
+}
+
+func TestWriteChecksumsMergesEntries(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.tar.gz")
+	b := filepath.Join(dir, "b.zip")
+	writeFile(t, a, "a")
+	writeFile(t, b, "b")
+
+	if err := WriteChecksums(dir, []string{a}); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, a, "a2")
+	if err := WriteChecksums(dir, []string{a, b}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ChecksumsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sumA, _ := SHA256File(a)
+	sumB, _ := SHA256File(b)
+	want := sumA + "  a.tar.gz\n" + sumB + "  b.zip\n"
+	if string(data) != want {
+		t.Fatalf("SHA256SUMS = %q, want %q", data, want)
+	}
+
+	// 已删除的归档 (如版本升级后的旧文件) 不再保留在 SHA256SUMS 中
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteChecksums(dir, []string{b}); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, ChecksumsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := sumB + "  b.zip\n"; string(data) != want {
+		t.Fatalf("SHA256SUMS after removing a.tar.gz = %q, want %q", data, want)
+	}
+}
+
+func TestArchiveNaming(t *testing.T) {
+	if got := ArchiveBaseName("api", "1.4.0", "linux", "amd64", "") + ArchiveExt("linux"); got != "api-1.4.0-linux-amd64.tar.gz" {
+		t.Fatalf("linux archive = %q", got)
+	}
+	if got := ArchiveBaseName("api", "", "windows", "arm64", "") + ArchiveExt("windows"); !strings.HasSuffix(got, "-dev-windows-arm64.zip") {
+		t.Fatalf("windows archive = %q", got)
+	}
+	if got := ArchiveBaseName("api", "1.4.0", "linux", "arm", "v7"); got != "api-1.4.0-linux-arm-v7" {
+		t.Fatalf("variant archive = %q", got)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
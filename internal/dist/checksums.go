@@ -0,0 +1,79 @@
+package dist
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChecksumsFileName 校验和文件名
+const ChecksumsFileName = "SHA256SUMS"
+
+// SHA256File 计算文件的 sha256
+func SHA256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteChecksums 将文件的 sha256 写入 dir/SHA256SUMS (sha256sum 兼容格式)。
+// 已有记录中同名文件会被更新，dir 中已不存在的文件 (如改名或版本升级后的旧归档) 的记录会被删除，
+// 其他记录保持不变。
+func WriteChecksums(dir string, paths []string) error {
+	sums := map[string]string{}
+
+	checksumsPath := filepath.Join(dir, ChecksumsFileName)
+	if f, err := os.Open(checksumsPath); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			sum, name, ok := strings.Cut(scanner.Text(), "  ")
+			if !ok {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				sums[name] = sum
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", ChecksumsFileName, err)
+		}
+	}
+
+	for _, path := range paths {
+		sum, err := SHA256File(path)
+		if err != nil {
+			return err
+		}
+		sums[filepath.Base(path)] = sum
+	}
+
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", sums[name], name)
+	}
+	return writeFileAtomic(checksumsPath, func(w io.Writer) error {
+		_, err := io.WriteString(w, b.String())
+		return err
+	})
+}
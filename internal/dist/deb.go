@@ -0,0 +1,223 @@
+package dist
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// debScripts deb 维护者脚本的文件名
+var debScripts = []struct {
+	name   string
+	script func(PackageScripts) string
+}{
+	{"preinst", func(s PackageScripts) string { return s.PreInstall }},
+	{"postinst", func(s PackageScripts) string { return s.PostInstall }},
+	{"prerm", func(s PackageScripts) string { return s.PreRemove }},
+	{"postrm", func(s PackageScripts) string { return s.PostRemove }},
+}
+
+// writeDeb 写入 deb 包：ar 归档中依次是 debian-binary、control.tar.gz 与 data.tar.gz
+func (p *LinuxPackage) writeDeb(w io.Writer) error {
+	entries, err := p.entries()
+	if err != nil {
+		return err
+	}
+	control, err := p.debControl(entries)
+	if err != nil {
+		return err
+	}
+
+	// control.tar.gz: control、md5sums、conffiles 与维护者脚本
+	var md5sums, conffiles strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&md5sums, "%x  %s\n", md5.Sum(entry.data), strings.TrimPrefix(entry.Dest, "/"))
+		if entry.Config {
+			fmt.Fprintf(&conffiles, "%s\n", entry.Dest)
+		}
+	}
+	controlFiles := []tarEntry{
+		{name: "./control", data: []byte(control), mode: 0644},
+		{name: "./md5sums", data: []byte(md5sums.String()), mode: 0644},
+	}
+	if conffiles.Len() > 0 {
+		controlFiles = append(controlFiles, tarEntry{name: "./conffiles", data: []byte(conffiles.String()), mode: 0644})
+	}
+	for _, script := range debScripts {
+		if content := script.script(p.Scripts); content != "" {
+			controlFiles = append(controlFiles, tarEntry{name: "./" + script.name, data: []byte(content), mode: 0755})
+		}
+	}
+	controlTar, err := tarGz(controlFiles, p.ModTime, true)
+	if err != nil {
+		return err
+	}
+
+	// data.tar.gz: 上级目录与安装的文件
+	dataFiles := []tarEntry{}
+	for _, dir := range parentDirs(entries) {
+		dataFiles = append(dataFiles, tarEntry{name: "." + dir + "/", mode: 0755, dir: true})
+	}
+	for _, entry := range entries {
+		dataFiles = append(dataFiles, tarEntry{name: "." + entry.Dest, data: entry.data, mode: int64(entry.mode)})
+	}
+	dataTar, err := tarGz(dataFiles, p.ModTime, true)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "!<arch>\n"); err != nil {
+		return err
+	}
+	for _, member := range []struct {
+		name string
+		data []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", controlTar},
+		{"data.tar.gz", dataTar},
+	} {
+		if err := writeArMember(w, member.name, member.data, p.ModTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// debControl 生成 control 文件
+func (p *LinuxPackage) debControl(entries []packageEntry) (string, error) {
+	version, err := DebVersion(p.Version, p.Release)
+	if err != nil {
+		return "", err
+	}
+	arch, err := LinuxArch(FormatDeb, p.Arch, p.Variant)
+	if err != nil {
+		return "", err
+	}
+	summary, description := p.summaryAndDescription()
+
+	var b strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	field("Package", p.Name)
+	field("Version", version)
+	field("Architecture", arch)
+	field("Maintainer", p.Maintainer)
+	field("Installed-Size", fmt.Sprint((installedSize(entries)+1023)/1024))
+	field("Depends", debDependencies(p.Depends))
+	field("Conflicts", debDependencies(p.Conflicts))
+	field("Provides", debDependencies(p.Provides))
+	field("Replaces", debDependencies(p.Replaces))
+	field("Section", p.Section)
+	field("Priority", p.Priority)
+	field("Homepage", p.Homepage)
+
+	// 详细描述的每行以空格开头，空行写为 " ."
+	fmt.Fprintf(&b, "Description: %s\n", summary)
+	if description != "" {
+		for _, line := range strings.Split(description, "\n") {
+			if line = strings.TrimRight(line, " \t"); line == "" {
+				line = "."
+			}
+			fmt.Fprintf(&b, " %s\n", line)
+		}
+	}
+	return b.String(), nil
+}
+
+// debDependencies 将依赖列表格式化为 deb 的写法: name (>= 1.0), other
+func debDependencies(list []string) string {
+	parts := make([]string, 0, len(list))
+	for _, item := range list {
+		dep, _ := ParseDependency(item)
+		switch dep.Op {
+		case "":
+			parts = append(parts, dep.Name)
+		case "<":
+			parts = append(parts, fmt.Sprintf("%s (<< %s)", dep.Name, dep.Version))
+		case ">":
+			parts = append(parts, fmt.Sprintf("%s (>> %s)", dep.Name, dep.Version))
+		default:
+			parts = append(parts, fmt.Sprintf("%s (%s %s)", dep.Name, dep.Op, dep.Version))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeArMember 写入 ar 归档成员：60 字节的文本头，内容按 2 字节对齐
+func writeArMember(w io.Writer, name string, data []byte, modTime time.Time) error {
+	header := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8o%-10d`\n", name, modTime.Unix(), 0, 0, 0100644, len(data))
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if len(data)%2 != 0 {
+		_, err := w.Write([]byte{'\n'})
+		return err
+	}
+	return nil
+}
+
+// tarEntry 写入 tar 的文件或目录
+type tarEntry struct {
+	name       string
+	data       []byte
+	mode       int64
+	dir        bool
+	paxRecords map[string]string
+}
+
+// tarGz 将条目写为 gzip 压缩的 tar，属主为 root。
+// terminate 为 false 时不写 tar 结束块 (apk 的控制段需要与数据段拼接为一个 tar)。
+func tarGz(entries []tarEntry, modTime time.Time, terminate bool) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:       entry.name,
+			Mode:       entry.mode,
+			Size:       int64(len(entry.data)),
+			ModTime:    modTime,
+			Uname:      "root",
+			Gname:      "root",
+			Typeflag:   tar.TypeReg,
+			PAXRecords: entry.paxRecords,
+			Format:     tar.FormatGNU,
+		}
+		if entry.dir {
+			header.Typeflag = tar.TypeDir
+			header.Size = 0
+		}
+		if len(entry.paxRecords) > 0 {
+			header.Format = tar.FormatPAX
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			return nil, err
+		}
+	}
+	finish := tw.Flush
+	if terminate {
+		finish = tw.Close
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
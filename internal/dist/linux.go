@@ -0,0 +1,367 @@
+package dist
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Linux 软件包格式
+const (
+	FormatDeb = "deb"
+	FormatRPM = "rpm"
+	FormatAPK = "apk"
+)
+
+// LinuxFormats 支持的 Linux 软件包格式
+var LinuxFormats = []string{FormatDeb, FormatRPM, FormatAPK}
+
+// LinuxPackage 一个 Linux 软件包的元数据与内容
+type LinuxPackage struct {
+	Name        string
+	Version     string // 上游版本号，如 1.2.3、v1.2.3-rc.1 或 git describe 的输出
+	Release     int    // 打包修订号，deb 的 -N、rpm 的 Release、apk 的 -r(N-1)
+	Arch        string // GOARCH
+	Variant     string // 架构子版本 (如 arm 的 v6)
+	Maintainer  string
+	Description string // 描述，第一行为摘要
+	Homepage    string
+	License     string
+	Vendor      string
+	Section     string // deb 的 Section，rpm 的 Group
+	Priority    string // deb 的 Priority
+	Depends     []string
+	Conflicts   []string
+	Provides    []string
+	Replaces    []string
+	Files       []PackageFile
+	Scripts     PackageScripts
+	ModTime     time.Time // 包内文件的修改时间与构建时间，固定后相同输入得到相同的包
+}
+
+// PackageFile 安装到系统中的文件
+type PackageFile struct {
+	Source string      // 本地文件路径
+	Dest   string      // 安装路径 (绝对路径)
+	Mode   fs.FileMode // 文件权限，为 0 时使用源文件权限
+	Config bool        // 配置文件：deb 的 conffiles、rpm 的 %config(noreplace)
+}
+
+// PackageScripts 安装与卸载脚本的内容
+type PackageScripts struct {
+	PreInstall  string
+	PostInstall string
+	PreRemove   string
+	PostRemove  string
+}
+
+// Dependency 解析后的依赖: name [op version]
+type Dependency struct {
+	Name    string
+	Op      string // <、<=、=、>=、>，无版本约束时为空
+	Version string
+}
+
+// dependencyPattern 依赖写法: "name"、"name >= 1.0" 或 deb 风格的 "name (>= 1.0)"，比较符也可以是 << 与 >>
+var dependencyPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9+._:()/-]*?)\s*(?:\(?\s*(<<|<=|>=|>>|=|<|>)\s*([^\s()]+)\s*\)?)?$`)
+
+// ParseDependency 解析依赖声明
+func ParseDependency(s string) (Dependency, error) {
+	m := dependencyPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Dependency{}, fmt.Errorf("invalid dependency %q (expected: name, or name >= version)", s)
+	}
+	op := m[2]
+	switch op {
+	case "<<":
+		op = "<"
+	case ">>":
+		op = ">"
+	}
+	return Dependency{Name: m[1], Op: op, Version: m[3]}, nil
+}
+
+// gitDescribePattern git describe 的输出: <tag>-<提交数>-g<哈希>
+var gitDescribePattern = regexp.MustCompile(`^(.+)-([0-9]+)-g([0-9a-f]+)$`)
+
+// gitHashPattern 没有标签时 git describe --always 输出的提交哈希，纯数字时视为版本号
+var gitHashPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// upstreamVersion 将版本号拆为数字部分、预发布部分与发布后部分 (git describe 的提交数与哈希)。
+// 如 v1.2.3-rc.1 -> (1.2.3, rc.1, "")，1.2.3-4-gabc -> (1.2.3, "", 4.gabc)；构建元数据 (+...) 被丢弃。
+// git describe --dirty 的 -dirty 后缀记入发布后部分 (1.2.3-dirty -> (1.2.3, "", 0.dirty))，
+// 没有标签时的提交哈希记为 0 的预发布版本 (abc1234 -> (0, gitabc1234, ""))。
+func upstreamVersion(version string) (base, pre, post string, err error) {
+	version = strings.TrimPrefix(version, "v")
+	version, dirty := strings.CutSuffix(version, "-dirty")
+	if gitHashPattern.MatchString(version) && strings.ContainsAny(version, "abcdef") {
+		pre = "git" + version
+		if dirty {
+			pre += ".dirty"
+		}
+		return "0", pre, "", nil
+	}
+	if m := gitDescribePattern.FindStringSubmatch(version); m != nil {
+		version, post = m[1], m[2]+".g"+m[3]
+	} else if dirty {
+		post = "0"
+	}
+	if dirty {
+		post += ".dirty"
+	}
+	version, _, _ = strings.Cut(version, "+")
+	base, pre, _ = strings.Cut(version, "-")
+	if base == "" || base[0] < '0' || base[0] > '9' {
+		return "", "", "", fmt.Errorf("version %q must start with a digit", version)
+	}
+	for _, r := range base {
+		if (r < '0' || r > '9') && r != '.' {
+			return "", "", "", fmt.Errorf("version %q must be numeric components separated by dots, optionally followed by -prerelease", version)
+		}
+	}
+	return base, strings.ReplaceAll(pre, "-", "."), post, nil
+}
+
+// DebVersion 返回 deb 版本号: 1.2.3~rc.1-1 (预发布用 ~ 排在正式版之前)，1.2.3+git4.gabc-1、1.2.3+git0.dirty-1
+func DebVersion(version string, release int) (string, error) {
+	base, pre, post, err := upstreamVersion(version)
+	if err != nil {
+		return "", err
+	}
+	return base + suffixIf("~", pre) + suffixIf("+git", post) + "-" + strconv.Itoa(release), nil
+}
+
+// RPMVersion 返回 rpm 的 Version 与 Release，Version 中不能出现 -
+func RPMVersion(version string, release int) (string, string, error) {
+	base, pre, post, err := upstreamVersion(version)
+	if err != nil {
+		return "", "", err
+	}
+	return base + suffixIf("~", pre) + suffixIf("+git", post), strconv.Itoa(release), nil
+}
+
+// apkPreSuffixes apk 版本号支持的预发布后缀
+var apkPreSuffixes = []string{"alpha", "beta", "pre", "rc"}
+
+// APKVersion 返回 apk 版本号: 1.2.3_rc1-r0、1.2.3_git4-r0。
+// apk 只接受固定的后缀，无法识别的预发布版本记为 _pre。
+func APKVersion(version string, release int) (string, error) {
+	base, pre, post, err := upstreamVersion(version)
+	if err != nil {
+		return "", err
+	}
+	if pre != "" {
+		suffix := "pre"
+		for _, candidate := range apkPreSuffixes {
+			if strings.HasPrefix(strings.ToLower(pre), candidate) {
+				suffix = candidate
+				break
+			}
+		}
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, pre)
+		if strings.HasPrefix(pre, "git") {
+			// 提交哈希中的数字没有顺序意义
+			digits = ""
+		}
+		base += "_" + suffix + digits
+	}
+	if post != "" {
+		commits, _, _ := strings.Cut(post, ".")
+		base += "_git" + commits
+	}
+	return base + "-r" + strconv.Itoa(max(release-1, 0)), nil
+}
+
+func suffixIf(prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	return prefix + value
+}
+
+// linuxArchs GOARCH 到各格式架构名的映射: deb、rpm、apk
+var linuxArchs = map[string][3]string{
+	"amd64":    {"amd64", "x86_64", "x86_64"},
+	"386":      {"i386", "i686", "x86"},
+	"arm64":    {"arm64", "aarch64", "aarch64"},
+	"arm/v5":   {"armel", "armv5tel", "armel"},
+	"arm/v6":   {"armhf", "armv6hl", "armhf"},
+	"arm/v7":   {"armhf", "armv7hl", "armv7"},
+	"ppc64le":  {"ppc64el", "ppc64le", "ppc64le"},
+	"s390x":    {"s390x", "s390x", "s390x"},
+	"riscv64":  {"riscv64", "riscv64", "riscv64"},
+	"loong64":  {"loong64", "loongarch64", "loongarch64"},
+	"mips64le": {"mips64el", "mips64el", "mips64el"},
+	"mipsle":   {"mipsel", "mipsel", "mipsel"},
+}
+
+// LinuxArch 返回目标架构在软件包格式中的名称，如 amd64 在 rpm 中为 x86_64
+func LinuxArch(format, goarch, variant string) (string, error) {
+	key := goarch
+	if goarch == "arm" {
+		// 交叉编译时 GOARM 默认为 7
+		if variant == "" {
+			variant = "v7"
+		}
+		key += "/" + variant
+	}
+	names, ok := linuxArchs[key]
+	if !ok {
+		return "", fmt.Errorf("no %s architecture for %s", format, strings.TrimSuffix(goarch+"/"+variant, "/"))
+	}
+	switch format {
+	case FormatDeb:
+		return names[0], nil
+	case FormatRPM:
+		return names[1], nil
+	case FormatAPK:
+		return names[2], nil
+	}
+	return "", fmt.Errorf("unsupported package format %q", format)
+}
+
+// FileName 返回软件包文件名:
+// deb 为 <name>_<version>_<arch>.deb，rpm 为 <name>-<version>-<release>.<arch>.rpm，apk 为 <name>_<version>_<arch>.apk
+func (p *LinuxPackage) FileName(format string) (string, error) {
+	arch, err := LinuxArch(format, p.Arch, p.Variant)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case FormatDeb:
+		version, err := DebVersion(p.Version, p.Release)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s_%s_%s.deb", p.Name, version, arch), nil
+	case FormatRPM:
+		version, release, err := RPMVersion(p.Version, p.Release)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s-%s-%s.%s.rpm", p.Name, version, release, arch), nil
+	case FormatAPK:
+		version, err := APKVersion(p.Version, p.Release)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s_%s_%s.apk", p.Name, version, arch), nil
+	}
+	return "", fmt.Errorf("unsupported package format %q", format)
+}
+
+// WriteLinuxPackage 写入指定格式的软件包
+func WriteLinuxPackage(format, target string, p *LinuxPackage) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	switch format {
+	case FormatDeb:
+		return writeFileAtomic(target, p.writeDeb)
+	case FormatRPM:
+		return writeFileAtomic(target, p.writeRPM)
+	case FormatAPK:
+		return writeFileAtomic(target, p.writeAPK)
+	}
+	return fmt.Errorf("unsupported package format %q", format)
+}
+
+// validate 检查安装路径与依赖声明
+func (p *LinuxPackage) validate() error {
+	seen := map[string]bool{}
+	for _, file := range p.Files {
+		if !path.IsAbs(file.Dest) || path.Clean(file.Dest) != file.Dest || file.Dest == "/" {
+			return fmt.Errorf("install path %q must be a clean absolute file path", file.Dest)
+		}
+		if seen[file.Dest] {
+			return fmt.Errorf("%s is installed twice", file.Dest)
+		}
+		seen[file.Dest] = true
+	}
+	for _, list := range [][]string{p.Depends, p.Conflicts, p.Provides, p.Replaces} {
+		for _, dep := range list {
+			if _, err := ParseDependency(dep); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// packageEntry 读入内存的待打包文件
+type packageEntry struct {
+	PackageFile
+	data []byte
+	mode fs.FileMode
+}
+
+// entries 按安装路径排序读取所有文件
+func (p *LinuxPackage) entries() ([]packageEntry, error) {
+	entries := make([]packageEntry, 0, len(p.Files))
+	for _, file := range p.Files {
+		info, err := os.Stat(file.Source)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(file.Source)
+		if err != nil {
+			return nil, err
+		}
+		mode := file.Mode
+		if mode == 0 {
+			mode = info.Mode().Perm()
+		}
+		entries = append(entries, packageEntry{PackageFile: file, data: data, mode: mode.Perm()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Dest < entries[j].Dest })
+	return entries, nil
+}
+
+// installedSize 返回安装后文件的总大小 (字节)
+func installedSize(entries []packageEntry) int64 {
+	var size int64
+	for _, entry := range entries {
+		size += int64(len(entry.data))
+	}
+	return size
+}
+
+// parentDirs 返回所有文件的上级目录 (不含 /)，按路径排序
+func parentDirs(entries []packageEntry) []string {
+	seen := map[string]bool{}
+	dirs := []string{}
+	for _, entry := range entries {
+		for dir := path.Dir(entry.Dest); dir != "/" && !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// summaryAndDescription 返回单行摘要与详细描述：描述的第一行为摘要，其余行为详细描述
+func (p *LinuxPackage) summaryAndDescription() (string, string) {
+	summary, description, _ := strings.Cut(strings.TrimSpace(p.Description), "\n")
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		summary = p.Name
+	}
+	return summary, strings.TrimSpace(description)
+}
+
+func sha256Hex(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
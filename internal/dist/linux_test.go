@@ -0,0 +1,347 @@
+package dist
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinuxPackageNaming(t *testing.T) {
+	versions := []struct {
+		version, goarch           string
+		deb, rpm, apk             string
+		rpmRelease                string
+		debFile, rpmFile, apkFile string
+	}{
+		{
+			version: "v1.2.0", goarch: "amd64",
+			deb: "1.2.0-1", rpm: "1.2.0", apk: "1.2.0-r0", rpmRelease: "1",
+			debFile: "api_1.2.0-1_amd64.deb", rpmFile: "api-1.2.0-1.x86_64.rpm", apkFile: "api_1.2.0-r0_x86_64.apk",
+		},
+		{
+			version: "1.2.0-rc.1", goarch: "arm64",
+			deb: "1.2.0~rc.1-1", rpm: "1.2.0~rc.1", apk: "1.2.0_rc1-r0", rpmRelease: "1",
+			debFile: "api_1.2.0~rc.1-1_arm64.deb", rpmFile: "api-1.2.0~rc.1-1.aarch64.rpm", apkFile: "api_1.2.0_rc1-r0_aarch64.apk",
+		},
+		{
+			version: "v1.2.0-3-gabc1234", goarch: "arm",
+			deb: "1.2.0+git3.gabc1234-1", rpm: "1.2.0+git3.gabc1234", apk: "1.2.0_git3-r0", rpmRelease: "1",
+			debFile: "api_1.2.0+git3.gabc1234-1_armhf.deb", rpmFile: "api-1.2.0+git3.gabc1234-1.armv7hl.rpm", apkFile: "api_1.2.0_git3-r0_armv7.apk",
+		},
+		{
+			// project.version = "git" 时 git describe --dirty 的输出
+			version: "1.2.3-4-gabc123-dirty", goarch: "amd64",
+			deb: "1.2.3+git4.gabc123.dirty-1", rpm: "1.2.3+git4.gabc123.dirty", apk: "1.2.3_git4-r0", rpmRelease: "1",
+			debFile: "api_1.2.3+git4.gabc123.dirty-1_amd64.deb", rpmFile: "api-1.2.3+git4.gabc123.dirty-1.x86_64.rpm", apkFile: "api_1.2.3_git4-r0_x86_64.apk",
+		},
+		{
+			version: "1.2.3-dirty", goarch: "amd64",
+			deb: "1.2.3+git0.dirty-1", rpm: "1.2.3+git0.dirty", apk: "1.2.3_git0-r0", rpmRelease: "1",
+			debFile: "api_1.2.3+git0.dirty-1_amd64.deb", rpmFile: "api-1.2.3+git0.dirty-1.x86_64.rpm", apkFile: "api_1.2.3_git0-r0_x86_64.apk",
+		},
+		{
+			// 没有标签时 git describe --always 输出提交哈希
+			version: "abc1234", goarch: "amd64",
+			deb: "0~gitabc1234-1", rpm: "0~gitabc1234", apk: "0_pre-r0", rpmRelease: "1",
+			debFile: "api_0~gitabc1234-1_amd64.deb", rpmFile: "api-0~gitabc1234-1.x86_64.rpm", apkFile: "api_0_pre-r0_x86_64.apk",
+		},
+	}
+	for _, tt := range versions {
+		if got, _ := DebVersion(tt.version, 1); got != tt.deb {
+			t.Errorf("DebVersion(%q) = %q, want %q", tt.version, got, tt.deb)
+		}
+		if got, release, _ := RPMVersion(tt.version, 1); got != tt.rpm || release != tt.rpmRelease {
+			t.Errorf("RPMVersion(%q) = %q, %q, want %q, %q", tt.version, got, release, tt.rpm, tt.rpmRelease)
+		}
+		if got, _ := APKVersion(tt.version, 1); got != tt.apk {
+			t.Errorf("APKVersion(%q) = %q, want %q", tt.version, got, tt.apk)
+		}
+		p := &LinuxPackage{Name: "api", Version: tt.version, Release: 1, Arch: tt.goarch}
+		for format, want := range map[string]string{FormatDeb: tt.debFile, FormatRPM: tt.rpmFile, FormatAPK: tt.apkFile} {
+			if got, err := p.FileName(format); err != nil || got != want {
+				t.Errorf("FileName(%s, %s) = %q, %v, want %q", format, tt.version, got, err, want)
+			}
+		}
+	}
+
+	if _, err := DebVersion("latest", 1); err == nil {
+		t.Error("DebVersion(latest) expected an error")
+	}
+	if _, err := LinuxArch(FormatDeb, "wasm", ""); err == nil {
+		t.Error("LinuxArch(wasm) expected an error")
+	}
+	for _, item := range []string{"libc6 (>= 2.17)", "libc6 >= 2.17", "libc6>=2.17"} {
+		if dep, err := ParseDependency(item); err != nil || dep != (Dependency{Name: "libc6", Op: ">=", Version: "2.17"}) {
+			t.Errorf("ParseDependency(%q) = %+v, %v", item, dep, err)
+		}
+	}
+	if dep, _ := ParseDependency("foo (<< 2)"); dep.Op != "<" {
+		t.Errorf("ParseDependency(<<) op = %q, want <", dep.Op)
+	}
+}
+
+func TestWriteDeb(t *testing.T) {
+	p := testLinuxPackage(t)
+	target := filepath.Join(t.TempDir(), "api.deb")
+	if err := WriteLinuxPackage(FormatDeb, target, p); err != nil {
+		t.Fatalf("WriteLinuxPackage(deb) unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ar 归档: 魔数后每个成员有 60 字节的头
+	if !bytes.HasPrefix(data, []byte("!<arch>\n")) {
+		t.Fatalf("deb does not start with the ar magic")
+	}
+	members := map[string][]byte{}
+	names := []string{}
+	for rest := data[8:]; len(rest) > 0; {
+		name := strings.TrimSpace(string(rest[:16]))
+		size, err := strconv.Atoi(strings.TrimSpace(string(rest[48:58])))
+		if err != nil {
+			t.Fatalf("bad ar header for %s: %v", name, err)
+		}
+		members[name] = rest[60 : 60+size]
+		names = append(names, name)
+		rest = rest[60+size+size%2:]
+	}
+	if want := []string{"debian-binary", "control.tar.gz", "data.tar.gz"}; !slices.Equal(names, want) {
+		t.Fatalf("ar members = %v, want %v", names, want)
+	}
+
+	control := readTarGz(t, members["control.tar.gz"])
+	for _, want := range []string{"Package: api\n", "Version: 1.2.0~rc.1-1\n", "Architecture: amd64\n", "Depends: libc6 (>= 2.17), ca-certificates\n", "Description: API server\n Serves the API.\n"} {
+		if !strings.Contains(control["./control"], want) {
+			t.Errorf("control missing %q:\n%s", want, control["./control"])
+		}
+	}
+	if control["./conffiles"] != "/etc/api/api.toml\n" {
+		t.Errorf("conffiles = %q", control["./conffiles"])
+	}
+	if control["./postinst"] != "#!/bin/sh\necho installed\n" {
+		t.Errorf("postinst = %q", control["./postinst"])
+	}
+	files := readTarGz(t, members["data.tar.gz"])
+	if files["./usr/bin/api"] != "binary" || files["./etc/api/api.toml"] != "port = 80\n" {
+		t.Errorf("data.tar.gz = %v", files)
+	}
+	if _, ok := files["./usr/bin/"]; !ok {
+		t.Errorf("data.tar.gz is missing the parent directories: %v", files)
+	}
+}
+
+func TestWriteRPM(t *testing.T) {
+	p := testLinuxPackage(t)
+	target := filepath.Join(t.TempDir(), "api.rpm")
+	if err := WriteLinuxPackage(FormatRPM, target, p); err != nil {
+		t.Fatalf("WriteLinuxPackage(rpm) unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte{0xed, 0xab, 0xee, 0xdb}) {
+		t.Fatalf("rpm does not start with the lead magic")
+	}
+
+	sig, sigSize := parseRPMHeader(t, data[96:], rpmTagHeaderSignatures)
+	headerStart := 96 + sigSize + (8-sigSize%8)%8
+	header, headerSize := parseRPMHeader(t, data[headerStart:], rpmTagHeaderImmutable)
+	if got, want := sig[rpmSigTagSHA256][0], sha256Hex(data[headerStart:headerStart+headerSize]); got != want {
+		t.Errorf("signature sha256 = %s, want %s", got, want)
+	}
+
+	for tag, want := range map[uint32]string{
+		rpmTagName:      "api",
+		rpmTagVersion:   "1.2.0~rc.1",
+		rpmTagRelease:   "1",
+		rpmTagArch:      "x86_64",
+		rpmTagOS:        "linux",
+		rpmTagSummary:   "API server",
+		rpmTagSourceRPM: "api-1.2.0~rc.1-1.src.rpm",
+		rpmTagPostIn:    "#!/bin/sh\necho installed\n",
+	} {
+		if got := header[tag]; len(got) != 1 || got[0] != want {
+			t.Errorf("tag %d = %q, want %q", tag, got, want)
+		}
+	}
+	if got := header[rpmTagBaseNames]; !slices.Equal(got, []string{"api.toml", "api"}) {
+		t.Errorf("basenames = %v", got)
+	}
+	if got := header[rpmTagDirNames]; !slices.Equal(got, []string{"/etc/api/", "/usr/bin/"}) {
+		t.Errorf("dirnames = %v", got)
+	}
+	if !slices.Contains(header[rpmTagRequireName], "libc6") || !slices.Contains(header[rpmTagRequireName], "rpmlib(TildeInVersions)") {
+		t.Errorf("requires = %v", header[rpmTagRequireName])
+	}
+	if got := header[rpmTagFileFlags]; !slices.Equal(got, []string{"17", "0"}) {
+		t.Errorf("file flags = %v, want the config file marked %%config(noreplace)", got)
+	}
+
+	// 载荷: gzip 压缩的 cpio (newc)
+	gz, err := gzip.NewReader(bytes.NewReader(data[headerStart+headerSize:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for rest := payload; len(rest) > 0; {
+		if string(rest[:6]) != "070701" {
+			t.Fatalf("bad cpio magic %q", rest[:6])
+		}
+		field := func(i int) int {
+			n, _ := strconv.ParseUint(string(rest[6+8*i:14+8*i]), 16, 32)
+			return int(n)
+		}
+		size, nameSize := field(6), field(11)
+		name := string(rest[110 : 110+nameSize-1])
+		if name == "TRAILER!!!" {
+			break
+		}
+		names = append(names, name)
+		dataStart := (110 + nameSize + 3) &^ 3
+		rest = rest[(dataStart+size+3)&^3:]
+	}
+	if want := []string{"./etc/api/api.toml", "./usr/bin/api"}; !slices.Equal(names, want) {
+		t.Errorf("payload = %v, want %v", names, want)
+	}
+}
+
+func TestWriteAPK(t *testing.T) {
+	p := testLinuxPackage(t)
+	target := filepath.Join(t.TempDir(), "api.apk")
+	if err := WriteLinuxPackage(FormatAPK, target, p); err != nil {
+		t.Fatalf("WriteLinuxPackage(apk) unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 控制段是第一个 gzip 流，datahash 为其后数据段的 sha256
+	r := bytes.NewReader(data)
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz.Multistream(false)
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		t.Fatal(err)
+	}
+	dataSegment := data[len(data)-r.Len():]
+
+	files := readTarGz(t, data)
+	pkginfo := files[".PKGINFO"]
+	for _, want := range []string{"pkgname = api\n", "pkgver = 1.2.0_rc1-r0\n", "arch = x86_64\n", "depend = libc6>=2.17\n", "datahash = " + sha256Hex(dataSegment) + "\n"} {
+		if !strings.Contains(pkginfo, want) {
+			t.Errorf(".PKGINFO missing %q:\n%s", want, pkginfo)
+		}
+	}
+	if files[".post-install"] != "#!/bin/sh\necho installed\n" {
+		t.Errorf(".post-install = %q", files[".post-install"])
+	}
+	if files["usr/bin/api"] != "binary" {
+		t.Errorf("data segment = %v", files)
+	}
+}
+
+func testLinuxPackage(t *testing.T) *LinuxPackage {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "api"), "binary")
+	writeFile(t, filepath.Join(root, "api.toml"), "port = 80\n")
+	return &LinuxPackage{
+		Name:        "api",
+		Version:     "v1.2.0-rc.1",
+		Release:     1,
+		Arch:        "amd64",
+		Maintainer:  "Jane Doe <jane@example.com>",
+		Description: "API server\nServes the API.",
+		License:     "MIT",
+		Depends:     []string{"libc6 >= 2.17", "ca-certificates"},
+		Files: []PackageFile{
+			{Source: filepath.Join(root, "api"), Dest: "/usr/bin/api", Mode: 0755},
+			{Source: filepath.Join(root, "api.toml"), Dest: "/etc/api/api.toml", Config: true},
+		},
+		Scripts: PackageScripts{PostInstall: "#!/bin/sh\necho installed\n"},
+		ModTime: time.Unix(1700000000, 0),
+	}
+}
+
+// readTarGz 读取 gzip 压缩的 tar (可以是多个拼接的流)，返回 文件名 -> 内容
+func readTarGz(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+// parseRPMHeader 解析 rpm 头部，检查区域尾部，返回 标签 -> 值 (整数格式化为十进制) 与头部长度
+func parseRPMHeader(t *testing.T, data []byte, regionTag uint32) (map[uint32][]string, int) {
+	t.Helper()
+	be := binary.BigEndian
+	if !bytes.HasPrefix(data, []byte{0x8e, 0xad, 0xe8, 0x01}) {
+		t.Fatalf("bad rpm header magic")
+	}
+	count, storeSize := int(be.Uint32(data[8:])), int(be.Uint32(data[12:]))
+	index := data[16 : 16+16*count]
+	store := data[16+16*count : 16+16*count+storeSize]
+
+	if tag, offset := be.Uint32(index), int(be.Uint32(index[8:])); tag != regionTag || offset != storeSize-16 {
+		t.Fatalf("region entry = tag %d offset %d, want tag %d offset %d", tag, offset, regionTag, storeSize-16)
+	}
+	if tag, offset := be.Uint32(store[storeSize-16:]), int32(be.Uint32(store[storeSize-8:])); tag != regionTag || offset != int32(-16*count) {
+		t.Fatalf("region trailer = tag %d offset %d, want offset %d", tag, offset, -16*count)
+	}
+
+	values := map[uint32][]string{}
+	for i := 1; i < count; i++ {
+		entry := index[16*i:]
+		tag, typ, offset, n := be.Uint32(entry), be.Uint32(entry[4:]), int(be.Uint32(entry[8:])), int(be.Uint32(entry[12:]))
+		switch typ {
+		case rpmString, rpmStringArray, rpmI18NString:
+			values[tag] = strings.Split(string(store[offset:]), "\x00")[:n]
+		case rpmInt32:
+			for j := 0; j < n; j++ {
+				values[tag] = append(values[tag], strconv.FormatUint(uint64(be.Uint32(store[offset+4*j:])), 10))
+			}
+		case rpmInt16:
+			for j := 0; j < n; j++ {
+				values[tag] = append(values[tag], strconv.FormatUint(uint64(be.Uint16(store[offset+2*j:])), 10))
+			}
+		}
+	}
+	return values, 16 + 16*count + storeSize
+}
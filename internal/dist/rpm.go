@@ -0,0 +1,455 @@
+package dist
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// rpm 头部数据类型
+const (
+	rpmInt16       = 3
+	rpmInt32       = 4
+	rpmString      = 6
+	rpmBin         = 7
+	rpmStringArray = 8
+	rpmI18NString  = 9
+)
+
+// rpm 头部标签
+const (
+	rpmTagHeaderSignatures = 62
+	rpmTagHeaderImmutable  = 63
+	rpmTagI18NTable        = 100
+
+	rpmSigTagSHA1        = 269
+	rpmSigTagSHA256      = 273
+	rpmSigTagSize        = 1000
+	rpmSigTagMD5         = 1004
+	rpmSigTagPayloadSize = 1007
+
+	rpmTagName              = 1000
+	rpmTagVersion           = 1001
+	rpmTagRelease           = 1002
+	rpmTagSummary           = 1004
+	rpmTagDescription       = 1005
+	rpmTagBuildTime         = 1006
+	rpmTagBuildHost         = 1007
+	rpmTagSize              = 1009
+	rpmTagVendor            = 1011
+	rpmTagLicense           = 1014
+	rpmTagPackager          = 1015
+	rpmTagGroup             = 1016
+	rpmTagURL               = 1020
+	rpmTagOS                = 1021
+	rpmTagArch              = 1022
+	rpmTagPreIn             = 1023
+	rpmTagPostIn            = 1024
+	rpmTagPreUn             = 1025
+	rpmTagPostUn            = 1026
+	rpmTagFileSizes         = 1028
+	rpmTagFileModes         = 1030
+	rpmTagFileRdevs         = 1033
+	rpmTagFileMtimes        = 1034
+	rpmTagFileDigests       = 1035
+	rpmTagFileLinkTos       = 1036
+	rpmTagFileFlags         = 1037
+	rpmTagFileUserName      = 1039
+	rpmTagFileGroupName     = 1040
+	rpmTagSourceRPM         = 1044
+	rpmTagFileVerifyFlags   = 1045
+	rpmTagProvideName       = 1047
+	rpmTagRequireFlags      = 1048
+	rpmTagRequireName       = 1049
+	rpmTagRequireVersion    = 1050
+	rpmTagConflictFlags     = 1053
+	rpmTagConflictName      = 1054
+	rpmTagConflictVersion   = 1055
+	rpmTagPreInProg         = 1085
+	rpmTagPostInProg        = 1086
+	rpmTagPreUnProg         = 1087
+	rpmTagPostUnProg        = 1088
+	rpmTagObsoleteName      = 1090
+	rpmTagFileDevices       = 1095
+	rpmTagFileInodes        = 1096
+	rpmTagFileLangs         = 1097
+	rpmTagProvideFlags      = 1112
+	rpmTagProvideVersion    = 1113
+	rpmTagObsoleteFlags     = 1114
+	rpmTagObsoleteVersion   = 1115
+	rpmTagDirIndexes        = 1116
+	rpmTagBaseNames         = 1117
+	rpmTagDirNames          = 1118
+	rpmTagPayloadFormat     = 1124
+	rpmTagPayloadCompressor = 1125
+	rpmTagPayloadFlags      = 1126
+	rpmTagFileDigestAlgo    = 5011
+)
+
+// rpm 依赖比较标志
+const (
+	rpmSenseLess    = 1 << 1
+	rpmSenseGreater = 1 << 2
+	rpmSenseEqual   = 1 << 3
+	rpmSenseRPMLib  = 1 << 24
+)
+
+// rpmSenseFlags 比较符对应的标志
+var rpmSenseFlags = map[string]uint32{
+	"":   0,
+	"<":  rpmSenseLess,
+	"<=": rpmSenseLess | rpmSenseEqual,
+	"=":  rpmSenseEqual,
+	">=": rpmSenseGreater | rpmSenseEqual,
+	">":  rpmSenseGreater,
+}
+
+// rpmScripts 安装脚本与对应的标签
+var rpmScripts = []struct {
+	tag, progTag uint32
+	script       func(PackageScripts) string
+}{
+	{rpmTagPreIn, rpmTagPreInProg, func(s PackageScripts) string { return s.PreInstall }},
+	{rpmTagPostIn, rpmTagPostInProg, func(s PackageScripts) string { return s.PostInstall }},
+	{rpmTagPreUn, rpmTagPreUnProg, func(s PackageScripts) string { return s.PreRemove }},
+	{rpmTagPostUn, rpmTagPostUnProg, func(s PackageScripts) string { return s.PostRemove }},
+}
+
+// writeRPM 写入 rpm 包：lead、签名头部 (摘要)、主头部 (元数据与文件列表) 与 gzip 压缩的 cpio 载荷。
+// 包未做 GPG 签名，rpm -K 只校验摘要。
+func (p *LinuxPackage) writeRPM(w io.Writer) error {
+	entries, err := p.entries()
+	if err != nil {
+		return err
+	}
+	version, release, err := RPMVersion(p.Version, p.Release)
+	if err != nil {
+		return err
+	}
+	arch, err := LinuxArch(FormatRPM, p.Arch, p.Variant)
+	if err != nil {
+		return err
+	}
+
+	payload, payloadSize, err := p.rpmPayload(entries)
+	if err != nil {
+		return err
+	}
+	header := p.rpmHeader(entries, version, release, arch).encode(rpmTagHeaderImmutable)
+
+	// 签名头部中的摘要: SHA1/SHA256 只覆盖主头部，MD5 与 SIZE 覆盖主头部与载荷
+	digest := md5.New()
+	digest.Write(header)
+	digest.Write(payload)
+	signature := &rpmHeader{}
+	signature.addString(rpmSigTagSHA1, fmt.Sprintf("%x", sha1.Sum(header)))
+	signature.addString(rpmSigTagSHA256, sha256Hex(header))
+	signature.addInt32(rpmSigTagSize, uint32(len(header)+len(payload)))
+	signature.add(rpmSigTagMD5, rpmBin, 16, digest.Sum(nil))
+	signature.addInt32(rpmSigTagPayloadSize, uint32(payloadSize))
+	sig := signature.encode(rpmTagHeaderSignatures)
+	// 签名头部之后按 8 字节对齐
+	sig = append(sig, make([]byte, (8-len(sig)%8)%8)...)
+
+	for _, part := range [][]byte{rpmLead(p.Name + "-" + version + "-" + release), sig, header, payload} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rpmLead 96 字节的 lead，现代 rpm 只检查魔数，信息以头部为准
+func rpmLead(name string) []byte {
+	lead := make([]byte, 96)
+	copy(lead, []byte{0xed, 0xab, 0xee, 0xdb, 3, 0}) // 魔数与格式版本 3.0
+	// 类型 (0 为二进制包) 与架构号保持为 0
+	copy(lead[10:75], name)
+	binary.BigEndian.PutUint16(lead[76:], 1) // 操作系统: Linux
+	binary.BigEndian.PutUint16(lead[78:], 5) // 签名类型: 头部格式
+	return lead
+}
+
+// rpmHeader 主头部：包信息、依赖、脚本与文件列表
+func (p *LinuxPackage) rpmHeader(entries []packageEntry, version, release, arch string) *rpmHeader {
+	summary, description := p.summaryAndDescription()
+	if description == "" {
+		description = summary
+	}
+	group := p.Section
+	if group == "" {
+		group = "Unspecified"
+	}
+
+	h := &rpmHeader{}
+	h.add(rpmTagI18NTable, rpmStringArray, 1, []byte("C\x00"))
+	h.addString(rpmTagName, p.Name)
+	h.addString(rpmTagVersion, version)
+	h.addString(rpmTagRelease, release)
+	h.add(rpmTagSummary, rpmI18NString, 1, []byte(summary+"\x00"))
+	h.add(rpmTagDescription, rpmI18NString, 1, []byte(description+"\x00"))
+	h.add(rpmTagGroup, rpmI18NString, 1, []byte(group+"\x00"))
+	h.addInt32(rpmTagBuildTime, uint32(p.ModTime.Unix()))
+	h.addString(rpmTagBuildHost, "localhost")
+	h.addInt32(rpmTagSize, uint32(installedSize(entries)))
+	h.addString(rpmTagVendor, p.Vendor)
+	h.addString(rpmTagLicense, p.License)
+	h.addString(rpmTagPackager, p.Maintainer)
+	h.addString(rpmTagURL, p.Homepage)
+	h.addString(rpmTagOS, "linux")
+	h.addString(rpmTagArch, arch)
+	// 有 SOURCERPM 的包才会被识别为二进制包
+	h.addString(rpmTagSourceRPM, p.Name+"-"+version+"-"+release+".src.rpm")
+
+	hasScripts := false
+	for _, script := range rpmScripts {
+		if content := script.script(p.Scripts); content != "" {
+			h.addString(script.tag, content)
+			h.addString(script.progTag, "/bin/sh")
+			hasScripts = true
+		}
+	}
+
+	// 依赖：rpmlib() 声明载荷使用的特性，提供自身的名称与版本
+	requires := []Dependency{
+		{Name: "rpmlib(CompressedFileNames)", Op: "<=", Version: "3.0.4-1"},
+		{Name: "rpmlib(FileDigests)", Op: "<=", Version: "4.6.0-1"},
+		{Name: "rpmlib(PayloadFilesHavePrefix)", Op: "<=", Version: "4.0-1"},
+	}
+	if strings.Contains(version, "~") {
+		requires = append(requires, Dependency{Name: "rpmlib(TildeInVersions)", Op: "<=", Version: "4.10.0-1"})
+	}
+	if hasScripts {
+		requires = append(requires, Dependency{Name: "/bin/sh"})
+	}
+	requires = append(requires, parseDependencies(p.Depends)...)
+	provides := append(parseDependencies(p.Provides), Dependency{Name: p.Name, Op: "=", Version: version + "-" + release})
+	h.addDependencies(rpmTagRequireName, rpmTagRequireFlags, rpmTagRequireVersion, requires)
+	h.addDependencies(rpmTagProvideName, rpmTagProvideFlags, rpmTagProvideVersion, provides)
+	h.addDependencies(rpmTagConflictName, rpmTagConflictFlags, rpmTagConflictVersion, parseDependencies(p.Conflicts))
+	h.addDependencies(rpmTagObsoleteName, rpmTagObsoleteFlags, rpmTagObsoleteVersion, parseDependencies(p.Replaces))
+
+	h.addPayloadFiles(entries, uint32(p.ModTime.Unix()))
+	h.addString(rpmTagPayloadFormat, "cpio")
+	h.addString(rpmTagPayloadCompressor, "gzip")
+	h.addString(rpmTagPayloadFlags, "9")
+	return h
+}
+
+// addPayloadFiles 写入文件列表，路径拆为目录 (DIRNAMES) 与文件名 (BASENAMES)
+func (h *rpmHeader) addPayloadFiles(entries []packageEntry, mtime uint32) {
+	if len(entries) == 0 {
+		return
+	}
+	var (
+		sizes, mtimes, flags, verify, devices, inodes, dirIndexes []uint32
+		modes, rdevs                                              []uint16
+		digests, linktos, users, groups, langs, baseNames, dirs   []string
+	)
+	dirIndex := map[string]uint32{}
+	for i, entry := range entries {
+		dir, base := entry.Dest[:strings.LastIndex(entry.Dest, "/")+1], entry.Dest[strings.LastIndex(entry.Dest, "/")+1:]
+		if _, ok := dirIndex[dir]; !ok {
+			dirIndex[dir] = uint32(len(dirs))
+			dirs = append(dirs, dir)
+		}
+		var flag uint32
+		if entry.Config {
+			flag = 1 | 1<<4 // %config(noreplace)
+		}
+		sizes = append(sizes, uint32(len(entry.data)))
+		mtimes = append(mtimes, mtime)
+		flags = append(flags, flag)
+		verify = append(verify, 0xffffffff)
+		devices = append(devices, 1)
+		inodes = append(inodes, uint32(i+1))
+		dirIndexes = append(dirIndexes, dirIndex[dir])
+		modes = append(modes, uint16(0100000|entry.mode))
+		rdevs = append(rdevs, 0)
+		digests = append(digests, sha256Hex(entry.data))
+		linktos = append(linktos, "")
+		users = append(users, "root")
+		groups = append(groups, "root")
+		langs = append(langs, "")
+		baseNames = append(baseNames, base)
+	}
+	h.addInt32(rpmTagFileSizes, sizes...)
+	h.addInt16(rpmTagFileModes, modes...)
+	h.addInt16(rpmTagFileRdevs, rdevs...)
+	h.addInt32(rpmTagFileMtimes, mtimes...)
+	h.addStrings(rpmTagFileDigests, digests...)
+	h.addStrings(rpmTagFileLinkTos, linktos...)
+	h.addInt32(rpmTagFileFlags, flags...)
+	h.addStrings(rpmTagFileUserName, users...)
+	h.addStrings(rpmTagFileGroupName, groups...)
+	h.addInt32(rpmTagFileVerifyFlags, verify...)
+	h.addInt32(rpmTagFileDevices, devices...)
+	h.addInt32(rpmTagFileInodes, inodes...)
+	h.addStrings(rpmTagFileLangs, langs...)
+	h.addInt32(rpmTagDirIndexes, dirIndexes...)
+	h.addStrings(rpmTagBaseNames, baseNames...)
+	h.addStrings(rpmTagDirNames, dirs...)
+	h.addInt32(rpmTagFileDigestAlgo, 8) // SHA256
+}
+
+// rpmPayload 生成 gzip 压缩的 cpio (newc) 载荷，路径以 ./ 开头，返回压缩后的内容与压缩前的大小
+func (p *LinuxPackage) rpmPayload(entries []packageEntry) ([]byte, int, error) {
+	var archive bytes.Buffer
+	writeRecord := func(ino int, name string, mode uint32, data []byte) {
+		nlink := 1
+		fmt.Fprintf(&archive, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+			ino, mode, 0, 0, nlink, p.ModTime.Unix(), len(data), 0, 0, 0, 0, len(name)+1, 0)
+		archive.WriteString(name + "\x00")
+		archive.Write(make([]byte, (4-archive.Len()%4)%4))
+		archive.Write(data)
+		archive.Write(make([]byte, (4-archive.Len()%4)%4))
+	}
+	for i, entry := range entries {
+		writeRecord(i+1, "."+entry.Dest, uint32(0100000|entry.mode), entry.data)
+	}
+	writeRecord(0, "TRAILER!!!", 0, nil)
+
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := gz.Write(archive.Bytes()); err != nil {
+		return nil, 0, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), archive.Len(), nil
+}
+
+// parseDependencies 解析依赖列表，调用前已由 validate 校验
+func parseDependencies(list []string) []Dependency {
+	deps := make([]Dependency, 0, len(list))
+	for _, item := range list {
+		dep, _ := ParseDependency(item)
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// rpmHeader rpm 头部的索引项，编码时按标签排序
+type rpmHeader struct {
+	entries []rpmEntry
+}
+
+type rpmEntry struct {
+	tag, typ, count uint32
+	data            []byte
+}
+
+func (h *rpmHeader) add(tag, typ, count uint32, data []byte) {
+	h.entries = append(h.entries, rpmEntry{tag: tag, typ: typ, count: count, data: data})
+}
+
+// addString 写入字符串，空字符串不写
+func (h *rpmHeader) addString(tag uint32, value string) {
+	if value != "" {
+		h.add(tag, rpmString, 1, []byte(value+"\x00"))
+	}
+}
+
+func (h *rpmHeader) addStrings(tag uint32, values ...string) {
+	data := []byte{}
+	for _, value := range values {
+		data = append(data, value...)
+		data = append(data, 0)
+	}
+	h.add(tag, rpmStringArray, uint32(len(values)), data)
+}
+
+func (h *rpmHeader) addInt32(tag uint32, values ...uint32) {
+	data := []byte{}
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint32(data, value)
+	}
+	h.add(tag, rpmInt32, uint32(len(values)), data)
+}
+
+func (h *rpmHeader) addInt16(tag uint32, values ...uint16) {
+	data := []byte{}
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint16(data, value)
+	}
+	h.add(tag, rpmInt16, uint32(len(values)), data)
+}
+
+// addDependencies 写入一组依赖的名称、比较标志与版本，列表为空时不写
+func (h *rpmHeader) addDependencies(nameTag, flagsTag, versionTag uint32, deps []Dependency) {
+	if len(deps) == 0 {
+		return
+	}
+	names, versions := []string{}, []string{}
+	flags := []uint32{}
+	for _, dep := range deps {
+		flag := rpmSenseFlags[dep.Op]
+		if strings.HasPrefix(dep.Name, "rpmlib(") {
+			flag |= rpmSenseRPMLib
+		}
+		names = append(names, dep.Name)
+		flags = append(flags, flag)
+		versions = append(versions, dep.Version)
+	}
+	h.addStrings(nameTag, names...)
+	h.addInt32(flagsTag, flags...)
+	h.addStrings(versionTag, versions...)
+}
+
+// encode 编码头部：魔数、索引项数、数据区大小、索引项与数据区。
+// 第一个索引项为区域标签，指向数据区末尾的区域尾部，尾部记录区域覆盖的索引项 (偏移为 -16*项数)。
+func (h *rpmHeader) encode(regionTag uint32) []byte {
+	entries := append([]rpmEntry(nil), h.entries...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+	be := binary.BigEndian
+	index := []byte{}
+	store := []byte{}
+	addIndex := func(tag, typ, offset, count uint32) {
+		index = be.AppendUint32(index, tag)
+		index = be.AppendUint32(index, typ)
+		index = be.AppendUint32(index, offset)
+		index = be.AppendUint32(index, count)
+	}
+	for _, entry := range entries {
+		// 整数按其宽度对齐
+		switch entry.typ {
+		case rpmInt16:
+			store = append(store, make([]byte, len(store)%2)...)
+		case rpmInt32:
+			store = append(store, make([]byte, (4-len(store)%4)%4)...)
+		}
+		addIndex(entry.tag, entry.typ, uint32(len(store)), entry.count)
+		store = append(store, entry.data...)
+	}
+
+	count := len(entries) + 1
+	trailer := []byte{}
+	trailer = be.AppendUint32(trailer, regionTag)
+	trailer = be.AppendUint32(trailer, rpmBin)
+	trailer = be.AppendUint32(trailer, uint32(-16*int32(count)))
+	trailer = be.AppendUint32(trailer, 16)
+	region := []byte{}
+	region = be.AppendUint32(region, regionTag)
+	region = be.AppendUint32(region, rpmBin)
+	region = be.AppendUint32(region, uint32(len(store)))
+	region = be.AppendUint32(region, 16)
+	store = append(store, trailer...)
+
+	out := []byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0}
+	out = be.AppendUint32(out, uint32(count))
+	out = be.AppendUint32(out, uint32(len(store)))
+	out = append(out, region...)
+	out = append(out, index...)
+	return append(out, store...)
+}
@@ -0,0 +1,99 @@
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// baseImage 作为基础镜像的 OCI image layout 目录 (如 skopeo copy docker://... oci:<dir> 的输出)
+type baseImage struct {
+	dir       string
+	manifests []Descriptor // 展开嵌套索引后的全部镜像清单
+}
+
+// openBase 读取基础镜像的 index.json，展开其中引用的镜像索引
+func openBase(dir string) (*baseImage, error) {
+	var index Index
+	if err := readJSON(filepath.Join(dir, "index.json"), &index); err != nil {
+		return nil, err
+	}
+	base := &baseImage{dir: dir}
+	if err := base.collect(index.Manifests, 0); err != nil {
+		return nil, err
+	}
+	if len(base.manifests) == 0 {
+		return nil, fmt.Errorf("index.json references no image manifests")
+	}
+	return base, nil
+}
+
+// collect 收集镜像清单，索引中引用的索引递归展开
+func (b *baseImage) collect(descriptors []Descriptor, depth int) error {
+	if depth > 4 {
+		return fmt.Errorf("image indexes are nested too deeply")
+	}
+	for _, desc := range descriptors {
+		switch desc.MediaType {
+		case MediaTypeManifest, mediaTypeDockerManifest:
+			b.manifests = append(b.manifests, desc)
+		case MediaTypeIndex, mediaTypeDockerManifestList:
+			var index Index
+			if err := b.readBlob(desc.Digest, &index); err != nil {
+				return err
+			}
+			if err := b.collect(index.Manifests, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve 返回与目标平台匹配的基础镜像清单与配置
+func (b *baseImage) resolve(platform Platform) (*Manifest, *ConfigFile, error) {
+	available := []string{}
+	for _, desc := range b.manifests {
+		var manifest Manifest
+		if err := b.readBlob(desc.Digest, &manifest); err != nil {
+			return nil, nil, err
+		}
+		var config ConfigFile
+		if err := b.readBlob(manifest.Config.Digest, &config); err != nil {
+			return nil, nil, err
+		}
+
+		// 索引中未声明平台的清单以镜像配置中的平台为准
+		candidate := Platform{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant}
+		if desc.Platform != nil {
+			candidate = *desc.Platform
+		}
+		if candidate.OS == platform.OS && candidate.Architecture == platform.Architecture &&
+			(platform.Variant == "" || candidate.Variant == "" || candidate.Variant == platform.Variant) {
+			return &manifest, &config, nil
+		}
+		available = append(available, candidate.String())
+	}
+	return nil, nil, fmt.Errorf("base image has no %s manifest (available: %s)", platform, strings.Join(available, ", "))
+}
+
+// readBlob 读取并解析 blob
+func (b *baseImage) readBlob(digest string, v any) error {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return fmt.Errorf("unsupported digest %q", digest)
+	}
+	return readJSON(filepath.Join(b.dir, filepath.FromSlash(blobPath(digest))), v)
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,453 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 镜像输出格式
+const (
+	FormatOCI    = "oci"    // OCI image layout 目录
+	FormatDocker = "docker" // docker save 兼容的 tar 包，可用 docker load 导入
+)
+
+// Formats 支持的输出格式
+var Formats = []string{FormatOCI, FormatDocker}
+
+// ErrDockerMultiPlatform docker save 格式只能容纳单个平台
+var ErrDockerMultiPlatform = errors.New("docker save tarballs hold a single platform; use --format oci for multi-arch images")
+
+// OCI 与 Docker 的媒体类型
+const (
+	MediaTypeIndex    = "application/vnd.oci.image.index.v1+json"
+	MediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeConfig   = "application/vnd.oci.image.config.v1+json"
+	MediaTypeLayer    = "application/vnd.oci.image.layer.v1.tar+gzip"
+
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// 索引中使用的注解
+const (
+	annotationRefName       = "org.opencontainers.image.ref.name"
+	annotationContainerName = "io.containerd.image.name"
+)
+
+// DefaultDir 二进制在镜像中的默认目录
+const DefaultDir = "/usr/local/bin"
+
+// Descriptor 内容描述符
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *Platform         `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Platform 镜像平台
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// String 返回 <os>/<arch>[/<variant>]
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Manifest 镜像清单
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Index 镜像索引 (多平台)
+type Index struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// ConfigFile 镜像配置
+type ConfigFile struct {
+	Created      string        `json:"created,omitempty"`
+	Architecture string        `json:"architecture"`
+	OS           string        `json:"os"`
+	Variant      string        `json:"variant,omitempty"`
+	Config       RuntimeConfig `json:"config"`
+	RootFS       RootFS        `json:"rootfs"`
+	History      []History     `json:"history,omitempty"`
+}
+
+// RuntimeConfig 容器运行参数
+type RuntimeConfig struct {
+	User         string              `json:"User,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	Env          []string            `json:"Env,omitempty"`
+	Entrypoint   []string            `json:"Entrypoint,omitempty"`
+	Cmd          []string            `json:"Cmd,omitempty"`
+	WorkingDir   string              `json:"WorkingDir,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+}
+
+// RootFS 镜像层的 diff ID (未压缩 tar 的摘要)
+type RootFS struct {
+	Type    string   `json:"type"`
+	DiffIDs []string `json:"diff_ids"`
+}
+
+// History 镜像层的构建记录
+type History struct {
+	Created    string `json:"created,omitempty"`
+	CreatedBy  string `json:"created_by,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	EmptyLayer bool   `json:"empty_layer,omitempty"`
+}
+
+// Binary 某个平台的二进制
+type Binary struct {
+	Path     string // 本地二进制路径
+	Platform Platform
+}
+
+// Options 镜像构建参数
+type Options struct {
+	Name       string   // 镜像名称 (如 ghcr.io/acme/app)
+	Tags       []string // 镜像标签
+	Binaries   []Binary // 每个平台一个二进制
+	BinName    string   // 二进制在镜像中的文件名
+	Dir        string   // 二进制在镜像中的目录，默认为 /usr/local/bin
+	Base       string   // 基础镜像的 OCI layout 目录，为空时基于 scratch
+	Entrypoint []string // 默认为二进制路径
+	Cmd        []string
+	Env        []string
+	Labels     map[string]string
+	WorkingDir string
+	User       string
+	Ports      []string  // 暴露的端口，如 "8080/tcp"
+	Created    time.Time // 镜像创建时间，同时作为层内文件的修改时间
+}
+
+// Result 镜像构建结果
+type Result struct {
+	Digest    string            // 单平台时为清单摘要，多平台时为索引摘要
+	Manifests map[string]string // 平台 -> 清单摘要
+}
+
+// Refs 返回 <name>:<tag> 形式的完整引用
+func (o *Options) Refs() []string {
+	refs := make([]string, 0, len(o.Tags))
+	for _, tag := range o.Tags {
+		refs = append(refs, o.Name+":"+tag)
+	}
+	return refs
+}
+
+// BinaryPath 返回二进制在镜像中的绝对路径
+func (o *Options) BinaryPath() string {
+	dir := o.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return strings.TrimSuffix(dir, "/") + "/" + o.BinName
+}
+
+// Write 构建镜像并写入 output：format 为 oci 时写入 OCI image layout 目录，
+// 为 docker 时写入 docker save 兼容的 tar 包 (只支持单个平台)。
+// 写入先在临时目录中完成，失败时不会破坏已有的输出。
+func Write(opts *Options, format, output string) (*Result, error) {
+	if len(opts.Binaries) == 0 {
+		return nil, fmt.Errorf("no binaries to package")
+	}
+	if format == FormatDocker && len(opts.Binaries) > 1 {
+		return nil, ErrDockerMultiPlatform
+	}
+	if format != FormatOCI && format != FormatDocker {
+		return nil, fmt.Errorf("invalid image format %q (expected: %s)", format, strings.Join(Formats, ", "))
+	}
+
+	var base *baseImage
+	if opts.Base != "" {
+		var err error
+		if base, err = openBase(opts.Base); err != nil {
+			return nil, fmt.Errorf("invalid base image %s: %w", opts.Base, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(output), "."+filepath.Base(output)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+	store := &blobStore{dir: staging}
+
+	result := &Result{Manifests: map[string]string{}}
+	manifests := []Descriptor{}
+	var dockerEntry *dockerManifestEntry
+	for _, binary := range opts.Binaries {
+		desc, entry, err := writeImage(store, opts, base, binary)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", binary.Platform, err)
+		}
+		manifests = append(manifests, desc)
+		result.Manifests[binary.Platform.String()] = desc.Digest
+		dockerEntry = entry
+	}
+
+	// 单平台时 index.json 直接引用清单，多平台时引用一个镜像索引
+	top := manifests[0]
+	if len(manifests) > 1 {
+		data, err := json.MarshalIndent(Index{SchemaVersion: 2, MediaType: MediaTypeIndex, Manifests: manifests}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if top, err = store.putBytes(MediaTypeIndex, data); err != nil {
+			return nil, err
+		}
+	}
+	result.Digest = top.Digest
+
+	layoutIndex := Index{SchemaVersion: 2, MediaType: MediaTypeIndex}
+	for _, tag := range opts.Tags {
+		desc := top
+		desc.Annotations = map[string]string{
+			annotationRefName:       tag,
+			annotationContainerName: opts.Name + ":" + tag,
+		}
+		layoutIndex.Manifests = append(layoutIndex.Manifests, desc)
+	}
+	if err := writeJSON(filepath.Join(staging, "index.json"), layoutIndex); err != nil {
+		return nil, err
+	}
+	if err := writeJSON(filepath.Join(staging, "oci-layout"), map[string]string{"imageLayoutVersion": "1.0.0"}); err != nil {
+		return nil, err
+	}
+
+	if format == FormatDocker {
+		dockerEntry.RepoTags = opts.Refs()
+		if err := writeJSON(filepath.Join(staging, "manifest.json"), []dockerManifestEntry{*dockerEntry}); err != nil {
+			return nil, err
+		}
+		if err := writeDockerArchive(staging, output, opts.Created); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if err := os.RemoveAll(output); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(staging, output); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// writeImage 写入单个平台的层、配置与清单，返回清单描述符与 docker save 的 manifest.json 条目
+func writeImage(store *blobStore, opts *Options, base *baseImage, binary Binary) (Descriptor, *dockerManifestEntry, error) {
+	created := opts.Created.UTC().Format(time.RFC3339)
+	config := &ConfigFile{
+		Architecture: binary.Platform.Architecture,
+		OS:           binary.Platform.OS,
+		Variant:      binary.Platform.Variant,
+		RootFS:       RootFS{Type: "layers"},
+	}
+	layers := []Descriptor{}
+	if base != nil {
+		baseManifest, baseConfig, err := base.resolve(binary.Platform)
+		if err != nil {
+			return Descriptor{}, nil, err
+		}
+		for _, layer := range baseManifest.Layers {
+			if err := store.copyBlob(base.dir, layer.Digest); err != nil {
+				return Descriptor{}, nil, fmt.Errorf("failed to copy base layer %s: %w", layer.Digest, err)
+			}
+			layers = append(layers, Descriptor{MediaType: layer.MediaType, Digest: layer.Digest, Size: layer.Size})
+		}
+		config.Config = baseConfig.Config
+		config.RootFS.DiffIDs = baseConfig.RootFS.DiffIDs
+		config.History = baseConfig.History
+	}
+
+	layer, diffID, err := store.putLayer(binary.Path, opts.BinaryPath(), opts.Created)
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	layers = append(layers, layer)
+	config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, diffID)
+	config.Created = created
+	config.History = append(config.History, History{Created: created, CreatedBy: "gocar image", Comment: opts.BinaryPath()})
+	applyRuntimeConfig(&config.Config, opts)
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	configDesc, err := store.putBytes(MediaTypeConfig, configData)
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+
+	manifestData, err := json.MarshalIndent(Manifest{SchemaVersion: 2, MediaType: MediaTypeManifest, Config: configDesc, Layers: layers}, "", "  ")
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	desc, err := store.putBytes(MediaTypeManifest, manifestData)
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	platform := binary.Platform
+	desc.Platform = &platform
+
+	entry := &dockerManifestEntry{Config: blobPath(configDesc.Digest)}
+	for _, layer := range layers {
+		entry.Layers = append(entry.Layers, blobPath(layer.Digest))
+	}
+	return desc, entry, nil
+}
+
+// applyRuntimeConfig 将 [image] 中的设置合并到 (基础镜像的) 运行参数：
+// 同名环境变量、标签与端口以 [image] 为准，Entrypoint 替换基础镜像的 Entrypoint 与 Cmd
+func applyRuntimeConfig(config *RuntimeConfig, opts *Options) {
+	for _, entry := range opts.Env {
+		name, _, _ := strings.Cut(entry, "=")
+		kept := config.Env[:0]
+		for _, existing := range config.Env {
+			if existingName, _, _ := strings.Cut(existing, "="); existingName != name {
+				kept = append(kept, existing)
+			}
+		}
+		config.Env = append(kept, entry)
+	}
+
+	config.Entrypoint = opts.Entrypoint
+	if len(config.Entrypoint) == 0 {
+		config.Entrypoint = []string{opts.BinaryPath()}
+	}
+	config.Cmd = opts.Cmd
+
+	if opts.WorkingDir != "" {
+		config.WorkingDir = opts.WorkingDir
+	}
+	if opts.User != "" {
+		config.User = opts.User
+	}
+	for _, port := range opts.Ports {
+		if !strings.Contains(port, "/") {
+			port += "/tcp"
+		}
+		if config.ExposedPorts == nil {
+			config.ExposedPorts = map[string]struct{}{}
+		}
+		config.ExposedPorts[port] = struct{}{}
+	}
+	for key, value := range opts.Labels {
+		if config.Labels == nil {
+			config.Labels = map[string]string{}
+		}
+		config.Labels[key] = value
+	}
+}
+
+// PlatformFor 将 gocar 目标平台转换为镜像平台，arm/arm64 的子版本写入 variant
+func PlatformFor(goos, goarch, variant string) Platform {
+	platform := Platform{OS: goos, Architecture: goarch}
+	if goarch == "arm" || goarch == "arm64" {
+		platform.Variant = variant
+	}
+	return platform
+}
+
+// digestOf 返回 sha256:<hex> 形式的摘要
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// blobPath 返回摘要对应的 blob 在镜像布局中的相对路径
+func blobPath(digest string) string {
+	algorithm, hexDigest, _ := strings.Cut(digest, ":")
+	return "blobs/" + algorithm + "/" + hexDigest
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// blobStore 将内容按摘要写入 blobs/sha256/ 目录
+type blobStore struct {
+	dir string
+}
+
+func (s *blobStore) path(digest string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(blobPath(digest)))
+}
+
+// putBytes 写入一段内容并返回其描述符
+func (s *blobStore) putBytes(mediaType string, data []byte) (Descriptor, error) {
+	digest := digestOf(data)
+	path := s.path(digest)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Descriptor{}, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return Descriptor{}, err
+	}
+	return Descriptor{MediaType: mediaType, Digest: digest, Size: int64(len(data))}, nil
+}
+
+// copyBlob 从另一个镜像布局复制 blob，复制前校验摘要
+func (s *blobStore) copyBlob(layoutDir, digest string) error {
+	src, err := os.Open(filepath.Join(layoutDir, filepath.FromSlash(blobPath(digest))))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dest := s.path(digest)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), src); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if got := "sha256:" + hex.EncodeToString(h.Sum(nil)); got != digest {
+		return fmt.Errorf("digest mismatch: got %s", got)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package image
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeBinary(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readBlobJSON(t *testing.T, layout, digest string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(layout, filepath.FromSlash(blobPath(digest))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteOCILayout(t *testing.T) {
+	dir := t.TempDir()
+	opts := &Options{
+		Name:    "ghcr.io/acme/app",
+		Tags:    []string{"1.0.0", "latest"},
+		BinName: "app",
+		Binaries: []Binary{
+			{Path: writeBinary(t, dir, "app-amd64", "amd64 binary"), Platform: PlatformFor("linux", "amd64", "")},
+			{Path: writeBinary(t, dir, "app-arm", "arm binary"), Platform: PlatformFor("linux", "arm", "v7")},
+		},
+		Env:     []string{"TZ=UTC"},
+		Ports:   []string{"8080", "53/udp"},
+		Labels:  map[string]string{"team": "infra"},
+		User:    "65532:65532",
+		Created: time.Unix(1700000000, 0),
+	}
+	output := filepath.Join(dir, "layout")
+	result, err := Write(opts, FormatOCI, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 所有 blob 的文件名都是其内容的摘要
+	blobs, _ := filepath.Glob(filepath.Join(output, "blobs", "sha256", "*"))
+	for _, blob := range blobs {
+		data, _ := os.ReadFile(blob)
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != filepath.Base(blob) {
+			t.Fatalf("blob %s does not match its digest", filepath.Base(blob))
+		}
+	}
+
+	var layoutIndex Index
+	if err := readJSON(filepath.Join(output, "index.json"), &layoutIndex); err != nil {
+		t.Fatal(err)
+	}
+	if len(layoutIndex.Manifests) != 2 || layoutIndex.Manifests[0].Digest != result.Digest || layoutIndex.Manifests[1].Annotations[annotationRefName] != "latest" {
+		t.Fatalf("index.json = %+v", layoutIndex)
+	}
+
+	var index Index
+	readBlobJSON(t, output, result.Digest, &index)
+	if len(index.Manifests) != 2 || index.Manifests[1].Platform.Variant != "v7" || index.Manifests[1].Digest != result.Manifests["linux/arm/v7"] {
+		t.Fatalf("image index = %+v", index)
+	}
+
+	var manifest Manifest
+	readBlobJSON(t, output, index.Manifests[0].Digest, &manifest)
+	var config ConfigFile
+	readBlobJSON(t, output, manifest.Config.Digest, &config)
+	if config.Architecture != "amd64" || !reflect.DeepEqual(config.Config.Entrypoint, []string{"/usr/local/bin/app"}) ||
+		config.Config.User != "65532:65532" || config.Config.Labels["team"] != "infra" {
+		t.Fatalf("config = %+v", config)
+	}
+	if _, ok := config.Config.ExposedPorts["8080/tcp"]; !ok || len(config.Config.ExposedPorts) != 2 {
+		t.Fatalf("exposed ports = %v", config.Config.ExposedPorts)
+	}
+
+	// 层中包含上级目录与可执行的二进制
+	layer, err := os.Open(filepath.Join(output, filepath.FromSlash(blobPath(manifest.Layers[0].Digest))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer layer.Close()
+	gz, err := gzip.NewReader(layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	names := []string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+		if header.Name == "usr/local/bin/app" {
+			content, _ := io.ReadAll(tr)
+			if string(content) != "amd64 binary" || header.Mode != 0755 || !header.ModTime.Equal(opts.Created) {
+				t.Fatalf("binary entry = %+v, %q", header, content)
+			}
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"usr/", "usr/local/", "usr/local/bin/", "usr/local/bin/app"}) {
+		t.Fatalf("layer entries = %v", names)
+	}
+
+	// 相同输入得到相同的摘要
+	again, err := Write(opts, FormatOCI, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Digest != result.Digest {
+		t.Fatalf("rebuilding gave digest %s, want %s", again.Digest, result.Digest)
+	}
+}
+
+func TestWriteDockerArchiveWithBase(t *testing.T) {
+	dir := t.TempDir()
+	baseLayout := filepath.Join(dir, "base")
+	baseOpts := &Options{
+		Name:     "base",
+		Tags:     []string{"latest"},
+		BinName:  "sh",
+		Dir:      "/bin",
+		Binaries: []Binary{{Path: writeBinary(t, dir, "sh", "shell"), Platform: PlatformFor("linux", "amd64", "")}},
+		Env:      []string{"PATH=/bin", "TZ=Europe/Berlin"},
+		Created:  time.Unix(0, 0),
+	}
+	if _, err := Write(baseOpts, FormatOCI, baseLayout); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &Options{
+		Name:     "app",
+		Tags:     []string{"dev"},
+		BinName:  "app",
+		Base:     baseLayout,
+		Binaries: []Binary{{Path: writeBinary(t, dir, "app", "app binary"), Platform: PlatformFor("linux", "amd64", "")}},
+		Env:      []string{"TZ=UTC"},
+		Cmd:      []string{"serve"},
+		Created:  time.Unix(1700000000, 0),
+	}
+	output := filepath.Join(dir, "app.tar")
+	if _, err := Write(opts, FormatDocker, output); err != nil {
+		t.Fatal(err)
+	}
+
+	extracted := filepath.Join(dir, "extracted")
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := filepath.Join(extracted, filepath.FromSlash(header.Name))
+		if header.Typeflag == tar.TypeDir {
+			os.MkdirAll(target, 0755)
+			continue
+		}
+		data, _ := io.ReadAll(tr)
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var entries []dockerManifestEntry
+	if err := readJSON(filepath.Join(extracted, "manifest.json"), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !reflect.DeepEqual(entries[0].RepoTags, []string{"app:dev"}) || len(entries[0].Layers) != 2 {
+		t.Fatalf("manifest.json = %+v", entries)
+	}
+	var config ConfigFile
+	if err := readJSON(filepath.Join(extracted, filepath.FromSlash(entries[0].Config)), &config); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.Config.Env, []string{"PATH=/bin", "TZ=UTC"}) || !reflect.DeepEqual(config.Config.Cmd, []string{"serve"}) ||
+		len(config.RootFS.DiffIDs) != 2 || len(config.History) != 2 {
+		t.Fatalf("config = %+v", config)
+	}
+
+	// docker save 只支持单个平台，基础镜像缺少目标平台时报错
+	opts.Binaries = append(opts.Binaries, Binary{Path: opts.Binaries[0].Path, Platform: PlatformFor("linux", "arm64", "")})
+	if _, err := Write(opts, FormatDocker, output); err == nil {
+		t.Fatal("multi-platform docker archives should be rejected")
+	}
+	if _, err := Write(opts, FormatOCI, filepath.Join(dir, "multi")); err == nil || !strings.Contains(err.Error(), "no linux/arm64 manifest") {
+		t.Fatalf("Write() error = %v, want missing base platform", err)
+	}
+}
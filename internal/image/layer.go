@@ -0,0 +1,200 @@
+package image
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dockerManifestEntry docker save 归档中 manifest.json 的条目
+type dockerManifestEntry struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// putLayer 将二进制打包为 gzip 压缩的层，写入 blob 并返回层描述符与 diff ID。
+// 文件属主为 root，修改时间固定为 modTime，相同输入得到相同的摘要。
+func (s *blobStore) putLayer(binary, imagePath string, modTime time.Time) (Descriptor, string, error) {
+	info, err := os.Stat(binary)
+	if err != nil {
+		return Descriptor{}, "", err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".layer-*")
+	if err != nil {
+		return Descriptor{}, "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	compressed := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(tmp, compressed)}
+	gz := gzip.NewWriter(counter)
+	uncompressed := sha256.New()
+	tw := tar.NewWriter(io.MultiWriter(gz, uncompressed))
+
+	// 依次写入上级目录，再写入二进制本身
+	dir := path.Dir(imagePath)
+	parents := []string{}
+	for d := dir; d != "/" && d != "."; d = path.Dir(d) {
+		parents = append([]string{d}, parents...)
+	}
+	for _, parent := range parents {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     strings.TrimPrefix(parent, "/") + "/",
+			Mode:     0755,
+			ModTime:  modTime,
+			Format:   tar.FormatPAX,
+		}); err != nil {
+			tmp.Close()
+			return Descriptor{}, "", err
+		}
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     strings.TrimPrefix(imagePath, "/"),
+		Mode:     0755,
+		Size:     info.Size(),
+		ModTime:  modTime,
+		Format:   tar.FormatPAX,
+	}); err != nil {
+		tmp.Close()
+		return Descriptor{}, "", err
+	}
+	if err := copyFile(tw, binary); err != nil {
+		tmp.Close()
+		return Descriptor{}, "", err
+	}
+	if err := tw.Close(); err != nil {
+		tmp.Close()
+		return Descriptor{}, "", err
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return Descriptor{}, "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return Descriptor{}, "", err
+	}
+
+	desc := Descriptor{
+		MediaType: MediaTypeLayer,
+		Digest:    "sha256:" + hex.EncodeToString(compressed.Sum(nil)),
+		Size:      counter.n,
+	}
+	dest := s.path(desc.Digest)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return Descriptor{}, "", err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return Descriptor{}, "", err
+	}
+	if err := os.Chmod(dest, 0644); err != nil {
+		return Descriptor{}, "", err
+	}
+	return desc, "sha256:" + hex.EncodeToString(uncompressed.Sum(nil)), nil
+}
+
+// writeDockerArchive 将暂存目录 (OCI 布局 + manifest.json) 打包为 docker save 格式的 tar 包
+func writeDockerArchive(staging, output string, modTime time.Time) error {
+	files := []string{}
+	err := filepath.WalkDir(staging, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != staging {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	tmp, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	tw := tar.NewWriter(tmp)
+	for _, file := range files {
+		rel, err := filepath.Rel(staging, file)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		header := &tar.Header{
+			Name:    filepath.ToSlash(rel),
+			Mode:    0644,
+			Size:    info.Size(),
+			ModTime: modTime,
+			Format:  tar.FormatPAX,
+		}
+		if info.IsDir() {
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+			header.Mode = 0755
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			tmp.Close()
+			return err
+		}
+		if !info.IsDir() {
+			if err := copyFile(tw, file); err != nil {
+				tmp.Close()
+				return err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), output)
+}
+
+func copyFile(w io.Writer, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
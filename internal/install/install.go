@@ -0,0 +1,209 @@
+package install
+
+import (
+	"crypto/sha256"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestFileName 安装清单文件名，位于用户配置目录下的 gocar 目录
+const ManifestFileName = "installed.json"
+
+// manifestVersion 清单格式版本
+const manifestVersion = 1
+
+// Manifest 安装清单，记录 gocar install 安装的二进制，供 gocar uninstall 使用
+type Manifest struct {
+	Version  int      `json:"version"`
+	Installs []Record `json:"installs"`
+}
+
+// Record 单个已安装的二进制
+type Record struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`    // 安装后的绝对路径
+	Project     string `json:"project"` // 项目根目录
+	Profile     string `json:"profile"`
+	SHA256      string `json:"sha256"`
+	InstalledAt string `json:"installed_at"`
+}
+
+// ManifestPath 返回安装清单的默认路径 (<用户配置目录>/gocar/installed.json)
+func ManifestPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "gocar", ManifestFileName), nil
+}
+
+// ReadManifest 读取安装清单，不存在时返回空清单
+func ReadManifest(path string) (*Manifest, error) {
+	manifest := &Manifest{Version: manifestVersion}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// Save 写入安装清单，记录按安装路径排序
+func (m *Manifest) Save(path string) error {
+	sort.Slice(m.Installs, func(i, j int) bool {
+		return m.Installs[i].Path < m.Installs[j].Path
+	})
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Add 添加或替换 (同一安装路径) 安装记录
+func (m *Manifest) Add(record Record) {
+	for i := range m.Installs {
+		if m.Installs[i].Path == record.Path {
+			m.Installs[i] = record
+			return
+		}
+	}
+	m.Installs = append(m.Installs, record)
+}
+
+// Remove 删除指定安装路径的记录
+func (m *Manifest) Remove(path string) {
+	kept := m.Installs[:0]
+	for _, record := range m.Installs {
+		if record.Path != path {
+			kept = append(kept, record)
+		}
+	}
+	m.Installs = kept
+}
+
+// CheckOwned 检查 path 是否可以由 gocar install 覆盖：文件不存在或已记录在清单中。
+// 其他工具 (如 go install) 安装的同名文件返回错误，避免 gocar uninstall 删除不属于它的文件。
+func (m *Manifest) CheckOwned(path string) error {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return nil
+	}
+	for _, record := range m.Installs {
+		if record.Path == path {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s already exists and was not installed by gocar (use --force to replace it)", path)
+}
+
+// SameBuild 检查两个二进制是否来自同一构建。
+// 比较内嵌的构建信息 (debug/buildinfo)；工作区有未提交改动或没有 VCS 信息时，
+// 构建信息无法反映源码差异，此时再比较文件内容。
+func SameBuild(a, b string) bool {
+	infoA, err := buildinfo.ReadFile(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := buildinfo.ReadFile(b)
+	if err != nil {
+		return false
+	}
+	if infoA.String() != infoB.String() {
+		return false
+	}
+
+	revision, modified := "", ""
+	for _, setting := range infoA.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+	if revision != "" && modified != "true" {
+		return true
+	}
+	digestA, err := FileDigest(a)
+	if err != nil {
+		return false
+	}
+	digestB, err := FileDigest(b)
+	return err == nil && digestA == digestB
+}
+
+// FileDigest 返回文件的 SHA-256
+func FileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// CopyAtomic 将 src 复制到 dest：先写入同目录下的临时文件，再重命名覆盖，
+// 安装中断时不会留下不完整的二进制
+func CopyAtomic(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".gocar-install-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0755); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// NewRecord 创建安装记录
+func NewRecord(name, path, project, profile, sha string) Record {
+	return Record{
+		Name:        name,
+		Path:        path,
+		Project:     project,
+		Profile:     profile,
+		SHA256:      sha,
+		InstalledAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
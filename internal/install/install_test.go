@@ -0,0 +1,105 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocar", ManifestFileName)
+	manifest, err := ReadManifest(path)
+	if err != nil || len(manifest.Installs) != 0 {
+		t.Fatalf("ReadManifest(missing) = %+v, %v", manifest, err)
+	}
+
+	manifest.Add(NewRecord("worker", "/usr/local/bin/worker", "/src/app", "release", "aa"))
+	manifest.Add(NewRecord("api", "/usr/local/bin/api", "/src/app", "release", "bb"))
+	manifest.Add(NewRecord("api", "/usr/local/bin/api", "/src/app", "debug", "cc"))
+	if err := manifest.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Installs) != 2 || loaded.Installs[0].Name != "api" || loaded.Installs[0].SHA256 != "cc" {
+		t.Fatalf("loaded manifest = %+v", loaded.Installs)
+	}
+	loaded.Remove("/usr/local/bin/api")
+	if len(loaded.Installs) != 1 || loaded.Installs[0].Name != "worker" {
+		t.Fatalf("after Remove = %+v", loaded.Installs)
+	}
+}
+
+func TestCopyAtomicAndSameBuild(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "bin", "app")
+	if SameBuild(exe, dest) {
+		t.Fatal("SameBuild() with a missing destination should be false")
+	}
+	if err := CopyAtomic(exe, dest); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("installed binary should be executable, mode %v", info.Mode())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(dest)); len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+	if !SameBuild(exe, dest) {
+		t.Fatal("SameBuild() should match an identical copy")
+	}
+
+	// 测试二进制没有 VCS 信息，构建信息相同但内容不同时不能跳过
+	file, err := os.OpenFile(dest, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte{0})
+	file.Close()
+	if SameBuild(exe, dest) {
+		t.Fatal("SameBuild() should compare contents when VCS info is missing")
+	}
+
+	script := filepath.Join(dir, "script")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if SameBuild(script, script) {
+		t.Fatal("SameBuild() should be false for files without Go build info")
+	}
+}
+
+func TestCheckOwned(t *testing.T) {
+	dir := t.TempDir()
+	manifest := &Manifest{Version: manifestVersion}
+
+	missing := filepath.Join(dir, "missing")
+	if err := manifest.CheckOwned(missing); err != nil {
+		t.Fatalf("CheckOwned(missing) = %v, want nil", err)
+	}
+
+	// go install 等其他工具安装的文件不能被接管
+	foreign := filepath.Join(dir, "tool")
+	if err := os.WriteFile(foreign, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.CheckOwned(foreign); err == nil {
+		t.Fatal("CheckOwned() should refuse a file that is not in the manifest")
+	}
+
+	manifest.Add(NewRecord("tool", foreign, "/src/app", "release", "aa"))
+	if err := manifest.CheckOwned(foreign); err != nil {
+		t.Fatalf("CheckOwned(tracked) = %v, want nil", err)
+	}
+}
@@ -1,5 +1,10 @@
 package util
 
+import (
+	"os/exec"
+	"strings"
+)
+
 // InitGit 初始化 Git 仓库
 func InitGit(appName string) error {
 	// git init with main as default branch
@@ -14,3 +19,23 @@ func InitGit(appName string) error {
 
 	return nil
 }
+
+// GitOutput 在 dir 中执行 git 命令并返回去除首尾空白的标准输出
+func GitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GitDescribe 返回基于最近标签的版本描述 (git describe --tags --dirty)。
+// 没有标签时回退为短提交哈希。
+func GitDescribe(dir string) (string, error) {
+	if describe, err := GitOutput(dir, "describe", "--tags", "--dirty"); err == nil {
+		return describe, nil
+	}
+	return GitOutput(dir, "describe", "--tags", "--always", "--dirty")
+}
@@ -0,0 +1,46 @@
+package winres
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// iconResources 读取 .ico 文件，每个图像作为一个 RT_ICON 资源 (ID 从 1 开始)，
+// 并生成引用它们的 RT_GROUP_ICON 资源 (ID 1)，资源管理器使用该图标组显示程序图标
+func iconResources(path string) ([]resource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	le := binary.LittleEndian
+	if len(data) < 6 || le.Uint16(data[0:]) != 0 || le.Uint16(data[2:]) != 1 {
+		return nil, fmt.Errorf("not an .ico file")
+	}
+	count := int(le.Uint16(data[4:]))
+	if count == 0 {
+		return nil, fmt.Errorf("icon file contains no images")
+	}
+	if len(data) < 6+16*count {
+		return nil, fmt.Errorf("icon directory is truncated")
+	}
+
+	// GRPICONDIR: 与 ICONDIR 相同的 6 字节头，目录项的 4 字节偏移换成 2 字节资源 ID
+	group := make([]byte, 6, 6+14*count)
+	copy(group, data[:6])
+	resources := make([]resource, 0, count+1)
+	for i := 0; i < count; i++ {
+		// ICONDIRENTRY: 宽、高、颜色数、保留、位面数、位深 (共 12 字节)，图像大小与偏移
+		raw := data[6+16*i:]
+		size, offset := le.Uint32(raw[8:]), le.Uint32(raw[12:])
+		if uint64(offset)+uint64(size) > uint64(len(data)) {
+			return nil, fmt.Errorf("image %d extends past the end of the file", i+1)
+		}
+
+		id := uint16(i + 1)
+		group = append(group, raw[:12]...)
+		group = le.AppendUint16(group, id)
+		resources = append(resources, resource{typeID: typeIcon, id: id, data: data[offset : offset+size]})
+	}
+	return append(resources, resource{typeID: typeGroupIcon, id: 1, data: group}), nil
+}
@@ -0,0 +1,72 @@
+package winres
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ManifestOptions 生成应用程序 manifest 的选项
+type ManifestOptions struct {
+	ExecutionLevel string // 默认为 asInvoker
+	DPIAwareness   string // 为空时不声明
+	LongPathAware  bool   // 允许超过 MAX_PATH 的路径 (还需要系统开启 LongPathsEnabled)
+}
+
+// supportedOS Windows 7 至 Windows 10/11 的兼容性 GUID，声明后系统不会对程序启用兼容模式
+var supportedOS = []string{
+	"{35138b9a-5d96-4fbd-8e2d-a2440225f93a}", // Windows 7
+	"{4a2f28e3-53b9-4441-ba9c-d69d4a4a6e38}", // Windows 8
+	"{1f676c76-80e1-4239-95bb-83d0f6d0da78}", // Windows 8.1
+	"{8e0f7a12-bfb3-4fe8-b9a5-48fd50a15a9a}", // Windows 10/11
+}
+
+// Manifest 生成应用程序 manifest
+func Manifest(opts ManifestOptions) []byte {
+	level := opts.ExecutionLevel
+	if level == "" {
+		level = "asInvoker"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<assembly xmlns="urn:schemas-microsoft-com:asm.v1" manifestVersion="1.0">
+  <compatibility xmlns="urn:schemas-microsoft-com:compatibility.v1">
+    <application>
+`)
+	for _, id := range supportedOS {
+		fmt.Fprintf(&buf, "      <supportedOS Id=\"%s\"/>\n", id)
+	}
+	buf.WriteString("    </application>\n  </compatibility>\n")
+
+	if opts.DPIAwareness != "" || opts.LongPathAware {
+		buf.WriteString("  <application xmlns=\"urn:schemas-microsoft-com:asm.v3\">\n    <windowsSettings>\n")
+		// dpiAware 供 Windows 10 1607 之前的系统使用，dpiAwareness 优先
+		switch opts.DPIAwareness {
+		case "unaware":
+			buf.WriteString("      <dpiAware xmlns=\"http://schemas.microsoft.com/SMI/2005/WindowsSettings\">false</dpiAware>\n")
+		case "system":
+			buf.WriteString("      <dpiAware xmlns=\"http://schemas.microsoft.com/SMI/2005/WindowsSettings\">true</dpiAware>\n")
+		case "permonitor":
+			buf.WriteString("      <dpiAware xmlns=\"http://schemas.microsoft.com/SMI/2005/WindowsSettings\">true/pm</dpiAware>\n")
+			buf.WriteString("      <dpiAwareness xmlns=\"http://schemas.microsoft.com/SMI/2016/WindowsSettings\">permonitor</dpiAwareness>\n")
+		case "permonitorv2":
+			buf.WriteString("      <dpiAware xmlns=\"http://schemas.microsoft.com/SMI/2005/WindowsSettings\">true/pm</dpiAware>\n")
+			buf.WriteString("      <dpiAwareness xmlns=\"http://schemas.microsoft.com/SMI/2016/WindowsSettings\">permonitorv2,permonitor</dpiAwareness>\n")
+		}
+		if opts.LongPathAware {
+			buf.WriteString("      <longPathAware xmlns=\"http://schemas.microsoft.com/SMI/2016/WindowsSettings\">true</longPathAware>\n")
+		}
+		buf.WriteString("    </windowsSettings>\n  </application>\n")
+	}
+
+	fmt.Fprintf(&buf, `  <trustInfo xmlns="urn:schemas-microsoft-com:asm.v3">
+    <security>
+      <requestedPrivileges>
+        <requestedExecutionLevel level="%s" uiAccess="false"/>
+      </requestedPrivileges>
+    </security>
+  </trustInfo>
+</assembly>
+`, level)
+	return buf.Bytes()
+}
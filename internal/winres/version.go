@@ -0,0 +1,122 @@
+package winres
+
+import (
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// VersionInfo 版本信息资源 (VS_VERSIONINFO)，资源管理器的“详细信息”页显示其中的内容
+type VersionInfo struct {
+	FileVersion    [4]uint16         // 数字形式的文件版本
+	ProductVersion [4]uint16         // 数字形式的产品版本
+	DLL            bool              // 产物为 DLL (VFT_DLL)，否则为应用程序 (VFT_APP)
+	Strings        map[string]string // StringFileInfo 中的字符串，如 FileVersion、ProductName、CompanyName
+}
+
+// ParseVersion 从版本号字符串中取出最多四段数字，如 "v1.2.3-rc.1" -> 1.2.3.0。
+// 遇到非数字时停止，无法解析的部分为 0。
+func ParseVersion(version string) [4]uint16 {
+	var parts [4]uint16
+	version = strings.TrimPrefix(version, "v")
+	for i := 0; i < len(parts) && version != ""; i++ {
+		end := 0
+		for end < len(version) && version[end] >= '0' && version[end] <= '9' {
+			end++
+		}
+		n, err := strconv.ParseUint(version[:end], 10, 16)
+		if err != nil {
+			break
+		}
+		parts[i] = uint16(n)
+		if end == len(version) || version[end] != '.' {
+			break
+		}
+		version = version[end+1:]
+	}
+	return parts
+}
+
+// encode 编码为 VS_VERSIONINFO：VS_FIXEDFILEINFO 之后是 StringFileInfo (语言 040904b0) 与 VarFileInfo
+func (v *VersionInfo) encode() []byte {
+	le := binary.LittleEndian
+	fixed := make([]byte, 0, 52)
+	for _, value := range []uint32{
+		0xFEEF04BD, // dwSignature
+		0x00010000, // dwStrucVersion
+		uint32(v.FileVersion[0])<<16 | uint32(v.FileVersion[1]),
+		uint32(v.FileVersion[2])<<16 | uint32(v.FileVersion[3]),
+		uint32(v.ProductVersion[0])<<16 | uint32(v.ProductVersion[1]),
+		uint32(v.ProductVersion[2])<<16 | uint32(v.ProductVersion[3]),
+		0x3F,        // dwFileFlagsMask
+		0,           // dwFileFlags
+		0x00040004,  // dwFileOS: VOS_NT_WINDOWS32
+		fileType(v), // dwFileType
+		0, 0, 0,     // dwFileSubtype, dwFileDateMS, dwFileDateLS
+	} {
+		fixed = le.AppendUint32(fixed, value)
+	}
+
+	keys := make([]string, 0, len(v.Strings))
+	for key, value := range v.Strings {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	strs := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		value := utf16z(v.Strings[key])
+		strs = append(strs, versionNode(key, uint16(len(value)/2), 1, value))
+	}
+	table := versionNode("040904b0", 0, 1, nil, strs...)
+	stringInfo := versionNode("StringFileInfo", 0, 1, nil, table)
+
+	translation := le.AppendUint16(le.AppendUint16(nil, langEnUS), 0x04b0)
+	varInfo := versionNode("VarFileInfo", 0, 1, nil, versionNode("Translation", uint16(len(translation)), 0, translation))
+
+	return versionNode("VS_VERSION_INFO", uint16(len(fixed)), 0, fixed, stringInfo, varInfo)
+}
+
+func fileType(v *VersionInfo) uint32 {
+	if v.DLL {
+		return 2 // VFT_DLL
+	}
+	return 1 // VFT_APP
+}
+
+// versionNode 编码版本信息中的一个节点：wLength、wValueLength、wType、以 0 结尾的 UTF-16 键名，
+// 然后是值与子节点，各部分按 4 字节对齐。wValueLength 对文本值以 WORD 计，对二进制值以字节计。
+func versionNode(key string, valueLength, valueType uint16, value []byte, children ...[]byte) []byte {
+	le := binary.LittleEndian
+	node := make([]byte, 6)
+	le.PutUint16(node[2:], valueLength)
+	le.PutUint16(node[4:], valueType)
+	node = append(node, utf16z(key)...)
+	node = pad4(node)
+	node = append(node, value...)
+	for _, child := range children {
+		node = pad4(node)
+		node = append(node, child...)
+	}
+	le.PutUint16(node[0:], uint16(len(node)))
+	return node
+}
+
+// utf16z 编码为以 0 结尾的 UTF-16LE 字符串
+func utf16z(s string) []byte {
+	out := []byte{}
+	for _, r := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, r)
+	}
+	return append(out, 0, 0)
+}
+
+func pad4(b []byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
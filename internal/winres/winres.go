@@ -0,0 +1,224 @@
+// Package winres 生成 Windows 资源 (图标、manifest、版本信息) 的 COFF 目标文件 (.syso)。
+// go build 会把包目录中的 .syso 文件链接进二进制，生成过程不依赖 windres/rc 等 Windows 工具。
+package winres
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// 资源类型 (RT_*)
+const (
+	typeIcon      = 3
+	typeGroupIcon = 14
+	typeVersion   = 16
+	typeManifest  = 24
+)
+
+// langEnUS 资源语言 (英语-美国)，与 StringFileInfo 的 040904b0 一致
+const langEnUS = 0x0409
+
+// Options 要写入 .syso 的资源
+type Options struct {
+	Icon     string       // .ico 文件路径，为空时不包含图标
+	Manifest []byte       // 应用程序 manifest，为空时不包含
+	Version  *VersionInfo // 版本信息，为 nil 时不包含
+}
+
+// machine COFF 文件头中的机器类型与资源数据项使用的重定位类型 (ADDR32NB，即 RVA)
+type machine struct {
+	id        uint16
+	relocType uint16
+	bits32    bool
+}
+
+var machines = map[string]machine{
+	"386":   {id: pe.IMAGE_FILE_MACHINE_I386, relocType: 0x0007, bits32: true}, // IMAGE_REL_I386_DIR32NB
+	"amd64": {id: pe.IMAGE_FILE_MACHINE_AMD64, relocType: 0x0003},              // IMAGE_REL_AMD64_ADDR32NB
+	"arm64": {id: pe.IMAGE_FILE_MACHINE_ARM64, relocType: 0x0002},              // IMAGE_REL_ARM64_ADDR32NB
+}
+
+// generatorStamp 写入 COFF 文件头 TimeDateStamp 字段的标记 ("gcar")，用于识别 gocar 生成的 .syso。
+// 链接器不使用目标文件的时间戳，固定值也保证了相同输入得到相同的内容。
+const generatorStamp = 0x72616367
+
+// IsGenerated 检查 .syso 文件内容是否由 Build 生成 (如构建被中断时遗留在包目录中的文件)
+func IsGenerated(data []byte) bool {
+	if len(data) < 20 {
+		return false
+	}
+	var header pe.FileHeader
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return false
+	}
+	return header.TimeDateStamp == generatorStamp && header.NumberOfSections == 1
+}
+
+// SysoName 返回 go build 在目标架构下会链接的资源文件名: rsrc_windows_<arch>.syso
+func SysoName(arch string) string {
+	return "rsrc_windows_" + arch + ".syso"
+}
+
+// resource 单个资源项
+type resource struct {
+	typeID uint16
+	id     uint16
+	data   []byte
+}
+
+// Build 返回目标架构的 .syso 文件内容。相同输入得到相同的内容。
+func Build(arch string, opts *Options) ([]byte, error) {
+	m, ok := machines[arch]
+	if !ok {
+		return nil, fmt.Errorf("windows resources are not supported for %s", arch)
+	}
+
+	var resources []resource
+	if opts.Icon != "" {
+		icons, err := iconResources(opts.Icon)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(opts.Icon), err)
+		}
+		resources = append(resources, icons...)
+	}
+	if opts.Version != nil {
+		resources = append(resources, resource{typeID: typeVersion, id: 1, data: opts.Version.encode()})
+	}
+	if len(opts.Manifest) > 0 {
+		resources = append(resources, resource{typeID: typeManifest, id: 1, data: opts.Manifest})
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("no resources to write")
+	}
+
+	section, relocs := resourceSection(resources)
+	return coffObject(m, section, relocs), nil
+}
+
+// resourceSection 按 类型 -> ID -> 语言 三级目录布局 .rsrc 节。
+// 返回节内容以及需要重定位的数据项偏移 (数据项中的 OffsetToData 为 RVA，由链接器修正)。
+func resourceSection(resources []resource) ([]byte, []uint32) {
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].typeID != resources[j].typeID {
+			return resources[i].typeID < resources[j].typeID
+		}
+		return resources[i].id < resources[j].id
+	})
+	types := []uint16{}
+	byType := map[uint16][]int{}
+	for i, res := range resources {
+		if len(byType[res.typeID]) == 0 {
+			types = append(types, res.typeID)
+		}
+		byType[res.typeID] = append(byType[res.typeID], i)
+	}
+
+	// 目录: IMAGE_RESOURCE_DIRECTORY (16 字节) + 每项 8 字节；数据项 16 字节；数据按 8 字节对齐
+	const dirSize, entrySize, dataEntrySize = 16, 8, 16
+	offset := uint32(dirSize + entrySize*len(types))
+	typeDirs := map[uint16]uint32{}
+	for _, typeID := range types {
+		typeDirs[typeID] = offset
+		offset += uint32(dirSize + entrySize*len(byType[typeID]))
+	}
+	idDirs := make([]uint32, len(resources))
+	for i := range resources {
+		idDirs[i] = offset
+		offset += dirSize + entrySize
+	}
+	dataEntries := make([]uint32, len(resources))
+	for i := range resources {
+		dataEntries[i] = offset
+		offset += dataEntrySize
+	}
+	dataOffsets := make([]uint32, len(resources))
+	for i, res := range resources {
+		offset = alignUp(offset, 8)
+		dataOffsets[i] = offset
+		offset += uint32(len(res.data))
+	}
+
+	section := make([]byte, alignUp(offset, 8))
+	le := binary.LittleEndian
+	writeDir := func(at uint32, entries int) {
+		le.PutUint16(section[at+14:], uint16(entries)) // NumberOfIdEntries，名称项为 0
+	}
+	writeEntry := func(at uint32, id uint32, target uint32, subdir bool) {
+		if subdir {
+			target |= 0x80000000
+		}
+		le.PutUint32(section[at:], id)
+		le.PutUint32(section[at+4:], target)
+	}
+
+	writeDir(0, len(types))
+	for i, typeID := range types {
+		writeEntry(uint32(dirSize+entrySize*i), uint32(typeID), typeDirs[typeID], true)
+		writeDir(typeDirs[typeID], len(byType[typeID]))
+		for j, index := range byType[typeID] {
+			writeEntry(typeDirs[typeID]+uint32(dirSize+entrySize*j), uint32(resources[index].id), idDirs[index], true)
+		}
+	}
+	relocs := make([]uint32, len(resources))
+	for i, res := range resources {
+		writeDir(idDirs[i], 1)
+		writeEntry(idDirs[i]+dirSize, langEnUS, dataEntries[i], false)
+		le.PutUint32(section[dataEntries[i]:], dataOffsets[i])
+		le.PutUint32(section[dataEntries[i]+4:], uint32(len(res.data)))
+		copy(section[dataOffsets[i]:], res.data)
+		relocs[i] = dataEntries[i]
+	}
+	return section, relocs
+}
+
+// coffObject 生成只包含 .rsrc 节的 COFF 目标文件：
+// 文件头、节头、节内容、重定位表、符号表 (一个指向 .rsrc 节的符号) 与空字符串表
+func coffObject(m machine, section []byte, relocs []uint32) []byte {
+	const fileHeaderSize, sectionHeaderSize, relocSize = 20, 40, 10
+	dataOffset := uint32(fileHeaderSize + sectionHeaderSize)
+	relocOffset := dataOffset + uint32(len(section))
+	symbolOffset := relocOffset + uint32(relocSize*len(relocs))
+
+	characteristics := uint16(pe.IMAGE_FILE_LINE_NUMS_STRIPPED)
+	if m.bits32 {
+		characteristics |= pe.IMAGE_FILE_32BIT_MACHINE
+	}
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	binary.Write(&buf, le, pe.FileHeader{
+		Machine:              m.id,
+		NumberOfSections:     1,
+		TimeDateStamp:        generatorStamp,
+		PointerToSymbolTable: symbolOffset,
+		NumberOfSymbols:      1,
+		Characteristics:      characteristics,
+	})
+	binary.Write(&buf, le, pe.SectionHeader32{
+		Name:                 [8]uint8{'.', 'r', 's', 'r', 'c'},
+		SizeOfRawData:        uint32(len(section)),
+		PointerToRawData:     dataOffset,
+		PointerToRelocations: relocOffset,
+		NumberOfRelocations:  uint16(len(relocs)),
+		Characteristics:      pe.IMAGE_SCN_CNT_INITIALIZED_DATA | pe.IMAGE_SCN_MEM_READ,
+	})
+	buf.Write(section)
+	for _, at := range relocs {
+		binary.Write(&buf, le, pe.Reloc{VirtualAddress: at, SymbolTableIndex: 0, Type: m.relocType})
+	}
+	binary.Write(&buf, le, pe.COFFSymbol{
+		Name:          [8]uint8{'.', 'r', 's', 'r', 'c'},
+		SectionNumber: 1,
+		StorageClass:  3, // IMAGE_SYM_CLASS_STATIC
+	})
+	binary.Write(&buf, le, uint32(4)) // 字符串表只有长度字段
+	return buf.Bytes()
+}
+
+func alignUp(n, align uint32) uint32 {
+	return (n + align - 1) &^ (align - 1)
+}
@@ -0,0 +1,161 @@
+package winres
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// writeIcon 写入包含两个图像的 .ico 文件，图像内容为占位字节
+func writeIcon(t *testing.T, dir string) string {
+	t.Helper()
+	images := [][]byte{bytes.Repeat([]byte{1}, 40), bytes.Repeat([]byte{2}, 72)}
+	le := binary.LittleEndian
+	ico := le.AppendUint16(le.AppendUint16(le.AppendUint16(nil, 0), 1), uint16(len(images)))
+	offset := uint32(6 + 16*len(images))
+	for i, image := range images {
+		size := byte(16 * (i + 1))
+		ico = append(ico, size, size, 0, 0)
+		ico = le.AppendUint16(le.AppendUint16(ico, 1), 32)
+		ico = le.AppendUint32(le.AppendUint32(ico, uint32(len(image))), offset)
+		offset += uint32(len(image))
+	}
+	for _, image := range images {
+		ico = append(ico, image...)
+	}
+	path := filepath.Join(dir, "app.ico")
+	if err := os.WriteFile(path, ico, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBuildCOFFResources(t *testing.T) {
+	opts := &Options{
+		Icon:     writeIcon(t, t.TempDir()),
+		Manifest: Manifest(ManifestOptions{ExecutionLevel: "requireAdministrator", LongPathAware: true}),
+		Version: &VersionInfo{
+			FileVersion:    ParseVersion("v1.4.2-rc.1"),
+			ProductVersion: ParseVersion("1.4"),
+			Strings:        map[string]string{"CompanyName": "Acme", "FileVersion": "1.4.2-rc.1", "Comments": ""},
+		},
+	}
+	data, err := Build("amd64", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := Build("amd64", opts)
+	if !bytes.Equal(data, again) {
+		t.Fatal("Build() is not deterministic")
+	}
+	if !IsGenerated(data) || IsGenerated([]byte("custom")) {
+		t.Fatal("IsGenerated() should recognize only files written by Build()")
+	}
+
+	file, err := pe.NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Machine != pe.IMAGE_FILE_MACHINE_AMD64 || len(file.Sections) != 1 || file.Sections[0].Name != ".rsrc" {
+		t.Fatalf("file header = %+v, sections = %d", file.FileHeader, len(file.Sections))
+	}
+	if len(file.COFFSymbols) != 1 || file.COFFSymbols[0].SectionNumber != 1 {
+		t.Fatalf("symbols = %+v", file.COFFSymbols)
+	}
+	section := file.Sections[0]
+	rsrc, err := section.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 遍历 类型 -> ID -> 语言 三级目录，每个数据项都有一个 ADDR32NB 重定位
+	le := binary.LittleEndian
+	found := map[[2]uint32][]byte{}
+	relocated := map[uint32]bool{}
+	for _, reloc := range section.Relocs {
+		if reloc.Type != 0x0003 {
+			t.Fatalf("relocation type = %#x", reloc.Type)
+		}
+		relocated[reloc.VirtualAddress] = true
+	}
+	entries := func(dir uint32) [][2]uint32 {
+		n := uint32(le.Uint16(rsrc[dir+14:]))
+		list := [][2]uint32{}
+		for i := uint32(0); i < n; i++ {
+			list = append(list, [2]uint32{le.Uint32(rsrc[dir+16+8*i:]), le.Uint32(rsrc[dir+20+8*i:])})
+		}
+		return list
+	}
+	for _, typ := range entries(0) {
+		for _, id := range entries(typ[1] &^ 0x80000000) {
+			lang := entries(id[1] &^ 0x80000000)
+			if len(lang) != 1 || lang[0][0] != langEnUS || lang[0][1]&0x80000000 != 0 {
+				t.Fatalf("language entries = %v", lang)
+			}
+			entry := lang[0][1]
+			if !relocated[entry] {
+				t.Fatalf("data entry at %#x has no relocation", entry)
+			}
+			offset, size := le.Uint32(rsrc[entry:]), le.Uint32(rsrc[entry+4:])
+			found[[2]uint32{typ[0], id[0]}] = rsrc[offset : offset+size]
+		}
+	}
+	if len(found) != 5 || len(section.Relocs) != 5 {
+		t.Fatalf("resources = %d, relocations = %d, want 5", len(found), len(section.Relocs))
+	}
+	if !bytes.Equal(found[[2]uint32{typeIcon, 2}], bytes.Repeat([]byte{2}, 72)) {
+		t.Fatal("second icon image does not match the .ico file")
+	}
+	group := found[[2]uint32{typeGroupIcon, 1}]
+	if len(group) != 6+14*2 || le.Uint16(group[4:]) != 2 || le.Uint16(group[6+14+12:]) != 2 {
+		t.Fatalf("group icon = %v", group)
+	}
+	if manifest := string(found[[2]uint32{typeManifest, 1}]); !strings.Contains(manifest, `level="requireAdministrator"`) || !strings.Contains(manifest, "<longPathAware") {
+		t.Fatalf("manifest = %s", manifest)
+	}
+
+	version := found[[2]uint32{typeVersion, 1}]
+	if int(le.Uint16(version)) != len(version) || le.Uint32(version[40:]) != 0xFEEF04BD {
+		t.Fatalf("VS_VERSIONINFO header = %v", version[:44])
+	}
+	if ms, ls := le.Uint32(version[48:]), le.Uint32(version[52:]); ms != 1<<16|4 || ls != 2<<16 {
+		t.Fatalf("file version = %#x %#x, want 1.4.2.0", ms, ls)
+	}
+	words := make([]uint16, len(version)/2)
+	for i := range words {
+		words[i] = le.Uint16(version[2*i:])
+	}
+	text := string(utf16.Decode(words))
+	for _, want := range []string{"StringFileInfo", "040904b0", "CompanyName\x00\x00Acme\x00", "Translation"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("version info does not contain %q", want)
+		}
+	}
+	if strings.Contains(text, "Comments") {
+		t.Fatal("empty strings should be omitted")
+	}
+
+	if _, err := Build("riscv64", opts); err == nil {
+		t.Fatal("unsupported architectures should be rejected")
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for version, want := range map[string][4]uint16{
+		"1.2.3":         {1, 2, 3, 0},
+		"v2.0.1-rc.1":   {2, 0, 1, 0},
+		"1.2.3.4.5":     {1, 2, 3, 4},
+		"0.9.0-3-gabcd": {0, 9, 0, 0},
+		"":              {},
+		"dev":           {},
+	} {
+		if got := ParseVersion(version); got != want {
+			t.Errorf("ParseVersion(%q) = %v, want %v", version, got, want)
+		}
+	}
+}